@@ -0,0 +1,114 @@
+package address
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	currentPackage = "github.com/Walker088/gorealestate/address"
+
+	QueryAddressError  = "AD00001"
+	UpdateAddressError = "AD00002"
+
+	backfillBatchSize = 1000
+)
+
+var (
+	tables = []string{"plvr_land_house_sale", "plvr_land_new_house", "plvr_land_rental"}
+)
+
+type BackfillSummary struct {
+	Table  string `json:"table"`
+	Parsed int64  `json:"parsed"`
+}
+
+// Backfill parses the addresses imported before the address_* columns exist, the parsed rows
+// are geocoded again
+func Backfill(ctx context.Context, pool *pgxpool.Pool, logger *zap.SugaredLogger) ([]BackfillSummary, *e.ErrorData) {
+	summaries := []BackfillSummary{}
+	for _, table := range tables {
+		summary := BackfillSummary{Table: table}
+		for {
+			parsed, errData := backfillBatch(ctx, pool, table)
+			if errData != nil {
+				return nil, errData
+			}
+			summary.Parsed += parsed
+			if parsed < backfillBatchSize {
+				break
+			}
+			logger.Debugf("[address] %s: %d parsed", table, summary.Parsed)
+		}
+		logger.Infof("[address] %s backfilled: %d parsed", table, summary.Parsed)
+		summaries = append(summaries, summary)
+	}
+	return summaries, nil
+}
+
+func backfillBatch(ctx context.Context, pool *pgxpool.Pool, table string) (int64, *e.ErrorData) {
+	query := fmt.Sprintf(`
+	SELECT tableoid::TEXT, ctid::TEXT, COALESCE(address, ''), COALESCE(district, '')
+	FROM %s
+	WHERE address_normalized IS NULL
+	LIMIT %d
+	`, table, backfillBatchSize)
+	rows, err := pool.Query(ctx, query)
+	if err != nil {
+		return 0, e.Wrap(
+			QueryAddressError,
+			err,
+			fmt.Sprintf("%s.backfillBatch", currentPackage),
+		)
+	}
+	// a ctid is only unique within a partition, the rows are identified along with their partition
+	type row struct{ tableoid, ctid, address, district string }
+	batch := []row{}
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.tableoid, &r.ctid, &r.address, &r.district); err != nil {
+			rows.Close()
+			return 0, e.Wrap(
+				QueryAddressError,
+				err,
+				fmt.Sprintf("%s.backfillBatch", currentPackage),
+			)
+		}
+		batch = append(batch, r)
+	}
+	// the rows are closed before the updates, a query interrupted midway is not taken as the last batch
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, e.Wrap(
+			QueryAddressError,
+			err,
+			fmt.Sprintf("%s.backfillBatch", currentPackage),
+		)
+	}
+
+	update := fmt.Sprintf(`
+	UPDATE %s SET
+		address_normalized = $3, address_city = $4, address_district = $5, address_village = $6,
+		address_road = $7, address_section = $8, address_lane = $9, address_alley = $10,
+		address_number_from = $11, address_number_to = $12, address_floor = $13, geocode_version = NULL
+	WHERE tableoid = $1::OID AND ctid = $2::TID
+	`, table)
+	var parsed int64
+	for _, r := range batch {
+		_, err := pool.Exec(ctx, update, append([]any{r.tableoid, r.ctid}, Parse(r.address, r.district).Columns()...)...)
+		if err != nil {
+			return parsed, e.Wrap(
+				UpdateAddressError,
+				err,
+				fmt.Sprintf("%s.backfillBatch", currentPackage),
+			)
+		}
+		parsed++
+	}
+	return parsed, nil
+}
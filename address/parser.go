@@ -0,0 +1,186 @@
+package address
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Walker088/gorealestate/common"
+)
+
+var (
+	cities = []string{
+		"臺北市", "新北市", "桃園市", "臺中市", "臺南市", "高雄市", "基隆市", "新竹市", "嘉義市",
+		"新竹縣", "苗栗縣", "彰化縣", "南投縣", "雲林縣", "嘉義縣", "屏東縣", "宜蘭縣", "花蓮縣",
+		"臺東縣", "澎湖縣", "金門縣", "連江縣", "桃園縣", "臺中縣", "臺南縣", "高雄縣",
+	}
+
+	districtPattern     = regexp.MustCompile(`^\p{Han}{1,3}?區`)
+	townPattern         = regexp.MustCompile(`^\p{Han}{1,3}?[鄉鎮市]`)
+	villagePattern      = regexp.MustCompile(`^(\p{Han}{1,3}?[里村])(\d+鄰)?`)
+	neighborhoodPattern = regexp.MustCompile(`^\d+鄰`)
+	roadPattern         = regexp.MustCompile(`^\p{Han}+?(大道|路|街)`)
+	sectionPattern      = regexp.MustCompile(`^([一二三四五六七八九十\d]+)段`)
+	lanePattern         = regexp.MustCompile(`^([\d之]+)巷`)
+	alleyPattern        = regexp.MustCompile(`^([\d之]+)[弄衖]`)
+	numberPattern       = regexp.MustCompile(`^(\d+)(?:[~-](\d+))?(?:之\d+)?號`)
+	floorPattern        = regexp.MustCompile(`^(地下)?([一二三四五六七八九十百\d]+)[樓層]`)
+	chineseNumberRun    = regexp.MustCompile(`[一二三四五六七八九十百]+(巷|弄|號|之)`)
+	roadSuffixes        = []string{"路", "街", "大道"}
+)
+
+// Address is the structured components of a plvr address (土地位置建物門牌),
+// house numbers are masked by the source as a range, e.g., 31~60號
+type Address struct {
+	Raw        string `json:"raw"`
+	Normalized string `json:"normalized"`
+	City       string `json:"city,omitempty"`
+	District   string `json:"district,omitempty"`
+	Village    string `json:"village,omitempty"`
+	Road       string `json:"road,omitempty"`
+	Section    int    `json:"section,omitempty"`
+	Lane       string `json:"lane,omitempty"`
+	Alley      string `json:"alley,omitempty"`
+	NumberFrom int    `json:"number_from,omitempty"`
+	NumberTo   int    `json:"number_to,omitempty"`
+	Floor      *int   `json:"floor,omitempty"`
+	Rest       string `json:"rest,omitempty"` // the unparsed remainder, e.g., land parcels 學府段一小段123地號
+}
+
+// Normalize converts full-width characters, unifies 台/臺 and removes the spaces
+func Normalize(raw string) string {
+	s := common.ToHalfWidth(raw)
+	s = strings.NewReplacer(" ", "", "台", "臺", "ㄧ", "一", "～", "~", "－", "-").Replace(s)
+	// chinese numerals of lanes, alleys and numbers, e.g., 十二巷 = 12巷
+	return chineseNumberRun.ReplaceAllStringFunc(s, func(m string) string {
+		suffix := m[len(m)-len("巷"):]
+		if v, ok := common.ChineseNumeralToInt(strings.TrimSuffix(m, suffix)); ok {
+			return strconv.Itoa(v) + suffix
+		}
+		return m
+	})
+}
+
+// Parse splits an address into its components, the district of the transaction
+// is used as a hint since it's not always part of the address
+func Parse(raw string, district string) *Address {
+	a := &Address{Raw: raw}
+	s := Normalize(raw)
+
+	for _, c := range cities {
+		if strings.HasPrefix(s, c) {
+			a.City, s = c, strings.TrimPrefix(s, c)
+			break
+		}
+	}
+	district = Normalize(district)
+	if district != "" && strings.HasPrefix(s, district) {
+		a.District, s = district, strings.TrimPrefix(s, district)
+	} else if m := districtPattern.FindString(s); m != "" {
+		a.District, s = m, strings.TrimPrefix(s, m)
+	} else if m := townPattern.FindString(s); m != "" && !isRoad(s, m) {
+		a.District, s = m, strings.TrimPrefix(s, m)
+	} else {
+		a.District = district
+	}
+	if m := villagePattern.FindStringSubmatch(s); m != nil && !isRoad(s, m[1]) {
+		a.Village, s = m[1], strings.TrimPrefix(s, m[0])
+	}
+	s = strings.TrimPrefix(s, neighborhoodPattern.FindString(s))
+	if m := roadPattern.FindString(s); m != "" {
+		a.Road, s = m, strings.TrimPrefix(s, m)
+	}
+	if m := sectionPattern.FindStringSubmatch(s); m != nil && a.Road != "" {
+		a.Section, _ = common.ChineseNumeralToInt(m[1])
+		s = strings.TrimPrefix(s, m[0])
+	}
+	if m := lanePattern.FindStringSubmatch(s); m != nil {
+		a.Lane, s = m[1], strings.TrimPrefix(s, m[0])
+	}
+	if m := alleyPattern.FindStringSubmatch(s); m != nil {
+		a.Alley, s = m[1], strings.TrimPrefix(s, m[0])
+	}
+	if m := numberPattern.FindStringSubmatch(s); m != nil {
+		a.NumberFrom, _ = strconv.Atoi(m[1])
+		a.NumberTo = a.NumberFrom
+		if m[2] != "" {
+			a.NumberTo, _ = strconv.Atoi(m[2])
+		}
+		s = strings.TrimPrefix(s, m[0])
+	}
+	if m := floorPattern.FindStringSubmatch(s); m != nil {
+		if f, ok := common.ParseFloor(m[0]); ok {
+			a.Floor = &f
+		}
+		s = strings.TrimPrefix(s, m[0])
+	}
+	a.Rest = s
+	a.Normalized = a.String()
+	return a
+}
+
+// isRoad tells if the matched town or village is actually the beginning of a road, e.g., 中村路
+func isRoad(s string, m string) bool {
+	rest := strings.TrimPrefix(s, m)
+	for _, suffix := range roadSuffixes {
+		if strings.HasPrefix(rest, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// String renders the normalized address, e.g., 臺北市大安區忠孝東路四段31~60號5樓
+func (a *Address) String() string {
+	var b strings.Builder
+	b.WriteString(a.City)
+	b.WriteString(a.District)
+	b.WriteString(a.Village)
+	b.WriteString(a.Road)
+	if a.Section > 0 {
+		b.WriteString(common.IntToChineseNumeral(a.Section) + "段")
+	}
+	if a.Lane != "" {
+		b.WriteString(a.Lane + "巷")
+	}
+	if a.Alley != "" {
+		b.WriteString(a.Alley + "弄")
+	}
+	if a.NumberFrom > 0 {
+		if a.NumberTo > a.NumberFrom {
+			b.WriteString(fmt.Sprintf("%d~%d號", a.NumberFrom, a.NumberTo))
+		} else {
+			b.WriteString(fmt.Sprintf("%d號", a.NumberFrom))
+		}
+	}
+	if a.Floor != nil {
+		if *a.Floor < 0 {
+			b.WriteString(fmt.Sprintf("地下%d樓", -*a.Floor))
+		} else {
+			b.WriteString(fmt.Sprintf("%d樓", *a.Floor))
+		}
+	}
+	b.WriteString(a.Rest)
+	return b.String()
+}
+
+// ColumnNames are the address_* columns in the order of the values of Columns
+var ColumnNames = []string{
+	"address_normalized", "address_city", "address_district", "address_village", "address_road", "address_section",
+	"address_lane", "address_alley", "address_number_from", "address_number_to", "address_floor",
+}
+
+// Columns returns the values of the address_* columns, zero numbers are stored as null
+func (a *Address) Columns() []any {
+	nullable := func(v int) *int {
+		if v == 0 {
+			return nil
+		}
+		return &v
+	}
+	return []any{
+		a.Normalized, a.City, a.District, a.Village, a.Road, nullable(a.Section),
+		a.Lane, a.Alley, nullable(a.NumberFrom), nullable(a.NumberTo), a.Floor,
+	}
+}
@@ -0,0 +1,63 @@
+package address
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	for raw, want := range map[string]string{
+		"台北市大安區忠孝東路四段３１～６０號": "臺北市大安區忠孝東路四段31~60號",
+		"台中市 西屯區 ㄧ心街十二巷五號":   "臺中市西屯區一心街12巷5號",
+		"新北市板橋區文化路一段１８８巷":    "新北市板橋區文化路一段188巷",
+	} {
+		if got := Normalize(raw); got != want {
+			t.Errorf("expected %s to be normalized to %s, got %s", raw, want, got)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	floor := func(v int) *int { return &v }
+	for _, c := range []struct {
+		raw, district string
+		want          Address
+	}{
+		{
+			// masked ranges of the house numbers
+			raw: "臺北市大安區忠孝東路四段31~60號5樓",
+			want: Address{
+				City: "臺北市", District: "大安區", Road: "忠孝東路", Section: 4,
+				NumberFrom: 31, NumberTo: 60, Floor: floor(5), Normalized: "臺北市大安區忠孝東路四段31~60號5樓",
+			},
+		},
+		{
+			// full-width digits, 台 and a district missing from the address
+			raw: "台中市一心街１２巷３弄５號地下一層", district: "西屯區",
+			want: Address{
+				City: "臺中市", District: "西屯區", Road: "一心街", Lane: "12", Alley: "3",
+				NumberFrom: 5, NumberTo: 5, Floor: floor(-1), Normalized: "臺中市西屯區一心街12巷3弄5號地下1樓",
+			},
+		},
+		{
+			// a town followed by a village and a neighborhood, 中村路 is not a village
+			raw: "彰化縣員林市大同里3鄰中村路10之1號",
+			want: Address{
+				City: "彰化縣", District: "員林市", Village: "大同里", Road: "中村路",
+				NumberFrom: 10, NumberTo: 10, Normalized: "彰化縣員林市大同里中村路10號",
+			},
+		},
+		{
+			// land parcels are kept as the remainder
+			raw: "新竹市東區學府段一小段123地號",
+			want: Address{
+				City: "新竹市", District: "東區", Rest: "學府段一小段123地號", Normalized: "新竹市東區學府段一小段123地號",
+			},
+		},
+	} {
+		c.want.Raw = c.raw
+		if got := Parse(c.raw, c.district); !reflect.DeepEqual(*got, c.want) {
+			t.Errorf("expected %s to be parsed to %+v, got %+v", c.raw, c.want, *got)
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package analysis
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/common"
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/store"
+)
+
+const (
+	currentPackage = "github.com/Walker088/gorealestate/analysis"
+
+	InvalidFilterError = "AN00001"
+	QueryError         = "AN00002"
+	ScanRowError       = "AN00003"
+)
+
+// Analyzer runs on both postgres and sqlite
+type Analyzer struct {
+	db     store.Querier
+	logger *zap.SugaredLogger
+}
+
+// Filter narrows down the transactions an analysis runs on, empty fields are ignored
+type Filter struct {
+	City         string `json:"city,omitempty"`
+	District     string `json:"district,omitempty"`
+	BuildingType string `json:"building_type,omitempty"`
+	FromSeason   string `json:"from_season,omitempty"` // inclusive, e.g., 111S1
+	ToSeason     string `json:"to_season,omitempty"`   // inclusive, e.g., 112S4
+
+	IncludeFlagged bool `json:"include_flagged,omitempty"` // analyses run on the clean views by default
+}
+
+func New(db store.Querier, logger *zap.SugaredLogger) *Analyzer {
+	return &Analyzer{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// cityCondition filters the city of the _current views, the resolved city is computed, so the
+// codes of the files which resolve to it, i.e., the city itself and the counties merged into
+// it, filter city_raw, the city column of the tables, for the index on (city, district,
+// transaction_date) to apply
+const cityCondition = `city_raw IN (
+	SELECT city_code FROM ref_plvr_land_city WHERE city_code = $%[1]d OR merged_into = $%[1]d
+	UNION SELECT city_code FROM ref_plvr_land_district_history WHERE current_city_code = $%[1]d
+) AND city = $%[1]d`
+
+// Where renders the filter into sql conditions, the returned args are positional starting from $1
+func (f *Filter) Where() (string, []any, *e.ErrorData) {
+	conds := []string{"transaction_date IS NOT NULL"}
+	args := []any{}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if f.City != "" {
+		add(cityCondition, f.City)
+	}
+	if f.District != "" {
+		add("district = $%d", f.District)
+	}
+	if f.BuildingType != "" {
+		add("building_type LIKE $%d || '%%'", f.BuildingType)
+	}
+	if f.FromSeason != "" {
+		from, _, err := common.RocSeasonToDateRange(f.FromSeason)
+		if err != nil {
+			return "", nil, e.NewErrorData(
+				InvalidFilterError,
+				err.Message,
+				fmt.Sprintf("%s.Filter.Where", currentPackage),
+				nil,
+				nil,
+			).WithCause(err)
+		}
+		add("transaction_date >= $%d", *from)
+	}
+	if f.ToSeason != "" {
+		_, to, err := common.RocSeasonToDateRange(f.ToSeason)
+		if err != nil {
+			return "", nil, e.NewErrorData(
+				InvalidFilterError,
+				err.Message,
+				fmt.Sprintf("%s.Filter.Where", currentPackage),
+				nil,
+				nil,
+			).WithCause(err)
+		}
+		add("transaction_date < $%d", *to)
+	}
+	return strings.Join(conds, " AND "), args, nil
+}
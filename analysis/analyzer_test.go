@@ -0,0 +1,59 @@
+package analysis
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestFilterWhere(t *testing.T) {
+	from := time.Date(2023, 1, 1, 0, 0, 0, 0, time.Local)
+	to := time.Date(2024, 1, 1, 0, 0, 0, 0, time.Local)
+	for _, c := range []struct {
+		name   string
+		filter Filter
+		where  string
+		args   []any
+	}{
+		{"empty", Filter{}, "transaction_date IS NOT NULL", []any{}},
+		{
+			"city", Filter{City: "b"},
+			"transaction_date IS NOT NULL AND " + fmt.Sprintf(cityCondition, 1),
+			[]any{"b"},
+		},
+		{
+			"district and building type", Filter{District: "大安區", BuildingType: "住宅大樓"},
+			"transaction_date IS NOT NULL AND district = $1 AND building_type LIKE $2 || '%'",
+			[]any{"大安區", "住宅大樓"},
+		},
+		{
+			"seasons", Filter{FromSeason: "112S1", ToSeason: "112s4"},
+			"transaction_date IS NOT NULL AND transaction_date >= $1 AND transaction_date < $2",
+			[]any{from, to},
+		},
+		{
+			"all", Filter{City: "a", District: "大安區", ToSeason: "112S4"},
+			"transaction_date IS NOT NULL AND " + fmt.Sprintf(cityCondition, 1) + " AND district = $2 AND transaction_date < $3",
+			[]any{"a", "大安區", to},
+		},
+	} {
+		where, args, err := c.filter.Where()
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.name, err.Message)
+			continue
+		}
+		if where != c.where {
+			t.Errorf("%s: expected %s, got %s", c.name, c.where, where)
+		}
+		if !reflect.DeepEqual(args, c.args) {
+			t.Errorf("%s: expected the args %v, got %v", c.name, c.args, args)
+		}
+	}
+
+	for _, f := range []Filter{{FromSeason: "112S5"}, {ToSeason: "112"}} {
+		if _, _, err := f.Where(); err == nil || err.Code != InvalidFilterError {
+			t.Errorf("expected %+v to be invalid, got %v", f, err)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/Walker088/gorealestate/common"
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	DefaultCompsLimit  = 10
+	DefaultCompsMonths = 24
+
+	maxCompsCandidates = 2000
+)
+
+var (
+	roadPattern = regexp.MustCompile(`[^市縣區鄉鎮里村鄰]+?(大道|路|街)([一二三四五六七八九十]+段)?`)
+)
+
+// CompsQuery describes the target property, nil attributes are left out from the similarity
+type CompsQuery struct {
+	City         string   `json:"city,omitempty"`
+	District     string   `json:"district,omitempty"`
+	Address      string   `json:"address,omitempty"`
+	BuildingType string   `json:"building_type,omitempty"`
+	AreaSqm      float64  `json:"area_sqm"`
+	Rooms        *int     `json:"rooms,omitempty"`
+	AgeYears     *float64 `json:"age_years,omitempty"`
+	Floor        *int     `json:"floor,omitempty"`
+	Months       int      `json:"months,omitempty"` // look back period of the transactions
+	Limit        int      `json:"limit,omitempty"`
+
+	IncludeFlagged bool `json:"include_flagged,omitempty"`
+}
+
+type Comparable struct {
+	Source          string    `json:"source"`
+	SerialNumber    string    `json:"serial_number"`
+	City            string    `json:"city"`
+	District        string    `json:"district"`
+	Address         string    `json:"address"`
+	BuildingType    string    `json:"building_type"`
+	TransactionDate time.Time `json:"transaction_date"`
+	AreaSqm         float64   `json:"area_sqm"`
+	Rooms           int       `json:"rooms"`
+	Floor           *int      `json:"floor,omitempty"`
+	AgeYears        *float64  `json:"age_years,omitempty"`
+	TotalPrice      int64     `json:"total_price"`
+	UnitPricePerSqm int64     `json:"unit_price_per_sqm"`
+	Similarity      float64   `json:"similarity"`
+}
+
+type CompsResult struct {
+	Comparables   []Comparable `json:"comparables"`
+	EstimatedLow  float64      `json:"estimated_low"`
+	Estimated     float64      `json:"estimated"`
+	EstimatedHigh float64      `json:"estimated_high"`
+}
+
+func (a *Analyzer) Comps(ctx context.Context, q *CompsQuery) (*CompsResult, *e.ErrorData) {
+	if q.AreaSqm <= 0 {
+		return nil, e.NewErrorData(
+			InvalidFilterError,
+			fmt.Sprintf("area of the target property should be positive, got %f", q.AreaSqm),
+			fmt.Sprintf("%s.Comps", currentPackage),
+			nil,
+			nil,
+		)
+	}
+	if q.City == "" && q.District == "" && q.Address == "" {
+		return nil, e.NewErrorData(
+			InvalidFilterError,
+			"either city, district or address of the target property is required",
+			fmt.Sprintf("%s.Comps", currentPackage),
+			nil,
+			nil,
+		)
+	}
+	if q.Limit <= 0 {
+		q.Limit = DefaultCompsLimit
+	}
+	if q.Months <= 0 {
+		q.Months = DefaultCompsMonths
+	}
+
+	candidates, errData := a.compsCandidates(ctx, q)
+	if errData != nil {
+		return nil, errData
+	}
+	road := roadPattern.FindString(q.Address)
+	for i := range candidates {
+		candidates[i].Similarity = similarity(q, road, &candidates[i])
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Similarity > candidates[j].Similarity
+	})
+	if len(candidates) > q.Limit {
+		candidates = candidates[:q.Limit]
+	}
+
+	res := &CompsResult{Comparables: candidates}
+	if len(candidates) > 0 {
+		res.EstimatedLow = weightedPercentile(candidates, 0.25) * q.AreaSqm
+		res.Estimated = weightedPercentile(candidates, 0.5) * q.AreaSqm
+		res.EstimatedHigh = weightedPercentile(candidates, 0.75) * q.AreaSqm
+	}
+	a.logger.Debugf("[analysis] %d comparables found for %+v", len(candidates), *q)
+	return res, nil
+}
+
+func (a *Analyzer) compsCandidates(ctx context.Context, q *CompsQuery) ([]Comparable, *e.ErrorData) {
+	conds := []string{
+		"transaction_date >= $1",
+		"unit_price_per_sqm > 0",
+		"building_area_sqm BETWEEN $2 AND $3",
+	}
+	args := []any{time.Now().AddDate(0, -q.Months, 0), q.AreaSqm / 2, q.AreaSqm * 2}
+	add := func(cond string, arg any) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+	if q.City != "" {
+		add(cityCondition, q.City)
+	}
+	if q.District != "" {
+		add("district = $%d", q.District)
+	} else if q.Address != "" {
+		add(a.db.Dialect().Contains("$%d", "district"), q.Address)
+	}
+	where := strings.Join(conds, " AND ")
+
+	selectFrom := func(source string, table string) string {
+		return fmt.Sprintf(`
+		SELECT '%s', COALESCE(serial_number, ''), city, COALESCE(district, ''), COALESCE(address, ''),
+			COALESCE(building_type, ''), transaction_date, CAST(building_area_sqm AS DOUBLE PRECISION),
+			CAST(COALESCE(number_of_rooms, 0) AS INTEGER), COALESCE(floor, ''), construction_complete_date,
+			CAST(COALESCE(total_price, 0) AS BIGINT), CAST(unit_price_per_sqm AS BIGINT)
+		FROM %s
+		WHERE %s
+		`, source, table, where)
+	}
+	query := fmt.Sprintf(
+		"%s UNION ALL %s ORDER BY 7 DESC LIMIT %d",
+		selectFrom("house_sale", CleanTable("plvr_land_house_sale", q.IncludeFlagged)),
+		selectFrom("new_house", CleanTable("plvr_land_new_house", q.IncludeFlagged)),
+		maxCompsCandidates,
+	)
+
+	rows, err := a.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.compsCandidates", currentPackage),
+		)
+	}
+	defer rows.Close()
+
+	candidates := []Comparable{}
+	for rows.Next() {
+		var c Comparable
+		var floor string
+		var completed *time.Time
+		if err := rows.Scan(
+			&c.Source, &c.SerialNumber, &c.City, &c.District, &c.Address,
+			&c.BuildingType, &c.TransactionDate, &c.AreaSqm, &c.Rooms,
+			&floor, &completed, &c.TotalPrice, &c.UnitPricePerSqm,
+		); err != nil {
+			return nil, e.Wrap(
+				ScanRowError,
+				err,
+				fmt.Sprintf("%s.compsCandidates", currentPackage),
+			)
+		}
+		if f, ok := common.ParseFloor(floor); ok {
+			c.Floor = &f
+		}
+		if completed != nil {
+			age := c.TransactionDate.Sub(*completed).Hours() / 24 / 365.25
+			c.AgeYears = &age
+		}
+		candidates = append(candidates, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.compsCandidates", currentPackage),
+		)
+	}
+	return candidates, nil
+}
+
+// similarity scores a candidate within (0, 1], the closer the attributes the higher the score
+func similarity(q *CompsQuery, road string, c *Comparable) float64 {
+	d := 3 * math.Abs(math.Log(c.AreaSqm/q.AreaSqm))
+	if q.Rooms != nil {
+		d += 0.3 * math.Abs(float64(*q.Rooms-c.Rooms))
+	}
+	if q.AgeYears != nil {
+		if c.AgeYears == nil {
+			d += 0.5
+		} else {
+			d += 0.05 * math.Abs(*q.AgeYears-*c.AgeYears)
+		}
+	}
+	if q.Floor != nil {
+		if c.Floor == nil {
+			d += 0.5
+		} else {
+			d += 0.1 * math.Abs(float64(*q.Floor-*c.Floor))
+		}
+	}
+	if q.BuildingType != "" && !strings.HasPrefix(c.BuildingType, q.BuildingType) {
+		d += 0.5
+	}
+	if road != "" && !strings.Contains(c.Address, road) {
+		d += 0.3
+	}
+	d += 0.02 * time.Since(c.TransactionDate).Hours() / 24 / 30
+	return 1 / (1 + d)
+}
+
+// weightedPercentile returns the similarity weighted percentile of the unit prices
+func weightedPercentile(comps []Comparable, p float64) float64 {
+	sorted := make([]Comparable, len(comps))
+	copy(sorted, comps)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].UnitPricePerSqm < sorted[j].UnitPricePerSqm
+	})
+	var total float64
+	for _, c := range sorted {
+		total += c.Similarity
+	}
+	var acc float64
+	for _, c := range sorted {
+		acc += c.Similarity
+		if acc >= p*total {
+			return float64(c.UnitPricePerSqm)
+		}
+	}
+	return float64(sorted[len(sorted)-1].UnitPricePerSqm)
+}
@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/Walker088/gorealestate/store"
+)
+
+func TestSimilarity(t *testing.T) {
+	rooms, age, floor := 3, 10.0, 5
+	q := &CompsQuery{AreaSqm: 100, Rooms: &rooms, AgeYears: &age, Floor: &floor, BuildingType: "住宅大樓"}
+	target := func() Comparable {
+		r, a, f := rooms, age, floor
+		return Comparable{
+			AreaSqm: 100, Rooms: r, AgeYears: &a, Floor: &f, BuildingType: "住宅大樓(11層含以上有電梯)",
+			Address: "臺北市大安區忠孝東路四段1號", TransactionDate: time.Now(),
+		}
+	}
+	for _, c := range []struct {
+		name   string
+		change func(*Comparable)
+		want   float64
+	}{
+		{"same", func(*Comparable) {}, 1},
+		{"twice the area", func(c *Comparable) { c.AreaSqm = 200 }, 1 / (1 + 3*math.Ln2)},
+		{"half the area", func(c *Comparable) { c.AreaSqm = 50 }, 1 / (1 + 3*math.Ln2)},
+		{"two more rooms", func(c *Comparable) { c.Rooms = 5 }, 1 / 1.6},
+		{"unknown age", func(c *Comparable) { c.AgeYears = nil }, 1 / 1.5},
+		{"ten years older", func(c *Comparable) { a := 20.0; c.AgeYears = &a }, 1 / 1.5},
+		{"unknown floor", func(c *Comparable) { c.Floor = nil }, 1 / 1.5},
+		{"other building type", func(c *Comparable) { c.BuildingType = "公寓(5樓含以下無電梯)" }, 1 / 1.5},
+		{"other road", func(c *Comparable) { c.Address = "臺北市大安區復興南路一段1號" }, 1 / 1.3},
+		{"a year ago", func(c *Comparable) { c.TransactionDate = time.Now().Add(-360 * 24 * time.Hour) }, 1 / 1.24},
+	} {
+		comp := target()
+		c.change(&comp)
+		if got := similarity(q, "忠孝東路四段", &comp); math.Abs(got-c.want) > 1e-6 {
+			t.Errorf("%s: expected the similarity %f, got %f", c.name, c.want, got)
+		}
+	}
+
+	// the attributes left out of the query do not count
+	comp := target()
+	comp.Rooms, comp.AgeYears, comp.Floor, comp.BuildingType = 9, nil, nil, "店面"
+	if got := similarity(&CompsQuery{AreaSqm: 100}, "", &comp); math.Abs(got-1) > 1e-6 {
+		t.Errorf("expected the similarity 1 of a query of the area alone, got %f", got)
+	}
+}
+
+func TestWeightedPercentile(t *testing.T) {
+	comps := func(prices []int64, weights []float64) []Comparable {
+		c := make([]Comparable, len(prices))
+		for i := range prices {
+			c[i] = Comparable{UnitPricePerSqm: prices[i], Similarity: weights[i]}
+		}
+		return c
+	}
+	for _, c := range []struct {
+		name  string
+		comps []Comparable
+		p     float64
+		want  float64
+	}{
+		{"lower quartile", comps([]int64{400, 100, 300, 200}, []float64{1, 1, 1, 1}), 0.25, 100},
+		{"median", comps([]int64{400, 100, 300, 200}, []float64{1, 1, 1, 1}), 0.5, 200},
+		{"upper quartile", comps([]int64{400, 100, 300, 200}, []float64{1, 1, 1, 1}), 0.75, 300},
+		{"heavy high price", comps([]int64{400, 100, 300, 200}, []float64{7, 1, 1, 1}), 0.5, 400},
+		{"heavy low price", comps([]int64{400, 100, 300, 200}, []float64{1, 7, 1, 1}), 0.75, 200},
+		{"single", comps([]int64{250}, []float64{0.2}), 0.25, 250},
+	} {
+		before := append([]Comparable{}, c.comps...)
+		if got := weightedPercentile(c.comps, c.p); got != c.want {
+			t.Errorf("%s: expected %f, got %f", c.name, c.want, got)
+		}
+		if !reflect.DeepEqual(before, c.comps) {
+			t.Errorf("%s: expected the comparables to be left in their order, got %v", c.name, c.comps)
+		}
+	}
+}
+
+func TestComps(t *testing.T) {
+	a, s := newTestAnalyzer(t)
+	recent := func(days int) *time.Time {
+		d := time.Now().AddDate(0, 0, -days).UTC().Truncate(24 * time.Hour)
+		return &d
+	}
+	save(t, s, store.HouseSaleTable,
+		testSale{serial: "C1", district: "大安區", date: recent(10), buildingType: "住宅大樓", area: 100, rooms: 3, unitPrice: 500000},
+		testSale{serial: "C2", district: "大安區", date: recent(20), buildingType: "住宅大樓", area: 120, rooms: 3, unitPrice: 600000},
+		testSale{serial: "C3", district: "大安區", date: recent(30), buildingType: "公寓", area: 180, rooms: 4, unitPrice: 400000},
+		// out of the area range, the district and the period
+		testSale{serial: "C4", district: "大安區", date: recent(10), buildingType: "住宅大樓", area: 300, rooms: 3, unitPrice: 500000},
+		testSale{serial: "C5", district: "信義區", date: recent(10), buildingType: "住宅大樓", area: 100, rooms: 3, unitPrice: 900000},
+		testSale{serial: "C6", district: "大安區", date: recent(800), buildingType: "住宅大樓", area: 100, rooms: 3, unitPrice: 300000},
+	)
+
+	rooms := 3
+	res, errData := a.Comps(context.Background(), &CompsQuery{City: "a", District: "大安區", AreaSqm: 100, Rooms: &rooms, BuildingType: "住宅大樓"})
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	serials := []string{}
+	for _, c := range res.Comparables {
+		serials = append(serials, c.SerialNumber)
+	}
+	if want := []string{"C1", "C2", "C3"}; !reflect.DeepEqual(serials, want) {
+		t.Errorf("expected the comparables %v, got %v", want, serials)
+	}
+	// C3 weighs too little to be the lower quartile
+	if res.EstimatedLow != 500000*100 || res.Estimated != 500000*100 || res.EstimatedHigh != 600000*100 {
+		t.Errorf("expected the estimates 50000000, 50000000 and 60000000, got %.0f, %.0f and %.0f", res.EstimatedLow, res.Estimated, res.EstimatedHigh)
+	}
+
+	for _, q := range []*CompsQuery{{City: "a"}, {AreaSqm: 100}} {
+		if _, errData := a.Comps(context.Background(), q); errData == nil || errData.Code != InvalidFilterError {
+			t.Errorf("expected %+v to be invalid, got %v", q, errData)
+		}
+	}
+}
@@ -0,0 +1,270 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+
+	"github.com/Walker088/gorealestate/common"
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/store"
+)
+
+const (
+	FlagRelatedParty   = "related_party"
+	FlagIncludesExtras = "includes_extras"
+	FlagPartialShare   = "partial_share"
+	FlagSpecialDeal    = "special_deal"
+	FlagAreaInvalid    = "area_invalid"
+	FlagPriceInvalid   = "price_invalid"
+	FlagPriceOutlier   = "price_outlier"
+
+	DefaultZScoreThreshold = 3.0
+	DefaultMinGroupSamples = 10
+
+	FlagTransactionsError = "AN00004"
+)
+
+var (
+	TransactionTables = []string{"plvr_land_house_sale", "plvr_land_new_house", "plvr_land_rental"}
+
+	// NotesRules classify a transaction by its notes (備註), the patterns stick to the regular expressions
+	// postgres and go agree on, the latter match them on sqlite
+	NotesRules = []NotesRule{
+		{FlagRelatedParty, `親友|員工|特殊關係|親屬|二親等|關係人`},
+		{FlagIncludesExtras, `增建|加蓋|違建|未登記建物|(含|附)(裝潢|傢俱|家具|家電|設備)`},
+		{FlagPartialShare, `持分|應有部分|部分移轉`},
+		{FlagSpecialDeal, `急買急賣|瑕疵|凶宅|兇宅|債權債務|法拍|政府機關|公共設施保留地|畸零地|地上權`},
+	}
+)
+
+type NotesRule struct {
+	Flag    string
+	Pattern string
+}
+
+// FlagOptions restricts the recomputation to the transactions dated in Seasons, e.g., 112S1,
+// and to the ones without a date when Undated is set, every transaction is flagged again
+// when both are unset
+type FlagOptions struct {
+	ZScoreThreshold float64
+	MinGroupSamples int
+	Seasons         []string
+	Undated         bool
+}
+
+type FlagSummary struct {
+	Table string `json:"table"`
+	Flag  string `json:"flag"`
+	Rows  int64  `json:"rows"`
+}
+
+// CleanTable returns the view excluding the flagged transactions unless includeFlagged is set,
+// both views resolve the city and district to the current ones, e.g., 豐原市 of 臺中縣 to 豐原區 of
+// 臺中市, and keep the codes of the files in city_raw and district_raw
+func CleanTable(table string, includeFlagged bool) string {
+	if includeFlagged {
+		return table + "_current"
+	}
+	return table + "_clean"
+}
+
+// FlagTransactions recomputes the flags of the transactions of the options, the price outliers
+// are detected by the unit price z-score within the same district and season, i.e., a season
+// is flagged on its own and in its own database transaction, which locks the rows of its partition
+// only on postgres
+func (a *Analyzer) FlagTransactions(ctx context.Context, opt *FlagOptions) ([]FlagSummary, *e.ErrorData) {
+	if opt.ZScoreThreshold <= 0 {
+		opt.ZScoreThreshold = DefaultZScoreThreshold
+	}
+	if opt.MinGroupSamples <= 0 {
+		opt.MinGroupSamples = DefaultMinGroupSamples
+	}
+	scopes, errData := flagScopes(opt)
+	if errData != nil {
+		return nil, errData
+	}
+
+	inTx, errData := a.flagTx()
+	if errData != nil {
+		return nil, errData
+	}
+	summaries := []FlagSummary{}
+	for _, scope := range scopes {
+		err := inTx(ctx, func(f flagger) error {
+			for _, table := range TransactionTables {
+				if err := flagTable(ctx, f, table, scope, opt, &summaries); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, e.Wrap(
+				FlagTransactionsError,
+				err,
+				fmt.Sprintf("%s.FlagTransactions", currentPackage),
+			)
+		}
+	}
+	a.logger.Infof("[analysis] transactions flagged: %+v", summaries)
+	return summaries, nil
+}
+
+// flagger sets the flags of the tables within a database transaction, the flags are an array
+// on postgres and a json array on sqlite
+type flagger interface {
+	dialect() store.Dialect
+	// reset clears the flags of the rows of table matching scope
+	reset(ctx context.Context, table string, scope string) error
+	// flag appends name to the flags of the rows of table matching scope and cond
+	flag(ctx context.Context, table string, scope string, name string, cond string, args ...any) (int64, error)
+	// flagOutliers appends FlagPriceOutlier to the flags of the unflagged rows of table matching scope
+	// whose unit price z-score within the district and season exceeds the threshold of opt
+	flagOutliers(ctx context.Context, table string, scope string, opt *FlagOptions) (int64, error)
+}
+
+// flagTx returns the runner of the database transactions of the flags of the backend of a
+func (a *Analyzer) flagTx() (func(ctx context.Context, fn func(flagger) error) error, *e.ErrorData) {
+	target := fmt.Sprintf("%s.FlagTransactions", currentPackage)
+	if a.db.Dialect() == store.DialectSQLite {
+		db, errData := store.SQLiteDB(a.db, "flagging the transactions", target)
+		if errData != nil {
+			return nil, errData
+		}
+		return func(ctx context.Context, fn func(flagger) error) error {
+			tx, err := db.BeginTx(ctx, nil)
+			if err != nil {
+				return err
+			}
+			if err := fn(&sqliteFlagger{tx: tx}); err != nil {
+				tx.Rollback()
+				return err
+			}
+			return tx.Commit()
+		}, nil
+	}
+	// the rows are matched by their ctid along with their partition
+	pool, errData := store.PgPool(a.db, "flagging the transactions", target)
+	if errData != nil {
+		return nil, errData
+	}
+	return func(ctx context.Context, fn func(flagger) error) error {
+		return pgx.BeginFunc(ctx, pool, func(tx pgx.Tx) error {
+			return fn(&pgFlagger{tx: tx})
+		})
+	}, nil
+}
+
+// flagScopes renders the conditions on transaction_date of the options, one per season, the
+// bounds are literals so that the partitions out of a season are pruned while planning
+func flagScopes(opt *FlagOptions) ([]string, *e.ErrorData) {
+	if len(opt.Seasons) == 0 && !opt.Undated {
+		return []string{"TRUE"}, nil
+	}
+	scopes := []string{}
+	for _, season := range opt.Seasons {
+		from, to, errData := common.RocSeasonToDateRange(season)
+		if errData != nil {
+			return nil, errData
+		}
+		scopes = append(scopes, fmt.Sprintf(
+			"transaction_date >= '%s' AND transaction_date < '%s'",
+			from.Format(time.DateOnly), to.Format(time.DateOnly),
+		))
+	}
+	if opt.Undated {
+		scopes = append(scopes, "transaction_date IS NULL")
+	}
+	return scopes, nil
+}
+
+// flagTable clears and sets the flags of the transactions of table matching scope
+func flagTable(ctx context.Context, f flagger, table string, scope string, opt *FlagOptions, summaries *[]FlagSummary) error {
+	// the rows of the scopes add up in a single summary per table and flag
+	count := func(name string, rows int64) {
+		for i := range *summaries {
+			if (*summaries)[i].Table == table && (*summaries)[i].Flag == name {
+				(*summaries)[i].Rows += rows
+				return
+			}
+		}
+		*summaries = append(*summaries, FlagSummary{Table: table, Flag: name, Rows: rows})
+	}
+	flag := func(name string, cond string, args ...any) error {
+		rows, err := f.flag(ctx, table, scope, name, cond, args...)
+		if err != nil {
+			return fmt.Errorf("%s on %s: %w", name, table, err)
+		}
+		count(name, rows)
+		return nil
+	}
+
+	if err := f.reset(ctx, table, scope); err != nil {
+		return fmt.Errorf("reset flags on %s: %w", table, err)
+	}
+	for _, rule := range NotesRules {
+		if err := flag(rule.Flag, f.dialect().Matches("notes", "$1"), rule.Pattern); err != nil {
+			return err
+		}
+	}
+	if err := flag(FlagAreaInvalid, "transaction_type LIKE '%建物%' AND COALESCE(building_area_sqm, 0) <= 0"); err != nil {
+		return err
+	}
+	if err := flag(FlagPriceInvalid, "COALESCE(total_price, 0) <= 0"); err != nil {
+		return err
+	}
+	rows, err := f.flagOutliers(ctx, table, scope, opt)
+	if err != nil {
+		return fmt.Errorf("%s on %s: %w", FlagPriceOutlier, table, err)
+	}
+	count(FlagPriceOutlier, rows)
+	return nil
+}
+
+type pgFlagger struct {
+	tx pgx.Tx
+}
+
+func (f *pgFlagger) dialect() store.Dialect {
+	return store.DialectPostgres
+}
+
+func (f *pgFlagger) reset(ctx context.Context, table string, scope string) error {
+	_, err := f.tx.Exec(ctx, fmt.Sprintf("UPDATE %s SET flags = '{}' WHERE (%s) AND cardinality(flags) > 0", table, scope))
+	return err
+}
+
+func (f *pgFlagger) flag(ctx context.Context, table string, scope string, name string, cond string, args ...any) (int64, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s SET flags = array_append(flags, '%s') WHERE (%s) AND %s",
+		table, name, scope, cond,
+	)
+	tag, err := f.tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// flagOutliers computes the statistics of the seasons of scope, they only depend on its own transactions
+func (f *pgFlagger) flagOutliers(ctx context.Context, table string, scope string, opt *FlagOptions) (int64, error) {
+	outlier := fmt.Sprintf(`
+	(tableoid, ctid) IN (
+		SELECT t.tableoid, t.ctid
+		FROM %[1]s t
+		JOIN (
+			SELECT city, district, date_trunc('quarter', transaction_date) AS season,
+				AVG(unit_price_per_sqm) AS mean, STDDEV_SAMP(unit_price_per_sqm) AS stddev
+			FROM %[1]s
+			WHERE (%[2]s) AND cardinality(flags) = 0 AND unit_price_per_sqm > 0
+			GROUP BY 1, 2, 3
+			HAVING COUNT(*) >= $1
+		) s ON s.city = t.city AND s.district = t.district AND s.season = date_trunc('quarter', t.transaction_date)
+		WHERE (%[2]s) AND cardinality(t.flags) = 0 AND t.unit_price_per_sqm > 0 AND s.stddev > 0
+			AND ABS(t.unit_price_per_sqm - s.mean) / s.stddev > $2
+	)`, table, scope)
+	return f.flag(ctx, table, scope, FlagPriceOutlier, outlier, opt.MinGroupSamples, opt.ZScoreThreshold)
+}
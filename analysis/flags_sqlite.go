@@ -0,0 +1,138 @@
+package analysis
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/Walker088/gorealestate/store"
+)
+
+const (
+	// outlierBatchSize is the number of rows flagged as outliers per statement
+	outlierBatchSize = 500
+)
+
+// sqliteFlagger appends the flags to the json arrays, sqlite has no standard deviation, the
+// z-scores are computed here
+type sqliteFlagger struct {
+	tx *sql.Tx
+}
+
+// priceGroup is a district and season of the outlier detection
+type priceGroup struct {
+	city     string
+	district string
+	year     int
+	quarter  int
+}
+
+type priceSample struct {
+	rowid int64
+	price float64
+}
+
+func (f *sqliteFlagger) dialect() store.Dialect {
+	return store.DialectSQLite
+}
+
+func (f *sqliteFlagger) reset(ctx context.Context, table string, scope string) error {
+	_, err := f.tx.ExecContext(ctx, fmt.Sprintf("UPDATE %s SET flags = '[]' WHERE (%s) AND flags <> '[]'", table, scope))
+	return err
+}
+
+func (f *sqliteFlagger) flag(ctx context.Context, table string, scope string, name string, cond string, args ...any) (int64, error) {
+	query := fmt.Sprintf(
+		"UPDATE %s SET flags = json_insert(flags, '$[#]', '%s') WHERE (%s) AND %s",
+		table, name, scope, cond,
+	)
+	res, err := f.tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+	return res.RowsAffected()
+}
+
+// flagOutliers reads the unit prices of scope, the rows without a district or a date belong to
+// no group, as on postgres
+func (f *sqliteFlagger) flagOutliers(ctx context.Context, table string, scope string, opt *FlagOptions) (int64, error) {
+	d := f.dialect()
+	query := fmt.Sprintf(`
+	SELECT rowid, city, district, %s, %s, unit_price_per_sqm
+	FROM %s
+	WHERE (%s) AND flags = '[]' AND unit_price_per_sqm > 0 AND district IS NOT NULL AND transaction_date IS NOT NULL
+	`, d.Year("transaction_date"), d.Quarter("transaction_date"), table, scope)
+	rows, err := f.tx.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	groups := map[priceGroup][]priceSample{}
+	for rows.Next() {
+		var g priceGroup
+		var s priceSample
+		if err := rows.Scan(&s.rowid, &g.city, &g.district, &g.year, &g.quarter, &s.price); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		groups[g] = append(groups[g], s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	ids := []string{}
+	for _, samples := range groups {
+		for _, s := range outliers(samples, opt) {
+			ids = append(ids, strconv.FormatInt(s.rowid, 10))
+		}
+	}
+	var flagged int64
+	for len(ids) > 0 {
+		n := len(ids)
+		if n > outlierBatchSize {
+			n = outlierBatchSize
+		}
+		cond := fmt.Sprintf("rowid IN (%s)", strings.Join(ids[:n], ", "))
+		rows, err := f.flag(ctx, table, scope, FlagPriceOutlier, cond)
+		if err != nil {
+			return flagged, err
+		}
+		flagged += rows
+		ids = ids[n:]
+	}
+	return flagged, nil
+}
+
+// outliers returns the samples of a group whose z-score exceeds the threshold of opt, the
+// standard deviation is the sample one, as STDDEV_SAMP of postgres, the groups of less than
+// MinGroupSamples have none
+func outliers(samples []priceSample, opt *FlagOptions) []priceSample {
+	n := len(samples)
+	if n < opt.MinGroupSamples || n < 2 {
+		return nil
+	}
+	var sum float64
+	for _, s := range samples {
+		sum += s.price
+	}
+	mean := sum / float64(n)
+	var squares float64
+	for _, s := range samples {
+		squares += (s.price - mean) * (s.price - mean)
+	}
+	stddev := math.Sqrt(squares / float64(n-1))
+	if stddev == 0 {
+		return nil
+	}
+	found := []priceSample{}
+	for _, s := range samples {
+		if math.Abs(s.price-mean)/stddev > opt.ZScoreThreshold {
+			found = append(found, s)
+		}
+	}
+	return found
+}
@@ -0,0 +1,255 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/config"
+	"github.com/Walker088/gorealestate/database"
+	"github.com/Walker088/gorealestate/migrations"
+	"github.com/Walker088/gorealestate/store"
+)
+
+// newTestAnalyzer migrates a temporary sqlite database
+func newTestAnalyzer(t *testing.T) (*Analyzer, *store.SQLite) {
+	t.Helper()
+	cfg := &config.PgConfig{
+		Driver:     config.DriverSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "test.db"),
+		DbName:     "test",
+	}
+	logger := zap.NewNop().Sugar()
+	sm, errData := migrations.New(cfg, logger)
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	defer sm.Stop()
+	if errData := sm.Migrate(); errData != nil {
+		t.Fatal(errData)
+	}
+	db, errData := database.NewSQLite(cfg, logger)
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	t.Cleanup(func() { db.Close() })
+	s := store.NewSQLite(db, logger)
+	return New(s, logger), s
+}
+
+// saleColumns are the columns of the transactions saved by the tests of the analyses
+var saleColumns = []string{
+	"serial_number", "city", "district", "transaction_type", "transaction_date", "building_type",
+	"building_area_sqm", "number_of_rooms", "total_price", "unit_price_per_sqm", "notes",
+}
+
+type testSale struct {
+	serial       string
+	district     string
+	date         *time.Time
+	buildingType string
+	area         float64
+	rooms        int
+	totalPrice   int64
+	unitPrice    int64
+	notes        string
+}
+
+// save inserts the transactions of table
+func save(t *testing.T, s *store.SQLite, table string, sales ...testSale) {
+	t.Helper()
+	for _, sale := range sales {
+		_, errData := s.Save(context.Background(), store.Transaction{
+			Table:   table,
+			Columns: saleColumns,
+			Values: []any{
+				sale.serial, "a", sale.district, "房地(土地+建物)", sale.date, sale.buildingType,
+				sale.area, sale.rooms, sale.totalPrice, sale.unitPrice, sale.notes,
+			},
+		})
+		if errData != nil {
+			t.Fatal(errData)
+		}
+	}
+}
+
+func date(year int, month time.Month, day int) *time.Time {
+	d := time.Date(year, month, day, 0, 0, 0, 0, time.UTC)
+	return &d
+}
+
+func TestFlagTransactionsSQLite(t *testing.T) {
+	a, s := newTestAnalyzer(t)
+	ctx := context.Background()
+	sales := []testSale{}
+	for i := 0; i < 12; i++ {
+		sales = append(sales, testSale{
+			serial: fmt.Sprintf("A%02d", i), district: "大安區", date: date(2023, 2, 1+i),
+			area: 100, totalPrice: 10000000, unitPrice: 100000 + int64(i%3)*1000,
+		})
+	}
+	sales = append(sales,
+		// the outlier of the district and season
+		testSale{serial: "B1", district: "大安區", date: date(2023, 3, 1), area: 100, totalPrice: 100000000, unitPrice: 1000000},
+		// the same price in another season, i.e., too few samples
+		testSale{serial: "B2", district: "大安區", date: date(2023, 4, 1), area: 100, totalPrice: 100000000, unitPrice: 1000000},
+		testSale{serial: "C1", district: "大安區", date: date(2023, 2, 20), area: 100, totalPrice: 5000000, unitPrice: 50000, notes: "親友間交易"},
+		testSale{serial: "C2", district: "大安區", date: date(2023, 2, 21), area: 0, totalPrice: 0},
+		testSale{serial: "C3", district: "大安區", area: 100, totalPrice: 10000000, unitPrice: 100000, notes: "含裝潢"},
+	)
+	save(t, s, store.HouseSaleTable, sales...)
+
+	flags := func() map[string]string {
+		t.Helper()
+		rows, err := s.Query(ctx, "SELECT serial_number, flags FROM plvr_land_house_sale WHERE flags <> '[]'")
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rows.Close()
+		got := map[string]string{}
+		for rows.Next() {
+			var serial, f string
+			if err := rows.Scan(&serial, &f); err != nil {
+				t.Fatal(err)
+			}
+			got[serial] = f
+		}
+		if err := rows.Err(); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	// flagging twice gives the same flags, i.e., they are reset first
+	for i := 0; i < 2; i++ {
+		summaries, errData := a.FlagTransactions(ctx, &FlagOptions{})
+		if errData != nil {
+			t.Fatal(errData)
+		}
+		want := map[string]string{
+			"B1": `["price_outlier"]`,
+			"C1": `["related_party"]`,
+			"C2": `["area_invalid","price_invalid"]`,
+			"C3": `["includes_extras"]`,
+		}
+		if got := flags(); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected the flags %v, got %v", want, got)
+		}
+		for _, summary := range summaries {
+			if summary.Table == "plvr_land_house_sale" && summary.Flag == FlagPriceOutlier && summary.Rows != 1 {
+				t.Errorf("expected 1 outlier, got %d", summary.Rows)
+			}
+		}
+	}
+
+	// the seasons out of the scope keep their flags
+	if _, errData := a.FlagTransactions(ctx, &FlagOptions{Seasons: []string{"112S2"}, ZScoreThreshold: 100}); errData != nil {
+		t.Fatal(errData)
+	}
+	if got := flags()["B1"]; got != `["price_outlier"]` {
+		t.Errorf("expected B1 to keep its flags out of the scope, got %s", got)
+	}
+	if _, errData := a.FlagTransactions(ctx, &FlagOptions{Seasons: []string{"112S1"}, ZScoreThreshold: 100}); errData != nil {
+		t.Fatal(errData)
+	}
+	if got, ok := flags()["B1"]; ok {
+		t.Errorf("expected B1 not to be an outlier of the z-score 100, got %s", got)
+	}
+}
+
+func TestOutliers(t *testing.T) {
+	samples := func(prices ...float64) []priceSample {
+		s := make([]priceSample, len(prices))
+		for i, p := range prices {
+			s[i] = priceSample{rowid: int64(i + 1), price: p}
+		}
+		return s
+	}
+	for _, c := range []struct {
+		name    string
+		samples []priceSample
+		opt     FlagOptions
+		want    []int64
+	}{
+		{"one far", samples(10, 10, 11, 11, 10, 10, 11, 11, 10, 100), FlagOptions{ZScoreThreshold: 2, MinGroupSamples: 5}, []int64{10}},
+		{"threshold", samples(10, 10, 11, 11, 10, 10, 11, 11, 10, 100), FlagOptions{ZScoreThreshold: 3, MinGroupSamples: 5}, nil},
+		{"too few", samples(10, 10, 11, 11, 10, 10, 11, 11, 10, 100), FlagOptions{ZScoreThreshold: 2, MinGroupSamples: 11}, nil},
+		{"same prices", samples(10, 10, 10, 10, 10), FlagOptions{ZScoreThreshold: 1, MinGroupSamples: 1}, nil},
+		{"single", samples(10), FlagOptions{ZScoreThreshold: 1, MinGroupSamples: 1}, nil},
+	} {
+		var got []int64
+		for _, s := range outliers(c.samples, &c.opt) {
+			got = append(got, s.rowid)
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: expected the outliers %v, got %v", c.name, c.want, got)
+		}
+	}
+}
+
+func TestNotesRules(t *testing.T) {
+	classify := func(notes string) []string {
+		flags := []string{}
+		for _, rule := range NotesRules {
+			if regexp.MustCompile(rule.Pattern).MatchString(notes) {
+				flags = append(flags, rule.Flag)
+			}
+		}
+		return flags
+	}
+	for notes, want := range map[string][]string{
+		"親友、員工、共有人或其他特殊關係間之交易": {FlagRelatedParty},
+		"二親等間買賣":     {FlagRelatedParty},
+		"含增建或未登記建物":  {FlagIncludesExtras},
+		"附裝潢及家具":     {FlagIncludesExtras},
+		"含傢俱":        {FlagIncludesExtras},
+		"持分移轉":       {FlagPartialShare},
+		"應有部分買賣":     {FlagPartialShare},
+		"急買急賣":       {FlagSpecialDeal},
+		"凶宅":         {FlagSpecialDeal},
+		"法拍":         {FlagSpecialDeal},
+		"瑕疵物件，親屬間交易": {FlagRelatedParty, FlagSpecialDeal},
+		"持分移轉含裝潢":    {FlagIncludesExtras, FlagPartialShare},
+		"":           {},
+		"車位價格另計":     {},
+		"含車位":        {},
+		"頂樓":         {},
+	} {
+		if got := classify(notes); !reflect.DeepEqual(got, want) {
+			t.Errorf("expected %q to be flagged %v, got %v", notes, want, got)
+		}
+	}
+}
+
+func TestFlagScopes(t *testing.T) {
+	for _, c := range []struct {
+		opt  FlagOptions
+		want []string
+	}{
+		{FlagOptions{}, []string{"TRUE"}},
+		{FlagOptions{Seasons: []string{"112S4"}}, []string{"transaction_date >= '2023-10-01' AND transaction_date < '2024-01-01'"}},
+		{FlagOptions{Seasons: []string{"112S1"}, Undated: true}, []string{
+			"transaction_date >= '2023-01-01' AND transaction_date < '2023-04-01'",
+			"transaction_date IS NULL",
+		}},
+		{FlagOptions{Undated: true}, []string{"transaction_date IS NULL"}},
+	} {
+		got, errData := flagScopes(&c.opt)
+		if errData != nil {
+			t.Errorf("%+v: unexpected error %s", c.opt, errData.Message)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%+v: expected %v, got %v", c.opt, c.want, got)
+		}
+	}
+	if _, errData := flagScopes(&FlagOptions{Seasons: []string{"112S9"}}); errData == nil {
+		t.Error("expected 112S9 to be invalid")
+	}
+}
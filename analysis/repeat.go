@@ -0,0 +1,81 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+// RepeatSale is a pair of consecutive sales of the same unit, matched by the normalized
+// address (including the floor) and the building area
+type RepeatSale struct {
+	City             string    `json:"city"`
+	District         string    `json:"district"`
+	Address          string    `json:"address"`
+	AreaSqm          float64   `json:"area_sqm"`
+	FirstDate        time.Time `json:"first_date"`
+	FirstPrice       int64     `json:"first_price"`
+	SecondDate       time.Time `json:"second_date"`
+	SecondPrice      int64     `json:"second_price"`
+	AnnualizedReturn float64   `json:"annualized_return"`
+}
+
+func (a *Analyzer) RepeatSales(ctx context.Context, f *Filter) ([]RepeatSale, *e.ErrorData) {
+	where, args, errData := f.Where()
+	if errData != nil {
+		return nil, errData
+	}
+	query := fmt.Sprintf(`
+	SELECT city, COALESCE(district, ''), address_normalized, CAST(building_area_sqm AS DOUBLE PRECISION),
+		transaction_date, total_price, next_date, next_price
+	FROM (
+		SELECT city, district, address_normalized, building_area_sqm, transaction_date, total_price,
+			LEAD(transaction_date) OVER w AS next_date,
+			LEAD(total_price) OVER w AS next_price
+		FROM %s
+		WHERE %s AND address_floor IS NOT NULL AND total_price > 0 AND building_area_sqm > 0
+		WINDOW w AS (PARTITION BY city, address_normalized, ROUND(building_area_sqm) ORDER BY transaction_date)
+	) t
+	WHERE next_date > transaction_date
+	ORDER BY city, address_normalized, transaction_date
+	`, CleanTable("plvr_land_house_sale", f.IncludeFlagged), where)
+
+	rows, err := a.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.RepeatSales", currentPackage),
+		)
+	}
+	defer rows.Close()
+
+	items := []RepeatSale{}
+	for rows.Next() {
+		var item RepeatSale
+		if err := rows.Scan(
+			&item.City, &item.District, &item.Address, &item.AreaSqm,
+			&item.FirstDate, &item.FirstPrice, &item.SecondDate, &item.SecondPrice,
+		); err != nil {
+			return nil, e.Wrap(
+				ScanRowError,
+				err,
+				fmt.Sprintf("%s.RepeatSales", currentPackage),
+			)
+		}
+		years := item.SecondDate.Sub(item.FirstDate).Hours() / 24 / 365.25
+		item.AnnualizedReturn = math.Pow(float64(item.SecondPrice)/float64(item.FirstPrice), 1/years) - 1
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.RepeatSales", currentPackage),
+		)
+	}
+	return items, nil
+}
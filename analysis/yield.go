@@ -0,0 +1,123 @@
+package analysis
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/Walker088/gorealestate/common"
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	DefaultMinSaleSamples   = 10
+	DefaultMinRentalSamples = 10
+)
+
+type YieldOptions struct {
+	Filter
+	MinSaleSamples   int `json:"min_sale_samples"`
+	MinRentalSamples int `json:"min_rental_samples"`
+}
+
+// YieldItem is the gross rental yield of a district and building type within a season,
+// i.e., median annual rent per sqm divided by median sale price per sqm
+type YieldItem struct {
+	City                 string  `json:"city"`
+	District             string  `json:"district"`
+	BuildingType         string  `json:"building_type"`
+	Season               string  `json:"season"`
+	SaleSamples          int     `json:"sale_samples"`
+	MedianSalePerSqm     float64 `json:"median_sale_price_per_sqm"`
+	RentalSamples        int     `json:"rental_samples"`
+	MedianMonthlyRentSqm float64 `json:"median_monthly_rent_per_sqm"`
+	GrossYield           float64 `json:"gross_yield"`
+}
+
+func (a *Analyzer) RentalYield(ctx context.Context, opt *YieldOptions) ([]YieldItem, *e.ErrorData) {
+	if opt.MinSaleSamples <= 0 {
+		opt.MinSaleSamples = DefaultMinSaleSamples
+	}
+	if opt.MinRentalSamples <= 0 {
+		opt.MinRentalSamples = DefaultMinRentalSamples
+	}
+	where, args, errData := opt.Filter.Where()
+	if errData != nil {
+		return nil, errData
+	}
+	args = append(args, opt.MinSaleSamples, opt.MinRentalSamples)
+	minSale, minRental := len(args)-1, len(args)
+
+	d := a.db.Dialect()
+	query := fmt.Sprintf(`
+	WITH sale AS (
+		SELECT city, district, building_type,
+			%[6]s AS year,
+			%[7]s AS quarter,
+			CAST(COUNT(*) AS INTEGER) AS samples,
+			%[8]s AS median_per_sqm
+		FROM %[4]s
+		WHERE %[1]s AND unit_price_per_sqm > 0
+		GROUP BY 1, 2, 3, 4, 5
+		HAVING COUNT(*) >= $%[2]d
+	), rental AS (
+		SELECT city, district, building_type,
+			%[6]s AS year,
+			%[7]s AS quarter,
+			CAST(COUNT(*) AS INTEGER) AS samples,
+			%[8]s AS median_per_sqm
+		FROM %[5]s
+		WHERE %[1]s AND unit_price_per_sqm > 0
+		GROUP BY 1, 2, 3, 4, 5
+		HAVING COUNT(*) >= $%[3]d
+	)
+	SELECT s.city, s.district, s.building_type, s.year, s.quarter,
+		s.samples, s.median_per_sqm, r.samples, r.median_per_sqm,
+		r.median_per_sqm * 12 / s.median_per_sqm AS gross_yield
+	FROM sale s
+	JOIN rental r USING (city, district, building_type, year, quarter)
+	ORDER BY s.city, s.district, s.building_type, s.year, s.quarter
+	`,
+		where, minSale, minRental,
+		CleanTable("plvr_land_house_sale", opt.IncludeFlagged),
+		CleanTable("plvr_land_rental", opt.IncludeFlagged),
+		d.Year("transaction_date"), d.Quarter("transaction_date"), d.Median("unit_price_per_sqm"),
+	)
+
+	rows, err := a.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.RentalYield", currentPackage),
+		)
+	}
+	defer rows.Close()
+
+	items := []YieldItem{}
+	for rows.Next() {
+		var item YieldItem
+		var year, quarter int
+		if err := rows.Scan(
+			&item.City, &item.District, &item.BuildingType, &year, &quarter,
+			&item.SaleSamples, &item.MedianSalePerSqm, &item.RentalSamples, &item.MedianMonthlyRentSqm,
+			&item.GrossYield,
+		); err != nil {
+			return nil, e.Wrap(
+				ScanRowError,
+				err,
+				fmt.Sprintf("%s.RentalYield", currentPackage),
+			)
+		}
+		item.Season = common.ToRocSeason(year, quarter)
+		items = append(items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.RentalYield", currentPackage),
+		)
+	}
+	a.logger.Debugf("[analysis] rental yield computed on %d groups", len(items))
+	return items, nil
+}
@@ -0,0 +1,62 @@
+package analysis
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+
+	"github.com/Walker088/gorealestate/store"
+)
+
+func TestRentalYield(t *testing.T) {
+	a, s := newTestAnalyzer(t)
+	// 112S1 has the samples of both, 112S2 too few rentals, the medians are continuous
+	save(t, s, store.HouseSaleTable,
+		testSale{serial: "S1", district: "大安區", date: date(2023, 1, 5), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 100},
+		testSale{serial: "S2", district: "大安區", date: date(2023, 2, 5), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 400},
+		testSale{serial: "S3", district: "大安區", date: date(2023, 3, 5), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 200},
+		testSale{serial: "S4", district: "大安區", date: date(2023, 3, 31), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 300},
+		testSale{serial: "S5", district: "大安區", date: date(2023, 4, 1), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 300},
+		testSale{serial: "S6", district: "大安區", date: date(2023, 5, 1), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 300},
+		testSale{serial: "S7", district: "大安區", date: date(2023, 6, 1), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 300},
+		testSale{serial: "S8", district: "大安區", date: date(2023, 6, 2), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 300},
+		// no price, left out
+		testSale{serial: "S9", district: "大安區", date: date(2023, 1, 6), buildingType: "住宅大樓(11層含以上有電梯)"},
+	)
+	save(t, s, store.RentalTable,
+		testSale{serial: "R1", district: "大安區", date: date(2023, 1, 10), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 3},
+		testSale{serial: "R2", district: "大安區", date: date(2023, 2, 10), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 1},
+		testSale{serial: "R3", district: "大安區", date: date(2023, 3, 10), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 2},
+		testSale{serial: "R4", district: "大安區", date: date(2023, 4, 10), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 2},
+		testSale{serial: "R5", district: "大安區", date: date(2023, 5, 10), buildingType: "住宅大樓(11層含以上有電梯)", unitPrice: 2},
+	)
+
+	items, errData := a.RentalYield(context.Background(), &YieldOptions{
+		Filter:           Filter{City: "a", FromSeason: "112S1", ToSeason: "112S2"},
+		MinSaleSamples:   4,
+		MinRentalSamples: 3,
+	})
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	want := []YieldItem{{
+		City: "a", District: "大安區", BuildingType: "住宅大樓(11層含以上有電梯)", Season: "112S1",
+		SaleSamples: 4, MedianSalePerSqm: 250, RentalSamples: 3, MedianMonthlyRentSqm: 2, GrossYield: 2 * 12 / 250.0,
+	}}
+	if len(items) == 1 && math.Abs(items[0].GrossYield-want[0].GrossYield) < 1e-9 {
+		items[0].GrossYield = want[0].GrossYield
+	}
+	if !reflect.DeepEqual(items, want) {
+		t.Errorf("expected %+v, got %+v", want, items)
+	}
+
+	// another city has none
+	items, errData = a.RentalYield(context.Background(), &YieldOptions{Filter: Filter{City: "b"}, MinSaleSamples: 1, MinRentalSamples: 1})
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	if len(items) != 0 {
+		t.Errorf("expected no yield of city b, got %+v", items)
+	}
+}
@@ -0,0 +1,90 @@
+package api
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/config"
+	"github.com/Walker088/gorealestate/database"
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/logger"
+	"github.com/Walker088/gorealestate/metrics"
+)
+
+// NewAdmin creates the admin server of the running daemon, it should only be reachable by
+// the operators, e.g., bound to localhost, the requests other than GET need the bearer
+// token of cfg when set, pool is nil on sqlite
+func NewAdmin(cfg *config.AdminConfig, logger *zap.SugaredLogger, levels *logger.Levels, pool *database.PgPool) *Server {
+	mux := http.NewServeMux()
+	s := &Server{
+		srv: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           authorize(cfg.Token, mux),
+			ReadHeaderTimeout: defaultReadHeaderTimeout,
+		},
+		mux:    mux,
+		logger: logger,
+		pool:   pool,
+	}
+	// GET or PUT {"level":"debug"} on /admin/log/console and /admin/log/file
+	mux.Handle("/admin/log/", http.StripPrefix("/admin/log", levels.Handler()))
+	mux.Handle("/metrics", metrics.Handler())
+	if pool != nil {
+		mux.HandleFunc("/admin/database/health", s.handleDatabaseHealth)
+		mux.HandleFunc("/admin/database/stats", s.handleDatabaseStats)
+	}
+	return s
+}
+
+// authorize rejects the requests changing the state, e.g., PUT /admin/log/file, without the
+// header Authorization: Bearer <token>, every request passes when token is empty
+func authorize(token string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if token == "" || r.Method == http.MethodGet || r.Method == http.MethodHead {
+			next.ServeHTTP(w, r)
+			return
+		}
+		given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(token)) != 1 {
+			errData := e.NewErrorData(
+				UnauthorizedError,
+				fmt.Sprintf("%s %s needs the admin token", r.Method, r.URL.Path),
+				fmt.Sprintf("%s.authorize", currentPackage),
+				nil,
+				nil,
+			)
+			w.Header().Set("Content-Type", "application/json; charset=utf-8")
+			w.WriteHeader(errData.HTTPStatus())
+			json.NewEncoder(w).Encode(map[string]interface{}{"error": errData})
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// GET /admin/database/health, 503 when the database does not answer within database.health_timeout
+func (s *Server) handleDatabaseHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	if errData := s.pool.Health(r.Context()); errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	s.writeData(w, map[string]string{"status": "ok"})
+}
+
+// GET /admin/database/stats
+func (s *Server) handleDatabaseStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeData(w, s.pool.Stats())
+}
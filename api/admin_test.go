@@ -0,0 +1,32 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAuthorize(t *testing.T) {
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	for _, c := range []struct {
+		token, method, header string
+		want                  int
+	}{
+		{"", http.MethodPut, "", http.StatusOK},
+		{"secret", http.MethodGet, "", http.StatusOK},
+		{"secret", http.MethodPut, "", http.StatusUnauthorized},
+		{"secret", http.MethodPut, "Bearer wrong", http.StatusUnauthorized},
+		{"secret", http.MethodPut, "secret", http.StatusUnauthorized},
+		{"secret", http.MethodPut, "Bearer secret", http.StatusOK},
+	} {
+		r := httptest.NewRequest(c.method, "/admin/log/file", nil)
+		if c.header != "" {
+			r.Header.Set("Authorization", c.header)
+		}
+		w := httptest.NewRecorder()
+		authorize(c.token, ok).ServeHTTP(w, r)
+		if w.Code != c.want {
+			t.Errorf("expected %s with %q and the token %q to be %d, got %d", c.method, c.header, c.token, c.want, w.Code)
+		}
+	}
+}
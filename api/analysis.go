@@ -0,0 +1,165 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Walker088/gorealestate/analysis"
+	e "github.com/Walker088/gorealestate/error"
+)
+
+func parseFilter(q url.Values) analysis.Filter {
+	return analysis.Filter{
+		City:         q.Get("city"),
+		District:     q.Get("district"),
+		BuildingType: q.Get("building_type"),
+		FromSeason:   q.Get("from_season"),
+		ToSeason:     q.Get("to_season"),
+
+		IncludeFlagged: q.Get("include_flagged") == "true",
+	}
+}
+
+func parseInt(q url.Values, key string, target string) (int, *e.ErrorData) {
+	raw := q.Get(key)
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, e.NewErrorData(
+			InvalidParameterError,
+			fmt.Sprintf("query parameter %s should be an integer, got %s", key, raw),
+			fmt.Sprintf("%s.%s", currentPackage, target),
+			nil,
+			nil,
+		)
+	}
+	return v, nil
+}
+
+func parseFloat(q url.Values, key string, target string) (float64, *e.ErrorData) {
+	raw := q.Get(key)
+	if raw == "" {
+		return 0, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, e.NewErrorData(
+			InvalidParameterError,
+			fmt.Sprintf("query parameter %s should be a number, got %s", key, raw),
+			fmt.Sprintf("%s.%s", currentPackage, target),
+			nil,
+			nil,
+		)
+	}
+	return v, nil
+}
+
+// GET /api/v1/analysis/yield?city=a&district=大安區&from_season=111S1&to_season=112S4&min_sale_samples=10&min_rental_samples=10
+func (s *Server) handleYield(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	minSale, errData := parseInt(q, "min_sale_samples", "handleYield")
+	if errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	minRental, errData := parseInt(q, "min_rental_samples", "handleYield")
+	if errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+
+	items, errData := s.analyzer.RentalYield(r.Context(), &analysis.YieldOptions{
+		Filter:           parseFilter(q),
+		MinSaleSamples:   minSale,
+		MinRentalSamples: minRental,
+	})
+	if errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	s.writeData(w, items)
+}
+
+// GET /api/v1/analysis/comps?district=大安區&address=臺北市大安區忠孝東路四段&area_sqm=85&rooms=3&age_years=20&floor=5&building_type=住宅大樓&limit=10
+func (s *Server) handleComps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	query := &analysis.CompsQuery{
+		City:         q.Get("city"),
+		District:     q.Get("district"),
+		Address:      q.Get("address"),
+		BuildingType: q.Get("building_type"),
+
+		IncludeFlagged: q.Get("include_flagged") == "true",
+	}
+	var errData *e.ErrorData
+	if query.AreaSqm, errData = parseFloat(q, "area_sqm", "handleComps"); errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	if query.Months, errData = parseInt(q, "months", "handleComps"); errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	if query.Limit, errData = parseInt(q, "limit", "handleComps"); errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	if q.Has("rooms") {
+		rooms, errData := parseInt(q, "rooms", "handleComps")
+		if errData != nil {
+			s.writeError(w, errData)
+			return
+		}
+		query.Rooms = &rooms
+	}
+	if q.Has("floor") {
+		floor, errData := parseInt(q, "floor", "handleComps")
+		if errData != nil {
+			s.writeError(w, errData)
+			return
+		}
+		query.Floor = &floor
+	}
+	if q.Has("age_years") {
+		age, errData := parseFloat(q, "age_years", "handleComps")
+		if errData != nil {
+			s.writeError(w, errData)
+			return
+		}
+		query.AgeYears = &age
+	}
+
+	res, errData := s.analyzer.Comps(r.Context(), query)
+	if errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	s.writeData(w, res)
+}
+
+// GET /api/v1/analysis/repeat-sales?city=a&district=大安區&from_season=101S1
+func (s *Server) handleRepeatSales(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	filter := parseFilter(r.URL.Query())
+	items, errData := s.analyzer.RepeatSales(r.Context(), &filter)
+	if errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	s.writeData(w, items)
+}
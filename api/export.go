@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/Walker088/gorealestate/export"
+)
+
+// GET /api/v1/export/geojson?table=house_sale&city=a&from_season=111S1&level=district
+func (s *Server) handleGeoJSON(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	opt := &export.Options{
+		Filter: parseFilter(q),
+		Table:  q.Get("table"),
+	}
+	if errData := opt.Validate(); errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/geo+json")
+	write := s.exporter.GeoJSON
+	if q.Get("level") == "district" {
+		write = s.exporter.DistrictGeoJSON
+	}
+	// the status is already sent once the features are streamed, errors can only be logged
+	if _, errData := write(r.Context(), w, opt); errData != nil {
+		s.logger.Errorf("[api] geojson export failed: %s", errData.ToString())
+	}
+}
+
+var contentTypes = map[string]string{
+	export.FormatCSV:     "text/csv; charset=utf-8",
+	export.FormatJSONL:   "application/x-ndjson",
+	export.FormatParquet: "application/vnd.apache.parquet",
+}
+
+// GET /api/v1/export/transactions?format=parquet&table=rental&city=a&from_season=111S1
+func (s *Server) handleExportTransactions(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	q := r.URL.Query()
+	opt := &export.FileOptions{
+		Options: export.Options{
+			Filter: parseFilter(q),
+			Table:  q.Get("table"),
+		},
+		Format: q.Get("format"),
+	}
+	if opt.Format == "" {
+		opt.Format = export.FormatCSV
+	}
+	if errData := opt.Validate(); errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentTypes[opt.Format])
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, opt.Table, opt.Format))
+	// the status is already sent once the records are streamed, errors can only be logged
+	if _, errData := s.exporter.Write(r.Context(), w, opt); errData != nil {
+		s.logger.Errorf("[api] %s export failed: %s", opt.Format, errData.ToString())
+	}
+}
@@ -0,0 +1,34 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// GET /api/v1/reference/cities
+func (s *Server) handleCities(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeData(w, s.ref.Cities())
+}
+
+// GET /api/v1/reference/cities/a
+func (s *Server) handleCity(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	code := strings.TrimPrefix(r.URL.Path, "/api/v1/reference/cities/")
+	if code == "" {
+		s.handleCities(w, r)
+		return
+	}
+	city, errData := s.ref.City(code)
+	if errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	s.writeData(w, city)
+}
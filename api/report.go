@@ -0,0 +1,44 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+)
+
+// GET /api/v1/reports/runs?limit=20
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	limit, errData := parseInt(r.URL.Query(), "limit", "handleRuns")
+	if errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	runs, errData := s.runs.Runs(r.Context(), limit)
+	if errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	s.writeData(w, runs)
+}
+
+// GET /api/v1/reports/runs/20240101T030000-1a2b3c4d
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		w.WriteHeader(http.StatusMethodNotAllowed)
+		return
+	}
+	runID := strings.TrimPrefix(r.URL.Path, "/api/v1/reports/runs/")
+	if runID == "" {
+		s.handleRuns(w, r)
+		return
+	}
+	run, errData := s.runs.Run(r.Context(), runID)
+	if errData != nil {
+		s.writeError(w, errData)
+		return
+	}
+	s.writeData(w, run)
+}
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/analysis"
+	"github.com/Walker088/gorealestate/config"
+	"github.com/Walker088/gorealestate/database"
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/export"
+	"github.com/Walker088/gorealestate/metrics"
+	"github.com/Walker088/gorealestate/reference"
+	"github.com/Walker088/gorealestate/store"
+)
+
+const (
+	currentPackage = "github.com/Walker088/gorealestate/api"
+
+	InvalidParameterError = "AP00001"
+	ServerStartError      = "AP00002"
+	ServerShutdownError   = "AP00003"
+	UnauthorizedError     = "AP00004"
+
+	defaultReadHeaderTimeout = 10 * time.Second
+)
+
+type Server struct {
+	srv      *http.Server
+	mux      *http.ServeMux
+	logger   *zap.SugaredLogger
+	analyzer *analysis.Analyzer
+	exporter *export.Exporter
+	runs     store.RunStore
+	ref      *reference.Reference
+	pool     *database.PgPool // admin only
+}
+
+func New(cfg *config.ServerConfig, logger *zap.SugaredLogger, analyzer *analysis.Analyzer, exporter *export.Exporter, runs store.RunStore, ref *reference.Reference) *Server {
+	mux := http.NewServeMux()
+	s := &Server{
+		srv: &http.Server{
+			Addr:              cfg.Addr,
+			Handler:           instrument(mux),
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+		},
+		mux:      mux,
+		logger:   logger,
+		analyzer: analyzer,
+		exporter: exporter,
+		runs:     runs,
+		ref:      ref,
+	}
+	s.routes()
+	return s
+}
+
+func (s *Server) routes() {
+	s.mux.HandleFunc("/api/v1/analysis/yield", s.handleYield)
+	s.mux.HandleFunc("/api/v1/analysis/comps", s.handleComps)
+	s.mux.HandleFunc("/api/v1/analysis/repeat-sales", s.handleRepeatSales)
+	s.mux.HandleFunc("/api/v1/export/geojson", s.handleGeoJSON)
+	s.mux.HandleFunc("/api/v1/export/transactions", s.handleExportTransactions)
+	s.mux.HandleFunc("/api/v1/reports/runs", s.handleRuns)
+	s.mux.HandleFunc("/api/v1/reports/runs/", s.handleRun)
+	s.mux.HandleFunc("/api/v1/reference/cities", s.handleCities)
+	s.mux.HandleFunc("/api/v1/reference/cities/", s.handleCity)
+}
+
+// instrument records the latency of the requests by the pattern of their route, i.e., the ids
+// of the paths do not add series
+func instrument(mux *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		started := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		mux.ServeHTTP(rec, r)
+		_, route := mux.Handler(r)
+		if route == "" {
+			route = "unmatched"
+		}
+		metrics.APIRequestDuration.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Observe(time.Since(started).Seconds())
+	})
+}
+
+// statusRecorder keeps the status written by the handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// Start blocks until the server is shut down
+func (s *Server) Start() *e.ErrorData {
+	s.logger.Infof("[api] listening on %s", s.srv.Addr)
+	if err := s.srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		return e.Wrap(
+			ServerStartError,
+			err,
+			fmt.Sprintf("%s.Start", currentPackage),
+		)
+	}
+	return nil
+}
+
+func (s *Server) Stop(ctx context.Context) *e.ErrorData {
+	if err := s.srv.Shutdown(ctx); err != nil {
+		return e.Wrap(
+			ServerShutdownError,
+			err,
+			fmt.Sprintf("%s.Stop", currentPackage),
+		)
+	}
+	s.logger.Info("[api] server stopped")
+	return nil
+}
+
+func (s *Server) writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		s.logger.Errorf("[api] failed to encode response: %s", err.Error())
+	}
+}
+
+func (s *Server) writeData(w http.ResponseWriter, data interface{}) {
+	s.writeJSON(w, http.StatusOK, map[string]interface{}{"data": data})
+}
+
+// writeError responds with the http status of the code of errData
+func (s *Server) writeError(w http.ResponseWriter, errData *e.ErrorData) {
+	s.logger.Debugf("[api] request failed: %s", errData.ToString())
+	s.writeJSON(w, errData.HTTPStatus(), map[string]interface{}{"error": errData})
+}
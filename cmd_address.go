@@ -0,0 +1,39 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Walker088/gorealestate/address"
+	"github.com/Walker088/gorealestate/store"
+)
+
+func runAddress(app *App, args []string) {
+	fs := flag.NewFlagSet("address", flag.ExitOnError)
+	raw := fs.String("parse", "", "address to parse, e.g., 臺北市大安區忠孝東路四段31~60號")
+	district := fs.String("district", "", "district hint of the address to parse")
+	fs.Parse(args)
+
+	if *raw != "" {
+		printJSON(address.Parse(*raw, *district))
+		return
+	}
+
+	pool, err := store.PgPool(app.database(app.logger), "the address backfill", "main.runAddress")
+	if err != nil {
+		app.exit(err)
+	}
+	summaries, err := address.Backfill(context.Background(), pool, app.logger)
+	if err != nil {
+		app.exit(err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tPARSED")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%d\n", s.Table, s.Parsed)
+	}
+	w.Flush()
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Walker088/gorealestate/analysis"
+	"github.com/Walker088/gorealestate/common"
+)
+
+func filterFlags(fs *flag.FlagSet) *analysis.Filter {
+	f := &analysis.Filter{}
+	fs.StringVar(&f.City, "city", "", "city code, e.g., a for Taipei City")
+	fs.StringVar(&f.District, "district", "", "district name, e.g., 大安區")
+	fs.StringVar(&f.BuildingType, "building-type", "", "building type prefix, e.g., 住宅大樓")
+	fs.StringVar(&f.FromSeason, "from", "", "first season, e.g., 111S1")
+	fs.StringVar(&f.ToSeason, "to", "", "last season, e.g., 112S4")
+	fs.BoolVar(&f.IncludeFlagged, "include-flagged", false, "include the flagged transactions")
+	return f
+}
+
+func printJSON(v interface{}) {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	enc.Encode(v)
+}
+
+func runYield(app *App, args []string) {
+	fs := flag.NewFlagSet("yield", flag.ExitOnError)
+	filter := filterFlags(fs)
+	minSale := fs.Int("min-sales", analysis.DefaultMinSaleSamples, "minimum number of sale transactions per group")
+	minRental := fs.Int("min-rentals", analysis.DefaultMinRentalSamples, "minimum number of rental transactions per group")
+	format := fs.String("format", "table", "output format, table or json")
+	fs.Parse(args)
+
+	items, err := analysis.New(app.database(app.logger), app.logger).RentalYield(context.Background(), &analysis.YieldOptions{
+		Filter:           *filter,
+		MinSaleSamples:   *minSale,
+		MinRentalSamples: *minRental,
+	})
+	if err != nil {
+		app.exit(err)
+	}
+	if *format == "json" {
+		printJSON(items)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "CITY\tDISTRICT\tBUILDING TYPE\tSEASON\tSALES\tSALE/SQM\tRENTALS\tRENT/SQM\tGROSS YIELD")
+	for _, item := range items {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%.0f\t%d\t%.0f\t%.2f%%\n",
+			item.City, item.District, item.BuildingType, item.Season,
+			item.SaleSamples, item.MedianSalePerSqm, item.RentalSamples, item.MedianMonthlyRentSqm,
+			item.GrossYield*100,
+		)
+	}
+	w.Flush()
+}
+
+func runComps(app *App, args []string) {
+	fs := flag.NewFlagSet("comps", flag.ExitOnError)
+	q := &analysis.CompsQuery{}
+	fs.StringVar(&q.City, "city", "", "city code, e.g., a for Taipei City")
+	fs.StringVar(&q.District, "district", "", "district name, e.g., 大安區")
+	fs.StringVar(&q.Address, "address", "", "address of the target property")
+	fs.StringVar(&q.BuildingType, "building-type", "", "building type prefix, e.g., 住宅大樓")
+	fs.Float64Var(&q.AreaSqm, "area", 0, "building area in square meters")
+	rooms := fs.Int("rooms", -1, "number of rooms, negative to ignore")
+	age := fs.Float64("age", -1, "building age in years, negative to ignore")
+	floor := fs.String("floor", "", "floor, e.g., 5 or 五層")
+	fs.IntVar(&q.Months, "months", analysis.DefaultCompsMonths, "look back period in months")
+	fs.IntVar(&q.Limit, "limit", analysis.DefaultCompsLimit, "number of comparables")
+	fs.BoolVar(&q.IncludeFlagged, "include-flagged", false, "include the flagged transactions")
+	format := fs.String("format", "table", "output format, table or json")
+	fs.Parse(args)
+
+	if *rooms >= 0 {
+		q.Rooms = rooms
+	}
+	if *age >= 0 {
+		q.AgeYears = age
+	}
+	if f, ok := common.ParseFloor(*floor); ok {
+		q.Floor = &f
+	}
+
+	res, err := analysis.New(app.database(app.logger), app.logger).Comps(context.Background(), q)
+	if err != nil {
+		app.exit(err)
+	}
+	if *format == "json" {
+		printJSON(res)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SIMILARITY\tDATE\tDISTRICT\tADDRESS\tBUILDING TYPE\tAREA\tROOMS\tTOTAL PRICE\tPRICE/SQM")
+	for _, c := range res.Comparables {
+		fmt.Fprintf(w, "%.3f\t%s\t%s\t%s\t%s\t%.2f\t%d\t%d\t%d\n",
+			c.Similarity, c.TransactionDate.Format(time.DateOnly), c.District, c.Address, c.BuildingType,
+			c.AreaSqm, c.Rooms, c.TotalPrice, c.UnitPricePerSqm,
+		)
+	}
+	w.Flush()
+	fmt.Printf("\nestimated value: %.0f (%.0f ~ %.0f)\n", res.Estimated, res.EstimatedLow, res.EstimatedHigh)
+}
+
+func runFlag(app *App, args []string) {
+	fs := flag.NewFlagSet("flag", flag.ExitOnError)
+	opt := &analysis.FlagOptions{}
+	fs.Float64Var(&opt.ZScoreThreshold, "z", analysis.DefaultZScoreThreshold, "unit price z-score threshold of the outliers")
+	fs.IntVar(&opt.MinGroupSamples, "min-samples", analysis.DefaultMinGroupSamples, "minimum transactions of a district and season to detect outliers")
+	seasons := fs.String("seasons", "", "comma separated seasons of the transaction dates to flag, e.g., 112S1,112S2, all when empty")
+	fs.BoolVar(&opt.Undated, "undated", false, "flag the transactions without a date, along with the seasons")
+	fs.Parse(args)
+	if *seasons != "" {
+		opt.Seasons = strings.Split(*seasons, ",")
+	}
+
+	summaries, err := analysis.New(app.database(app.logger), app.logger).FlagTransactions(context.Background(), opt)
+	if err != nil {
+		app.exit(err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tFLAG\tROWS")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", s.Table, s.Flag, s.Rows)
+	}
+	w.Flush()
+}
+
+func runRepeatSales(app *App, args []string) {
+	fs := flag.NewFlagSet("repeat-sales", flag.ExitOnError)
+	filter := filterFlags(fs)
+	format := fs.String("format", "table", "output format, table or json")
+	fs.Parse(args)
+
+	items, err := analysis.New(app.database(app.logger), app.logger).RepeatSales(context.Background(), filter)
+	if err != nil {
+		app.exit(err)
+	}
+	if *format == "json" {
+		printJSON(items)
+		return
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ADDRESS\tAREA\tFIRST DATE\tFIRST PRICE\tSECOND DATE\tSECOND PRICE\tANNUALIZED RETURN")
+	for _, item := range items {
+		fmt.Fprintf(w, "%s\t%.2f\t%s\t%d\t%s\t%d\t%.2f%%\n",
+			item.Address, item.AreaSqm,
+			item.FirstDate.Format(time.DateOnly), item.FirstPrice,
+			item.SecondDate.Format(time.DateOnly), item.SecondPrice,
+			item.AnnualizedReturn*100,
+		)
+	}
+	w.Flush()
+}
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+// setFlags collects the repeated -set key=value flags
+type setFlags []string
+
+func (s *setFlags) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *setFlags) Set(value string) error {
+	if !strings.Contains(value, "=") {
+		return fmt.Errorf("expect key=value, e.g., server.addr=:9090")
+	}
+	*s = append(*s, value)
+	return nil
+}
+
+// exitConfigError prints the invalid keys of err and exits with status 1
+func exitConfigError(err *e.ErrorData) {
+	fmt.Fprintln(os.Stderr, err.Message)
+	for _, d := range err.Details {
+		fmt.Fprintf(os.Stderr, "  %s: %s\n", d.Target, d.Message)
+	}
+	os.Exit(1)
+}
+
+func runConfig(app *App, args []string) {
+	action := "print"
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		action, args = args[0], args[1:]
+	}
+	fs := flag.NewFlagSet("config", flag.ExitOnError)
+	format := fs.String("format", "table", "output format of print, table or json")
+	var sets setFlags
+	fs.Var(&sets, "set", "override a key, e.g., -set crawler.concurrency=4, can be repeated")
+	fs.Parse(args)
+
+	for _, kv := range sets {
+		key, value, _ := strings.Cut(kv, "=")
+		if err := app.config.Override(key, value); err != nil {
+			exitConfigError(err)
+		}
+	}
+
+	files := strings.Join(app.config.Files(), ", ")
+	if files == "" {
+		files = "none, defaults and env vars only"
+	}
+	switch action {
+	case "validate":
+		// the config is validated on load already, the files it refers to are checked again
+		if err := app.config.Validate(); err != nil {
+			exitConfigError(err)
+		}
+		fmt.Printf("config is valid, profile: %s, files: %s\n", app.config.Profile(), files)
+	case "print":
+		if *format == "json" {
+			printJSON(app.config.Settings())
+			return
+		}
+		fmt.Printf("profile: %s\nfiles: %s\n\n", app.config.Profile(), files)
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "KEY\tVALUE\tSOURCE")
+		for _, s := range app.config.Settings() {
+			fmt.Fprintf(w, "%s\t%v\t%s\n", s.Key, s.Value, s.Source)
+		}
+		w.Flush()
+	default:
+		fmt.Fprintf(os.Stderr, "unknown config action %s, expect print or validate\n", action)
+		os.Exit(2)
+	}
+}
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"sync"
+	"time"
+
+	"github.com/Walker088/gorealestate/analysis"
+	"github.com/Walker088/gorealestate/config"
+	"github.com/Walker088/gorealestate/crawler/plvr"
+	"github.com/Walker088/gorealestate/geocode"
+	ghttp "github.com/Walker088/gorealestate/http"
+	"github.com/Walker088/gorealestate/logger"
+	"github.com/Walker088/gorealestate/reference"
+	"github.com/Walker088/gorealestate/report"
+	"github.com/Walker088/gorealestate/store"
+)
+
+func runCrawl(app *App, args []string) {
+	fs := flag.NewFlagSet("crawl", flag.ExitOnError)
+	daemon := fs.Bool("daemon", false, "keep running, crawl every crawler.interval and reload the config file on changes")
+	fs.Parse(args)
+
+	deadlineChannel := make(chan os.Signal, 1)
+	signal.Notify(deadlineChannel, os.Interrupt)
+
+	l := app.logger
+	l.Info("welcome to gorealestate")
+
+	client := ghttp.New(ghttp.NewOptions(app.config.GetHTTPConfig()))
+	if !*daemon {
+		// the metrics of a long backfill are served by the admin server too
+		stop := make(chan struct{})
+		defer close(stop)
+		app.admin(stop)
+		crawlOnce(app, client, deadlineChannel, func(*plvr.PlvrCrawler) {})
+		return
+	}
+
+	var (
+		mu          sync.Mutex
+		current     *plvr.PlvrCrawler
+		rescheduled = make(chan struct{}, 1)
+		stop        = make(chan struct{})
+	)
+	defer close(stop)
+	app.config.OnChange(func(c *config.AppConfig) {
+		client.SetOptions(ghttp.NewOptions(c.GetHTTPConfig()))
+		mu.Lock()
+		if current != nil {
+			current.Reload(c.GetCrawlerConfig())
+		}
+		mu.Unlock()
+		select {
+		case rescheduled <- struct{}{}:
+		default:
+		}
+	})
+	app.daemon(stop)
+
+	for {
+		started := time.Now()
+		interrupted := crawlOnce(app, client, deadlineChannel, func(c *plvr.PlvrCrawler) {
+			mu.Lock()
+			current = c
+			mu.Unlock()
+		})
+		mu.Lock()
+		current = nil
+		mu.Unlock()
+		if interrupted {
+			return
+		}
+
+		// the interval may be changed by a reload while waiting
+	wait:
+		for {
+			next := started.Add(app.config.GetCrawlerConfig().Interval)
+			l.Infof("[main] next crawl at %s", next.Format(time.DateTime))
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-deadlineChannel:
+				timer.Stop()
+				l.Info("interrupt signal received")
+				return
+			case <-rescheduled:
+				timer.Stop()
+			case <-timer.C:
+				break wait
+			}
+		}
+	}
+}
+
+// crawlOnce downloads and imports the configured seasons, then flags the transactions and
+// geocodes them on postgres, true is returned when interrupted, every log line of the run carries its id
+// and the report of the run is saved in the database
+func crawlOnce(app *App, client *ghttp.Client, deadlineChannel chan os.Signal, onStart func(*plvr.PlvrCrawler)) bool {
+	recorder := report.NewRecorder(logger.NewRunID())
+	l := app.logger.With(logger.FieldRunID, recorder.RunID())
+	st := app.database(l)
+	// the reference is loaded before the run starts, a run is never left running
+	ref, err := reference.Load(context.Background(), st)
+	if err != nil {
+		l.Error(err.ToString())
+		return false
+	}
+	if err := st.StartRun(context.Background(), recorder); err != nil {
+		l.Error(err.ToString())
+	}
+	imported := 0
+	save := func(status string) {
+		run := recorder.Run(status)
+		l.Infow("run "+status,
+			"imported", imported, "errors", run.ErrorCount,
+			"rows_read", run.Rows.Read, "rows_inserted", run.Rows.Inserted, "rows_updated", run.Rows.Updated,
+			"rows_skipped", run.Rows.Skipped, "rows_rejected", run.Rows.Rejected,
+		)
+		if err := st.SaveRun(context.Background(), run); err != nil {
+			l.Error(err.ToString())
+		}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	crawler := plvr.New(ctx, cancel, app.rootDir, app.config.GetCrawlerConfig(), client, recorder, ref, l, st, st)
+	onStart(crawler)
+	go crawler.Start()
+	for {
+		select {
+		case <-deadlineChannel:
+			l.Info("interrupt signal received")
+			crawler.Stop()
+			save(report.StatusInterrupted)
+			return true
+		case <-ctx.Done():
+			save(report.StatusFinished)
+			// the z-scores are computed per season, only the seasons of the inserted rows change
+			seasons, undated := crawler.Touched()
+			if len(seasons) > 0 || undated {
+				opt := &analysis.FlagOptions{Seasons: seasons, Undated: undated}
+				if _, err := analysis.New(st, l).FlagTransactions(context.Background(), opt); err != nil {
+					l.Error(err.ToString())
+				}
+			}
+			pool, err := store.PgPool(st, "geocoding", "main.crawlOnce")
+			if err != nil {
+				l.Infof("[crawl] transactions not geocoded: %s", err.Message)
+				return false
+			}
+			if _, err := geocode.New(pool, l).Geocode(context.Background(), false); err != nil {
+				l.Error(err.ToString())
+			}
+			return false
+		case <-crawler.ErrorsCh:
+			// logged and recorded by the crawler along with the season, file and row
+		case <-crawler.ResultsCh:
+			imported++
+		}
+	}
+}
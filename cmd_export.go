@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/export"
+)
+
+func runExport(app *App, args []string) {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	opt := &export.FileOptions{Options: export.Options{Filter: *filterFlags(fs)}}
+	fs.StringVar(&opt.Table, "table", "house_sale", "transactions to export, house_sale, new_house or rental")
+	format := fs.String("format", "geojson", "output format, geojson, csv, jsonl or parquet")
+	districts := fs.Bool("districts", false, "export a polygon per district with the price statistics instead of the transactions")
+	partition := fs.String("partition", "", "comma separated partitions of the csv, jsonl or parquet export, city and/or season, -o is then a directory")
+	out := fs.String("o", "", "output file, required since the logs are written to stdout")
+	fs.Parse(args)
+
+	if *out == "" {
+		fmt.Fprintln(os.Stderr, "output file is required, e.g., -o transactions.geojson")
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	x := export.New(app.database(app.logger), app.logger)
+	ctx := context.Background()
+	if *format != "geojson" {
+		opt.Format = *format
+		if *partition != "" {
+			opt.PartitionBy = strings.Split(*partition, ",")
+		}
+		n, err := x.Export(ctx, *out, opt)
+		if err != nil {
+			app.exit(err)
+		}
+		app.logger.Infof("[export] %d transactions exported to %s", n, *out)
+		return
+	}
+
+	f, err := os.Create(*out)
+	if err != nil {
+		app.exit(e.Wrap(export.WriteError, err, "main.runExport"))
+	}
+	defer f.Close()
+
+	write := x.GeoJSON
+	if *districts {
+		write = x.DistrictGeoJSON
+	}
+	n, errData := write(ctx, f, &opt.Options)
+	if errData != nil {
+		f.Close()
+		app.exit(errData)
+	}
+	app.logger.Infof("[export] %d features exported to %s", n, *out)
+}
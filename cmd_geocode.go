@@ -0,0 +1,45 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/Walker088/gorealestate/geocode"
+	"github.com/Walker088/gorealestate/store"
+)
+
+func runGeocode(app *App, args []string) {
+	fs := flag.NewFlagSet("geocode", flag.ExitOnError)
+	load := fs.String("load", "", "reference file to load, csv or geojson with city, district, village, road and section")
+	source := fs.String("source", "", "name of the reference source, defaults to the file name")
+	reset := fs.Bool("reset", false, "clear the coordinates geocoded before and match them again")
+	fs.Parse(args)
+
+	pool, err := store.PgPool(app.database(app.logger), "geocoding", "main.runGeocode")
+	if err != nil {
+		app.exit(err)
+	}
+	g := geocode.New(pool, app.logger)
+	if *load != "" {
+		if *source == "" {
+			*source = *load
+		}
+		if _, err := g.Load(context.Background(), *load, *source); err != nil {
+			app.exit(err)
+		}
+	}
+
+	summaries, err := g.Geocode(context.Background(), *reset)
+	if err != nil {
+		app.exit(err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "TABLE\tLEVEL\tROWS")
+	for _, s := range summaries {
+		fmt.Fprintf(w, "%s\t%s\t%d\n", s.Table, s.Level, s.Rows)
+	}
+	w.Flush()
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/migrations"
+)
+
+const migrateUsage = `Usage: migrate [status|up|down [N]|steps N|goto VERSION|force VERSION] [-dry-run] [-format json]
+
+  status         current version, dirty state, applied and pending migrations (default)
+  up             apply the pending migrations
+  down [N]       revert the last N migrations, 1 by default
+  steps N        apply the next N migrations, or revert the last -N ones
+  goto VERSION   migrate up or down to VERSION, 0 reverts every migration
+  force VERSION  set VERSION without running any migration, clears the dirty state
+
+Negative numbers are arguments, not flags, e.g., migrate steps -2 -dry-run.
+`
+
+func runMigrate(app *App, args []string) {
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dryRun := fs.Bool("dry-run", false, "print the sql of the migrations to be run instead of running them")
+	format := fs.String("format", "table", "output format of status, table or json")
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, migrateUsage)
+		fs.PrintDefaults()
+	}
+	positional := splitArgs(fs, args)
+	sub := "status"
+	if len(positional) > 0 {
+		sub = positional[0]
+	}
+	// n is the number of steps or the version of the subcommand, the arguments are checked
+	// before connecting
+	n, required := 0, map[string]bool{"steps": true, "goto": true, "force": true}
+	switch {
+	case len(positional) > 1:
+		var err error
+		if n, err = strconv.Atoi(positional[1]); err != nil || sub == "goto" && n < 0 {
+			fmt.Fprintf(os.Stderr, "%s expects an integer, got %s\n", sub, positional[1])
+			os.Exit(2)
+		}
+	case sub == "down":
+		n = 1
+	case required[sub]:
+		fs.Usage()
+		os.Exit(2)
+	}
+	switch sub {
+	case "status", "up", "down", "steps", "goto", "force":
+	default:
+		fs.Usage()
+		os.Exit(2)
+	}
+
+	sm, errData := migrations.New(app.config.GetPgConfig(), app.logger)
+	if errData != nil {
+		app.exit(errData)
+	}
+	defer sm.Stop()
+
+	var target uint
+	switch sub {
+	case "status":
+		errData = printStatus(sm, *format)
+	case "force":
+		if *dryRun {
+			fmt.Printf("-- would force version %d\n", n)
+			return
+		}
+		errData = sm.Force(n)
+	case "up":
+		target, errData = sm.Latest()
+		if errData == nil && !*dryRun {
+			errData = sm.Migrate()
+		}
+	case "down", "steps":
+		if sub == "down" {
+			n = -n
+		}
+		target, errData = sm.StepsTarget(n)
+		if errData == nil && !*dryRun {
+			errData = sm.Steps(n)
+		}
+	case "goto":
+		target = uint(n)
+		if !*dryRun {
+			errData = sm.Goto(target)
+		}
+	}
+	if errData == nil && *dryRun && sub != "status" {
+		errData = printPlan(sm, target)
+	}
+	if errData != nil {
+		sm.Stop()
+		app.exit(errData)
+	}
+}
+
+// splitArgs separates the flags from the positional arguments, the flags are accepted before
+// and after them, and the integers are positional, e.g., the -2 of steps -2
+func splitArgs(fs *flag.FlagSet, args []string) []string {
+	var positional []string
+	for len(args) > 0 {
+		i := 0
+		for i < len(args) && !isInteger(args[i]) {
+			i++
+		}
+		for rest := args[:i]; len(rest) > 0; rest = fs.Args()[1:] {
+			fs.Parse(rest)
+			if fs.NArg() == 0 {
+				break
+			}
+			positional = append(positional, fs.Arg(0))
+		}
+		if i < len(args) {
+			positional = append(positional, args[i])
+			i++
+		}
+		args = args[i:]
+	}
+	return positional
+}
+
+func isInteger(arg string) bool {
+	_, err := strconv.Atoi(arg)
+	return err == nil
+}
+
+func printStatus(sm *migrations.SchemaManager, format string) *e.ErrorData {
+	status, errData := sm.Status()
+	if errData != nil {
+		return errData
+	}
+	if format == "json" {
+		printJSON(status)
+		return nil
+	}
+	fmt.Printf("version %d of %d, dirty %t\n", status.Version, status.Latest, status.Dirty)
+	if status.Dirty {
+		fmt.Printf("version %d failed halfway, fix the schema by hand then run migrate force <version>\n", status.Version)
+	}
+	fmt.Println()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE")
+	for _, m := range status.Applied {
+		state := "applied"
+		if status.Dirty && m.Version == status.Version {
+			state = "dirty"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\n", m.Version, m.Name, state)
+	}
+	for _, m := range status.Pending {
+		fmt.Fprintf(w, "%d\t%s\tpending\n", m.Version, m.Name)
+	}
+	w.Flush()
+	return nil
+}
+
+// printPlan prints the sql run to reach target without running it
+func printPlan(sm *migrations.SchemaManager, target uint) *e.ErrorData {
+	plan, errData := sm.Plan(target)
+	if errData != nil {
+		return errData
+	}
+	if len(plan) == 0 {
+		fmt.Println("-- no change")
+	}
+	for _, m := range plan {
+		fmt.Printf("-- %d_%s.%s.sql\n%s\n", m.Version, m.Name, m.Direction, m.SQL)
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Walker088/gorealestate/report"
+)
+
+func runReport(app *App, args []string) {
+	fs := flag.NewFlagSet("report", flag.ExitOnError)
+	runID := fs.String("run", "", "run id, lists the latest runs when empty")
+	limit := fs.Int("limit", report.DefaultRunsLimit, "number of runs to list")
+	format := fs.String("format", "table", "output format, table or json")
+	fs.Parse(args)
+
+	runStore := app.database(app.logger)
+	if *runID == "" {
+		runs, err := runStore.Runs(context.Background(), *limit)
+		if err != nil {
+			app.exit(err)
+		}
+		if *format == "json" {
+			printJSON(runs)
+			return
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(w, "RUN\tSTATUS\tSTARTED\tDURATION\tREAD\tINSERTED\tUPDATED\tSKIPPED\tREJECTED\tERRORS")
+		for _, run := range runs {
+			duration := "-"
+			if run.FinishedTime != nil {
+				duration = run.FinishedTime.Sub(run.StartedTime).Round(time.Second).String()
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%d\t%d\t%d\t%d\t%d\t%d\n",
+				run.RunID, run.Status, run.StartedTime.Local().Format(time.DateTime), duration,
+				run.Rows.Read, run.Rows.Inserted, run.Rows.Updated, run.Rows.Skipped, run.Rows.Rejected, run.ErrorCount,
+			)
+		}
+		w.Flush()
+		return
+	}
+
+	run, err := runStore.Run(context.Background(), *runID)
+	if err != nil {
+		app.exit(err)
+	}
+	if *format == "json" {
+		printJSON(run)
+		return
+	}
+	fmt.Printf("run %s %s, started %s\n\n", run.RunID, run.Status, run.StartedTime.Local().Format(time.DateTime))
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEASON\tFILE\tREAD\tINSERTED\tUPDATED\tSKIPPED\tREJECTED")
+	for _, f := range run.Files {
+		fmt.Fprintf(w, "%s\t%s\t%d\t%d\t%d\t%d\t%d\n", f.Season, f.File, f.Rows.Read, f.Rows.Inserted, f.Rows.Updated, f.Rows.Skipped, f.Rows.Rejected)
+	}
+	w.Flush()
+	if len(run.Errors) == 0 {
+		return
+	}
+	fmt.Println()
+	w = tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "SEASON\tFILE\tCODE\tCOUNT\tSAMPLE")
+	for _, er := range run.Errors {
+		sample := ""
+		if len(er.Samples) > 0 {
+			sample = er.Samples[0].Message
+			if er.Samples[0].Row > 0 {
+				sample = fmt.Sprintf("row %d: %s", er.Samples[0].Row, sample)
+			}
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%d\t%s\n", er.Season, er.File, er.Code, er.Count, sample)
+	}
+	w.Flush()
+}
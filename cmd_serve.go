@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+
+	"github.com/Walker088/gorealestate/analysis"
+	"github.com/Walker088/gorealestate/api"
+	"github.com/Walker088/gorealestate/export"
+	"github.com/Walker088/gorealestate/reference"
+)
+
+func runServe(app *App, args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	addr := fs.String("addr", app.config.GetServerConfig().Addr, "address the api server listens on, overrides server.addr")
+	fs.Parse(args)
+	override := false
+	fs.Visit(func(f *flag.Flag) { override = override || f.Name == "addr" })
+	if override {
+		if err := app.config.Override("server.addr", *addr); err != nil {
+			app.logger.Error(err.ToString())
+			return
+		}
+	}
+	cfg := app.config.GetServerConfig()
+
+	deadlineChannel := make(chan os.Signal, 1)
+	signal.Notify(deadlineChannel, os.Interrupt)
+
+	db := app.database(app.logger)
+	ref, errData := reference.Load(context.Background(), db)
+	if errData != nil {
+		app.logger.Error(errData.ToString())
+		return
+	}
+
+	stop := make(chan struct{})
+	defer close(stop)
+	app.daemon(stop)
+
+	server := api.New(
+		cfg,
+		app.logger,
+		analysis.New(db, app.logger),
+		export.New(db, app.logger),
+		db,
+		ref,
+	)
+	go func() {
+		if err := server.Start(); err != nil {
+			app.logger.Error(err.ToString())
+			deadlineChannel <- os.Interrupt
+		}
+	}()
+
+	<-deadlineChannel
+	app.logger.Info("interrupt signal received")
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
+	defer cancel()
+	if err := server.Stop(ctx); err != nil {
+		app.logger.Error(err.ToString())
+	}
+}
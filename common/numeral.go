@@ -0,0 +1,80 @@
+package common
+
+import (
+	"strconv"
+	"strings"
+)
+
+var chineseDigits = map[rune]int{
+	'零': 0, '〇': 0, '一': 1, '二': 2, '兩': 2, '三': 3, '四': 4,
+	'五': 5, '六': 6, '七': 7, '八': 8, '九': 9,
+}
+
+// Input: chinese numeral below ten thousand, e.g., 二十一 = 21, 一百零五 = 105
+func ChineseNumeralToInt(s string) (int, bool) {
+	if s == "" {
+		return 0, false
+	}
+	if v, err := strconv.Atoi(ToHalfWidth(s)); err == nil {
+		return v, true
+	}
+	units := map[rune]int{'十': 10, '百': 100, '千': 1000}
+	total, digit := 0, -1
+	for _, r := range s {
+		if d, ok := chineseDigits[r]; ok {
+			digit = d
+			continue
+		}
+		unit, ok := units[r]
+		if !ok {
+			return 0, false
+		}
+		if digit == -1 {
+			digit = 1 // 十一 = 11
+		}
+		total += digit * unit
+		digit = -1
+	}
+	if digit > 0 {
+		total += digit
+	}
+	return total, true
+}
+
+// Input: plvr floor, e.g., 十一層 = 11, 地下一層 = -1, 三層,四層 = 3 (the first one is taken)
+func ParseFloor(s string) (int, bool) {
+	s = strings.TrimSpace(s)
+	if i := strings.IndexAny(s, ",，"); i >= 0 {
+		s = s[:i]
+	}
+	sign := 1
+	if strings.HasPrefix(s, "地下") {
+		sign = -1
+		s = strings.TrimPrefix(s, "地下")
+	}
+	s = strings.TrimSuffix(strings.TrimSuffix(s, "層"), "樓")
+	v, ok := ChineseNumeralToInt(s)
+	if !ok {
+		return 0, false
+	}
+	return sign * v, true
+}
+
+// Input: positive integer below one hundred, e.g., 4 = 四, 12 = 十二
+func IntToChineseNumeral(v int) string {
+	digits := []string{"零", "一", "二", "三", "四", "五", "六", "七", "八", "九"}
+	if v < 10 {
+		return digits[v]
+	}
+	if v < 100 {
+		s := "十"
+		if v/10 > 1 {
+			s = digits[v/10] + s
+		}
+		if v%10 > 0 {
+			s += digits[v%10]
+		}
+		return s
+	}
+	return strconv.Itoa(v)
+}
@@ -0,0 +1,43 @@
+package common
+
+import "testing"
+
+func TestChineseNumeralToInt(t *testing.T) {
+	for s, want := range map[string]int{
+		"四": 4, "十": 10, "十一": 11, "二十一": 21, "一百零五": 105, "兩千三百": 2300, "１２": 12, "12": 12,
+	} {
+		if got, ok := ChineseNumeralToInt(s); !ok || got != want {
+			t.Errorf("expected %s to be %d, got %d %v", s, want, got, ok)
+		}
+	}
+	for _, s := range []string{"", "樓", "十A"} {
+		if got, ok := ChineseNumeralToInt(s); ok {
+			t.Errorf("expected %s to be invalid, got %d", s, got)
+		}
+	}
+}
+
+func TestParseFloor(t *testing.T) {
+	for s, want := range map[string]int{
+		"十一層": 11, "五樓": 5, "地下一層": -1, "三層,四層": 3, "全，二層": 0, " 7 ": 7,
+	} {
+		got, ok := ParseFloor(s)
+		if want == 0 {
+			if ok {
+				t.Errorf("expected %s to be invalid, got %d", s, got)
+			}
+			continue
+		}
+		if !ok || got != want {
+			t.Errorf("expected %s to be the floor %d, got %d %v", s, want, got, ok)
+		}
+	}
+}
+
+func TestIntToChineseNumeral(t *testing.T) {
+	for v, want := range map[int]string{4: "四", 10: "十", 12: "十二", 20: "二十", 99: "九十九", 105: "105"} {
+		if got := IntToChineseNumeral(v); got != want {
+			t.Errorf("expected %d to be %s, got %s", v, want, got)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	RocSeasonFormattingError = "CM00001"
+)
+
+// Input: year in common era and quarter, e.g., 2023, 1 = 112S1
+func ToRocSeason(year int, quarter int) string {
+	return fmt.Sprintf("%dS%d", year-1911, quarter)
+}
+
+// Input: ROC season, e.g., 112S1 = [2023/01/01, 2023/04/01)
+func RocSeasonToDateRange(season string) (*time.Time, *time.Time, *e.ErrorData) {
+	parts := strings.Split(strings.ToUpper(season), "S")
+	if len(parts) != 2 {
+		return nil, nil, e.NewErrorData(
+			RocSeasonFormattingError,
+			fmt.Sprintf("Incorrect ROC season format %s, expect <roc year>S<quarter>, e.g, 112S1", season),
+			fmt.Sprintf("%s.RocSeasonToDateRange", currentPackage),
+			nil,
+			nil,
+		)
+	}
+	rocYear, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return nil, nil, e.NewErrorData(
+			RocSeasonFormattingError,
+			fmt.Sprintf("Incorrect ROC season format: %s, year extracting error %s", season, err.Error()),
+			fmt.Sprintf("%s.RocSeasonToDateRange", currentPackage),
+			nil,
+			nil,
+		).WithCause(err)
+	}
+	quarter, err := strconv.Atoi(parts[1])
+	if err != nil || quarter < 1 || quarter > 4 {
+		return nil, nil, e.NewErrorData(
+			RocSeasonFormattingError,
+			fmt.Sprintf("Incorrect ROC season format: %s, quarter should be within 1 to 4", season),
+			fmt.Sprintf("%s.RocSeasonToDateRange", currentPackage),
+			nil,
+			nil,
+		)
+	}
+
+	from := time.Date(rocYear+1911, time.Month((quarter-1)*3+1), 1, 0, 0, 0, 0, time.Local)
+	to := from.AddDate(0, 3, 0)
+	return &from, &to, nil
+}
@@ -0,0 +1,51 @@
+package common
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRocSeasonToDateRange(t *testing.T) {
+	for _, c := range []struct {
+		season   string
+		from, to string
+	}{
+		{"112S1", "2023-01-01", "2023-04-01"},
+		{"112S2", "2023-04-01", "2023-07-01"},
+		{"112s3", "2023-07-01", "2023-10-01"},
+		{"112S4", "2023-10-01", "2024-01-01"},
+		{"99S4", "2010-10-01", "2011-01-01"},
+	} {
+		from, to, err := RocSeasonToDateRange(c.season)
+		if err != nil {
+			t.Errorf("%s: unexpected error %s", c.season, err.Message)
+			continue
+		}
+		if got := from.Format(time.DateOnly); got != c.from {
+			t.Errorf("%s: expected to start on %s, got %s", c.season, c.from, got)
+		}
+		if got := to.Format(time.DateOnly); got != c.to {
+			t.Errorf("%s: expected to end before %s, got %s", c.season, c.to, got)
+		}
+		// the seasons are half open ranges starting at midnight
+		if from.Hour() != 0 || to.Hour() != 0 {
+			t.Errorf("%s: expected the range to start and end at midnight, got %s and %s", c.season, from, to)
+		}
+	}
+	for _, season := range []string{"", "112", "112S0", "112S5", "S1", "112S1S2", "abcS1"} {
+		if _, _, err := RocSeasonToDateRange(season); err == nil || err.Code != RocSeasonFormattingError {
+			t.Errorf("expected %q to be invalid, got %v", season, err)
+		}
+	}
+}
+
+func TestToRocSeason(t *testing.T) {
+	if got := ToRocSeason(2023, 4); got != "112S4" {
+		t.Errorf("expected 112S4, got %s", got)
+	}
+	// the range of a season gives the season back
+	from, _, _ := RocSeasonToDateRange("101S3")
+	if got := ToRocSeason(from.Year(), (int(from.Month())+2)/3); got != "101S3" {
+		t.Errorf("expected 101S3, got %s", got)
+	}
+}
@@ -0,0 +1,16 @@
+package common
+
+import "strings"
+
+// ToHalfWidth converts full-width digits, letters and symbols, e.g., ３１ = 31
+func ToHalfWidth(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '　' {
+			return ' '
+		}
+		if r >= '！' && r <= '～' {
+			return r - 0xfee0
+		}
+		return r
+	}, s)
+}
@@ -9,7 +9,7 @@ import (
 )
 
 const (
-	RocEraFormattingError = "PS00002"
+	RocEraFormattingError = "CM00002"
 
 	currentPackage = "github.com/Walker088/gorealestate/common/time"
 )
@@ -39,7 +39,7 @@ func RocEraToCommonEra(dateStr string) (*time.Time, *e.ErrorData) {
 			fmt.Sprintf("%s.RocEraToCommonEra", currentPackage),
 			nil,
 			nil,
-		)
+		).WithCause(err)
 	}
 
 	monthAndDay := dateStr[len(rocYearStr):] //SliceDiff(dateStr, rocYearStr)
@@ -52,7 +52,7 @@ func RocEraToCommonEra(dateStr string) (*time.Time, *e.ErrorData) {
 			fmt.Sprintf("%s.RocEraToCommonEra", currentPackage),
 			nil,
 			nil,
-		)
+		).WithCause(err)
 	}
 
 	day, err := strconv.Atoi(monthAndDay[2:])
@@ -63,7 +63,7 @@ func RocEraToCommonEra(dateStr string) (*time.Time, *e.ErrorData) {
 			fmt.Sprintf("%s.RocEraToCommonEra", currentPackage),
 			nil,
 			nil,
-		)
+		).WithCause(err)
 	}
 
 	converted := time.Date(rocToCommonEra(int(rocYear)), time.Month(month), day, 0, 0, 0, 0, time.Local)
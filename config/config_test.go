@@ -0,0 +1,77 @@
+package config
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestConnStringEscaping(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		pg   PgConfig
+		tls  bool
+	}{
+		{"plain", PgConfig{DbUser: "app", DbPass: "secret", SslMode: "disable"}, false},
+		{"url delimiters", PgConfig{DbUser: "app", DbPass: "p@ss:w/rd?#x", SslMode: "require"}, true},
+		{"percent and spaces", PgConfig{DbUser: "app user", DbPass: "100% sure &more=1", SslMode: "prefer"}, true},
+		{"quoted schema", PgConfig{DbUser: "app", DbPass: "a'b\"c\\d", DbSchema: `my"schema`, SslMode: "disable"}, false},
+	} {
+		c.pg.DbHost, c.pg.DbPort, c.pg.DbName = "db.example.com", 5433, "real estate"
+		if c.pg.DbSchema == "" {
+			c.pg.DbSchema = "staging"
+		}
+		for kind, conn := range map[string]string{"conn": c.pg.ToConnString(), "migration": c.pg.MigrationConnString()} {
+			parsed, err := pgconn.ParseConfig(conn)
+			if err != nil {
+				t.Errorf("%s: expected the %s string to parse, got %s", c.name, kind, err)
+				continue
+			}
+			if parsed.User != c.pg.DbUser || parsed.Password != c.pg.DbPass {
+				t.Errorf("%s: expected the %s credentials %q %q, got %q %q", c.name, kind, c.pg.DbUser, c.pg.DbPass, parsed.User, parsed.Password)
+			}
+			if parsed.Host != c.pg.DbHost || parsed.Port != 5433 || parsed.Database != c.pg.DbName {
+				t.Errorf("%s: expected the %s address %s:5433/%s, got %s:%d/%s", c.name, kind, c.pg.DbHost, c.pg.DbName, parsed.Host, parsed.Port, parsed.Database)
+			}
+			if got := parsed.TLSConfig != nil; got != c.tls {
+				t.Errorf("%s: expected the %s tls %v of sslmode %s, got %v", c.name, kind, c.tls, c.pg.SslMode, got)
+			}
+			want := c.pg.SearchPath()
+			if kind == "migration" {
+				want += ",public"
+			}
+			if got := parsed.RuntimeParams["search_path"]; got != want {
+				t.Errorf("%s: expected the %s search path %s, got %s", c.name, kind, want, got)
+			}
+		}
+	}
+}
+
+func TestConnStringTLSFiles(t *testing.T) {
+	pg := PgConfig{
+		DbHost: "localhost", DbPort: 5432, DbName: "db", DbUser: "app", DbPass: "p@ss", DbSchema: "public",
+		SslMode: "verify-full", SslRootCert: "/etc/ssl/my certs/root.crt", SslCert: "/tmp/client&1.crt", SslKey: "/tmp/client#1.key",
+	}
+	// pgconn reads the files while parsing, the url query is checked instead
+	u, err := url.Parse(pg.ToConnString())
+	if err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"sslmode": "verify-full", "sslrootcert": pg.SslRootCert, "sslcert": pg.SslCert, "sslkey": pg.SslKey, "search_path": `"public"`,
+	} {
+		if got := u.Query().Get(key); got != want {
+			t.Errorf("expected %s %q, got %q", key, want, got)
+		}
+	}
+	if password, _ := u.User.Password(); password != pg.DbPass {
+		t.Errorf("expected the password %q, got %q", pg.DbPass, password)
+	}
+	if got := pg.MigrationConnString(); got != pg.ToConnString() {
+		t.Errorf("expected public alone on the search path of the migrations, got %s", got)
+	}
+	if redacted := pg.Redacted(); redacted == pg.ToConnString() || u.Redacted() != redacted {
+		t.Errorf("expected the password to be redacted, got %s", redacted)
+	}
+}
@@ -0,0 +1,31 @@
+package config
+
+const (
+	ProfileDevelopment = "development"
+	ProfileStaging     = "staging"
+	ProfileProduction  = "production"
+
+	// ProfileEnv selects the profile when no flag is given
+	ProfileEnv = "GOREALESTATE_PROFILE"
+)
+
+var (
+	Profiles = []string{ProfileDevelopment, ProfileStaging, ProfileProduction}
+
+	// profileDefaults override the defaults, the files and env vars still take precedence
+	profileDefaults = map[string]map[string]interface{}{
+		ProfileDevelopment: {},
+		ProfileStaging: {
+			"database.sslmode": "require",
+		},
+		ProfileProduction: {
+			"database.sslmode":     "require",
+			"logger.console_level": "warn",
+		},
+	}
+)
+
+// Profile returns the profile the config is loaded with
+func (c *AppConfig) Profile() string {
+	return c.profile
+}
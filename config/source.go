@@ -0,0 +1,130 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	SourceDefault = "default"
+	SourceFile    = "file"
+	SourceEnv     = "env"
+	SourceFlag    = "flag"
+	SourceSecret  = "secret file"
+
+	redacted = "******"
+)
+
+var (
+	// secrets are redacted when the config is printed
+	secrets = map[string]bool{
+		"database.password": true,
+		"admin.token":       true,
+	}
+)
+
+// Setting is an effective config value and where it comes from
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // default, file, env, flag or secret file
+}
+
+// Override sets a key from a command line flag, the config is validated again and the
+// override is dropped when invalid
+func (c *AppConfig) Override(key string, value interface{}) *e.ErrorData {
+	if _, ok := defaults[key]; !ok {
+		return e.NewErrorData(
+			ConfigValidationError,
+			fmt.Sprintf("unknown config key %s", key),
+			fmt.Sprintf("%s.Override", currentPackage),
+			nil,
+			nil,
+		)
+	}
+	c.mu.RLock()
+	v := c.v
+	c.mu.RUnlock()
+	previous := v.Get(key)
+	v.Set(key, value)
+	if errData := c.load(v); errData != nil {
+		v.Set(key, previous)
+		return errData
+	}
+	c.mu.Lock()
+	c.overrides[key] = value
+	c.mu.Unlock()
+	return nil
+}
+
+// Settings lists the effective value of every key along with its source, the secrets are redacted
+func (c *AppConfig) Settings() []Setting {
+	keys := make([]string, 0, len(defaults))
+	for key := range defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	settings := make([]Setting, len(keys))
+	for i, key := range keys {
+		value, source := display(c.v.Get(key)), c.source(key)
+		if key == "database.password" && c.pgConfig.PasswordFile != "" {
+			value, source = c.pgConfig.DbPass, SourceSecret
+		}
+		if secrets[key] && value != "" {
+			value = redacted
+		}
+		settings[i] = Setting{Key: key, Value: value, Source: source}
+	}
+	return settings
+}
+
+// display renders the lists as comma separated values, i.e., the format of the env vars
+func display(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	case []string:
+		return strings.Join(v, ",")
+	case []int:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = fmt.Sprint(item)
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(value)
+}
+
+// source follows the precedence of viper, i.e., flag, env, file then default, the caller holds the lock
+func (c *AppConfig) source(key string) string {
+	if _, ok := c.overrides[key]; ok {
+		return SourceFlag
+	}
+	// viper ignores the empty env vars
+	if os.Getenv(strings.ToUpper(strings.ReplaceAll(key, ".", "_"))) != "" {
+		return SourceEnv
+	}
+	if env, ok := legacyEnv[key]; ok && os.Getenv(env) != "" {
+		return SourceEnv
+	}
+	if c.v.InConfig(key) {
+		return SourceFile
+	}
+	return SourceDefault
+}
+
+// Files returns the paths of the loaded config files in the order they are merged
+func (c *AppConfig) Files() []string {
+	return c.files
+}
@@ -0,0 +1,280 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"go.uber.org/zap/zapcore"
+
+	"github.com/Walker088/gorealestate/common"
+	e "github.com/Walker088/gorealestate/error"
+)
+
+var (
+	isCityCode = regexp.MustCompile(`^[a-z]$`)
+
+	drivers = map[string]bool{DriverPostgres: true, DriverSQLite: true}
+
+	sslModes = map[string]bool{
+		"disable": true, "allow": true, "prefer": true, "require": true, "verify-ca": true, "verify-full": true,
+	}
+
+	// Families are the plvr file families, i.e., the suffix of [a-z]_lvr_land_[a-c].csv
+	Families = map[string]string{
+		"house_sale": "a",
+		"new_house":  "b",
+		"rental":     "c",
+	}
+)
+
+// Validate checks the loaded sections again and lists each invalid key in the details, the
+// files referred to, e.g., the password file, may have been removed since the load
+func (c *AppConfig) Validate() *e.ErrorData {
+	c.mu.RLock()
+	s := c.raw
+	c.mu.RUnlock()
+	return s.validate()
+}
+
+// loopback tells whether addr is only reachable from the host, e.g., 127.0.0.1:9091 or
+// localhost:9091, an empty host listens on every interface
+func loopback(addr string) bool {
+	host, _, _ := net.SplitHostPort(addr)
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+func (s *sections) validate() *e.ErrorData {
+	details := []e.Error{}
+	invalid := func(key string, format string, args ...interface{}) {
+		details = append(details, *e.NewError(*e.NewErrorData(
+			ConfigValidationError,
+			fmt.Sprintf(format, args...),
+			key,
+			nil,
+			nil,
+		)))
+	}
+
+	db := &s.Database
+	if !drivers[db.Driver] {
+		invalid("database.driver", "driver %s is invalid, expect postgres or sqlite", db.Driver)
+	}
+	if db.Driver == DriverSQLite && db.SQLitePath == "" {
+		invalid("database.sqlite_path", "sqlite path is required by the sqlite driver")
+	}
+	if db.DbHost == "" {
+		invalid("database.host", "host is required")
+	}
+	if db.DbPort < 1 || db.DbPort > 65535 {
+		invalid("database.port", "port %d is out of range", db.DbPort)
+	}
+	if db.DbName == "" {
+		invalid("database.name", "database name is required")
+	}
+	if db.DbUser == "" {
+		invalid("database.user", "user is required")
+	}
+	if db.DbSchema == "" {
+		invalid("database.schema", "schema is required")
+	}
+	if db.DbPass != "" && db.PasswordFile != "" {
+		invalid("database.password_file", "password and password file are both set, expect one of them")
+	}
+	if db.PasswordFile != "" {
+		if info, err := os.Stat(db.PasswordFile); err != nil || info.IsDir() {
+			invalid("database.password_file", "password file %s is not readable", db.PasswordFile)
+		}
+	}
+	if !sslModes[db.SslMode] {
+		invalid("database.sslmode", "sslmode %s is invalid, expect disable, allow, prefer, require, verify-ca or verify-full", db.SslMode)
+	}
+	if (db.SslCert == "") != (db.SslKey == "") {
+		invalid("database.sslcert", "sslcert and sslkey should be set together")
+	}
+	for key, file := range map[string]string{
+		"database.sslrootcert": db.SslRootCert,
+		"database.sslcert":     db.SslCert,
+		"database.sslkey":      db.SslKey,
+	} {
+		if _, err := os.Stat(file); file != "" && err != nil {
+			invalid(key, "certificate file %s is not readable", file)
+		}
+	}
+	if db.MigrationsDir != "" {
+		if info, err := os.Stat(db.MigrationsDir); err != nil || !info.IsDir() {
+			invalid("database.migrations_dir", "migrations dir %s is not a directory", db.MigrationsDir)
+		}
+	}
+	if db.MinConns < 0 {
+		invalid("database.min_conns", "min conns %d should not be negative", db.MinConns)
+	}
+	if db.MaxConns < 1 {
+		invalid("database.max_conns", "max conns %d should be at least 1", db.MaxConns)
+	}
+	if db.MinConns > db.MaxConns {
+		invalid("database.min_conns", "min conns %d exceeds the max conns %d", db.MinConns, db.MaxConns)
+	}
+	for key, d := range map[string]time.Duration{
+		"database.max_conn_idle_time":       db.MaxConnIdleTime,
+		"database.max_conn_lifetime":        db.MaxConnLifetime,
+		"database.max_conn_lifetime_jitter": db.MaxConnLifetimeJitter,
+		"database.health_check_period":      db.HealthCheckPeriod,
+		"database.slow_query":               db.SlowQuery,
+	} {
+		if d < 0 {
+			invalid(key, "duration %s should not be negative", d)
+		}
+	}
+	if db.HealthCheckPeriod == 0 {
+		invalid("database.health_check_period", "health check period should be positive")
+	}
+	if db.HealthTimeout <= 0 {
+		invalid("database.health_timeout", "health timeout %s should be positive", db.HealthTimeout)
+	}
+	if db.ConnectRetries < 0 {
+		invalid("database.connect_retries", "connect retries %d should not be negative", db.ConnectRetries)
+	}
+	if db.ConnectBackoff <= 0 {
+		invalid("database.connect_backoff", "connect backoff %s should be positive", db.ConnectBackoff)
+	}
+
+	for key, lvl := range map[string]string{
+		"logger.console_level": s.Logger.ConsoleLogLevel,
+		"logger.file_level":    s.Logger.FileLogLevel,
+	} {
+		if _, err := zapcore.ParseLevel(lvl); err != nil {
+			invalid(key, "log level %s is invalid, expect debug, info, warn, error, dpanic, panic or fatal", lvl)
+		}
+	}
+
+	lg := &s.Logger
+	if lg.Dir == "" {
+		invalid("logger.dir", "log directory is required")
+	}
+	if lg.File == "" {
+		invalid("logger.file", "log file is required")
+	}
+	if lg.ErrorFile != "" && lg.ErrorFile == lg.File {
+		invalid("logger.error_file", "error file should differ from the log file")
+	}
+	for key, n := range map[string]int{
+		"logger.max_size_mb":  lg.MaxSizeMB,
+		"logger.max_backups":  lg.MaxBackups,
+		"logger.max_age_days": lg.MaxAgeDays,
+	} {
+		if n < 0 {
+			invalid(key, "%d should not be negative", n)
+		}
+	}
+
+	cr := &s.Crawler
+	from, _, errData := common.RocSeasonToDateRange(cr.FromSeason)
+	if errData != nil {
+		invalid("crawler.from_season", errData.Message)
+	}
+	if cr.ToSeason != "" {
+		to, _, errData := common.RocSeasonToDateRange(cr.ToSeason)
+		if errData != nil {
+			invalid("crawler.to_season", errData.Message)
+		} else if from != nil && to.Before(*from) {
+			invalid("crawler.to_season", "to season %s is before the from season %s", cr.ToSeason, cr.FromSeason)
+		}
+	}
+	for _, city := range cr.Cities {
+		if !isCityCode.MatchString(city) {
+			invalid("crawler.cities", "city code %s is invalid, expect a single letter, e.g., a for Taipei", city)
+		}
+	}
+	if len(cr.Families) == 0 {
+		invalid("crawler.families", "at least one of house_sale, new_house or rental is required")
+	}
+	for _, f := range cr.Families {
+		if _, ok := Families[f]; !ok {
+			invalid("crawler.families", "file family %s is invalid, expect house_sale, new_house or rental", f)
+		}
+	}
+	if cr.Concurrency < 1 {
+		invalid("crawler.concurrency", "concurrency %d should be at least 1", cr.Concurrency)
+	}
+	if cr.Interval < time.Minute {
+		invalid("crawler.interval", "interval %s should be at least 1m", cr.Interval)
+	}
+	if cr.DownloadDir == "" {
+		invalid("crawler.download_dir", "download directory is required")
+	}
+	if u, err := url.Parse(cr.ApiUrl); err != nil || u.Host == "" {
+		invalid("crawler.api_url", "api url %s is not an absolute url", cr.ApiUrl)
+	} else if strings.Count(cr.ApiUrl, "%s") != 1 {
+		invalid("crawler.api_url", "api url %s should contain a single %%s for the season", cr.ApiUrl)
+	}
+
+	h := &s.HTTP
+	if h.Timeout <= 0 {
+		invalid("http.timeout", "timeout %s should be positive", h.Timeout)
+	}
+	if h.MaxBodySize <= 0 {
+		invalid("http.max_body_size", "max body size %d should be positive", h.MaxBodySize)
+	}
+	if h.RetryTimes < 0 {
+		invalid("http.retry_times", "retry times %d should not be negative", h.RetryTimes)
+	}
+	for _, code := range h.RetryHTTPCodes {
+		if code < 100 || code > 599 {
+			invalid("http.retry_http_codes", "http status %d is invalid", code)
+		}
+	}
+	if h.Proxy != "" {
+		if u, err := url.Parse(h.Proxy); err != nil || u.Host == "" {
+			invalid("http.proxy", "proxy %s is not an absolute url", h.Proxy)
+		}
+	}
+
+	srv := &s.Server
+	if _, _, err := net.SplitHostPort(srv.Addr); err != nil {
+		invalid("server.addr", "address %s is invalid, %s", srv.Addr, err.Error())
+	}
+	if srv.ReadTimeout < 0 {
+		invalid("server.read_timeout", "read timeout %s should not be negative", srv.ReadTimeout)
+	}
+	if srv.ReadHeaderTimeout < 0 {
+		invalid("server.read_header_timeout", "read header timeout %s should not be negative", srv.ReadHeaderTimeout)
+	}
+	if srv.WriteTimeout < 0 {
+		invalid("server.write_timeout", "write timeout %s should not be negative", srv.WriteTimeout)
+	}
+	if srv.ShutdownTimeout <= 0 {
+		invalid("server.shutdown_timeout", "shutdown timeout %s should be positive", srv.ShutdownTimeout)
+	}
+
+	if s.Admin.Addr != "" {
+		if _, _, err := net.SplitHostPort(s.Admin.Addr); err != nil {
+			invalid("admin.addr", "address %s is invalid, %s", s.Admin.Addr, err.Error())
+		} else if s.Admin.Addr == srv.Addr {
+			invalid("admin.addr", "address %s is already used by the api server", s.Admin.Addr)
+		} else if !loopback(s.Admin.Addr) && s.Admin.Token == "" {
+			invalid("admin.token", "token is required by the admin address %s reachable from the network", s.Admin.Addr)
+		}
+	}
+
+	if len(details) > 0 {
+		sort.SliceStable(details, func(i, j int) bool { return details[i].Target < details[j].Target })
+		return e.NewErrorData(
+			ConfigValidationError,
+			fmt.Sprintf("%d invalid config values", len(details)),
+			fmt.Sprintf("%s.Validate", currentPackage),
+			details,
+			nil,
+		)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+// writeFile writes content to name under a temporary directory and returns its path
+func writeFile(t *testing.T, name string, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestNewDefaults(t *testing.T) {
+	for _, profile := range Profiles {
+		c, err := New(profile)
+		if err != nil {
+			t.Fatalf("expected the defaults of %s to be valid, got %v", profile, err.Details)
+		}
+		if err := c.Validate(); err != nil {
+			t.Errorf("expected the defaults of %s to be valid again, got %v", profile, err.Details)
+		}
+	}
+	if _, err := New("test"); err == nil || err.Code != ConfigValidationError {
+		t.Errorf("expected an unknown profile to be a %s, got %v", ConfigValidationError, err)
+	}
+}
+
+func TestNewInvalidKeys(t *testing.T) {
+	file := writeFile(t, "config.yaml", `
+database:
+  driver: mysql
+  port: 70000
+  min_conns: 5
+  max_conns: 2
+crawler:
+  from_season: 101S5
+  cities: [a, TPE]
+  families: [house_sale, land]
+  api_url: https://plvr.land.moi.gov.tw/download
+server:
+  addr: ":8080"
+admin:
+  addr: ":9091"
+`)
+	_, err := New(ProfileDevelopment, file)
+	if err == nil || err.Code != ConfigValidationError {
+		t.Fatalf("expected a %s, got %v", ConfigValidationError, err)
+	}
+	keys := []string{}
+	for _, d := range err.Details {
+		keys = append(keys, d.Target)
+	}
+	want := []string{
+		"admin.token",
+		"crawler.api_url",
+		"crawler.cities",
+		"crawler.families",
+		"crawler.from_season",
+		"database.driver",
+		"database.min_conns",
+		"database.port",
+	}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("expected the invalid keys %v, got %v", want, keys)
+	}
+}
+
+func TestNewAdminToken(t *testing.T) {
+	file := writeFile(t, "config.yaml", "admin:\n  addr: \":9091\"\n  token: secret\n")
+	c, err := New(ProfileDevelopment, file)
+	if err != nil {
+		t.Fatalf("expected a token to allow a network address, got %v", err.Details)
+	}
+	for _, s := range c.Settings() {
+		if s.Key == "admin.token" && s.Value != redacted {
+			t.Errorf("expected admin.token to be redacted, got %s", s.Value)
+		}
+	}
+}
+
+func TestNewPasswordFile(t *testing.T) {
+	password := writeFile(t, "password", "secret\n")
+	file := writeFile(t, "config.yaml", "database:\n  password_file: "+password+"\n")
+	c, err := New(ProfileDevelopment, file)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err.Details)
+	}
+	if pass := c.GetPgConfig().DbPass; pass != "secret" {
+		t.Errorf("expected the password of the file, got %q", pass)
+	}
+	// the password and its file are both set once read, the sections loaded are validated instead
+	if err := c.Validate(); err != nil {
+		t.Fatalf("expected the config to be valid again, got %v", err.Details)
+	}
+	os.Remove(password)
+	if err := c.Validate(); err == nil || len(err.Details) != 1 || err.Details[0].Target != "database.password_file" {
+		t.Errorf("expected the removed password file to be invalid, got %v", err)
+	}
+}
+
+func TestNewLegacyFile(t *testing.T) {
+	file := writeFile(t, ".env.development", "DB_HOST=db.internal\nDB_PORT=5433\nFILE_LOG_LEVEL=warn\nUNKNOWN=1\n")
+	if !IsLegacyFile(file) {
+		t.Fatalf("expected %s to be a legacy file", file)
+	}
+	c, err := New(ProfileDevelopment, file)
+	if err != nil {
+		t.Fatalf("unexpected error %v", err.Details)
+	}
+	db, lg := c.GetPgConfig(), c.GetLoggerConfig()
+	if db.DbHost != "db.internal" || db.DbPort != 5433 || lg.FileLogLevel != "warn" {
+		t.Errorf("expected the legacy variables to be read, got %s:%d %s", db.DbHost, db.DbPort, lg.FileLogLevel)
+	}
+}
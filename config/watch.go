@@ -0,0 +1,134 @@
+package config
+
+import (
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	// editors write a file in several events, they are coalesced into a single reload
+	reloadDelay = 200 * time.Millisecond
+)
+
+// OnChange registers fn to be called after each valid reload
+func (c *AppConfig) OnChange(fn func(*AppConfig)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, fn)
+}
+
+// Reload reads the config files again along with the env vars and the flag overrides,
+// the current config is kept when the new one is invalid
+func (c *AppConfig) Reload() *e.ErrorData {
+	v, errData := c.newViper()
+	if errData != nil {
+		return errData
+	}
+	c.mu.RLock()
+	for key, value := range c.overrides {
+		v.Set(key, value)
+	}
+	c.mu.RUnlock()
+	if errData := c.load(v); errData != nil {
+		return errData
+	}
+
+	c.mu.RLock()
+	listeners := append([]func(*AppConfig){}, c.listeners...)
+	c.mu.RUnlock()
+	for _, fn := range listeners {
+		fn(c)
+	}
+	return nil
+}
+
+// WatchConfig reloads the config files on changes until stop is closed, invalid changes are
+// logged and rolled back, i.e., the running components keep the previous config
+func (c *AppConfig) WatchConfig(logger *zap.SugaredLogger, stop <-chan struct{}) *e.ErrorData {
+	if len(c.files) == 0 {
+		logger.Info("[config] no config file to watch")
+		return nil
+	}
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return e.Wrap(
+			ConfigWatchError,
+			err,
+			fmt.Sprintf("%s.WatchConfig", currentPackage),
+		)
+	}
+	// the directories are watched since the editors often replace the files instead of writing them
+	files := map[string]bool{}
+	for _, file := range c.files {
+		files[filepath.Clean(file)] = true
+		if err := watcher.Add(filepath.Dir(file)); err != nil {
+			watcher.Close()
+			return e.Wrap(
+				ConfigWatchError,
+				err,
+				fmt.Sprintf("%s.WatchConfig", currentPackage),
+			)
+		}
+	}
+
+	var reloading sync.Mutex
+	reload := func(file string) {
+		reloading.Lock()
+		defer reloading.Unlock()
+		previous := *c.GetPgConfig()
+		previousServer := *c.GetServerConfig()
+		if errData := c.Reload(); errData != nil {
+			logger.Errorf("[config] invalid change of %s is ignored: %s", file, errData.Message)
+			for _, d := range errData.Details {
+				logger.Errorf("[config]   %s: %s", d.Target, d.Message)
+			}
+			return
+		}
+		logger.Infof("[config] %s reloaded", file)
+		if !reflect.DeepEqual(previous, *c.GetPgConfig()) || !reflect.DeepEqual(previousServer, *c.GetServerConfig()) {
+			logger.Warn("[config] the database and server sections only apply after a restart")
+		}
+	}
+
+	go func() {
+		defer watcher.Close()
+		var timer *time.Timer
+		for {
+			select {
+			case <-stop:
+				if timer != nil {
+					timer.Stop()
+				}
+				return
+			case event, ok := <-watcher.Events:
+				if !ok {
+					return
+				}
+				file := filepath.Clean(event.Name)
+				if !files[file] || event.Op&(fsnotify.Write|fsnotify.Create) == 0 {
+					continue
+				}
+				if timer != nil {
+					timer.Stop()
+				}
+				timer = time.AfterFunc(reloadDelay, func() { reload(file) })
+			case err, ok := <-watcher.Errors:
+				if !ok {
+					return
+				}
+				logger.Errorf("[config] watching %s: %s", strings.Join(c.files, ", "), err.Error())
+			}
+		}
+	}()
+	logger.Infof("[config] watching %s", strings.Join(c.files, ", "))
+	return nil
+}
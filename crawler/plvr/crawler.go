@@ -7,16 +7,23 @@ import (
 	"fmt"
 	"io"
 	"math/rand"
-	"net/http"
 	"os"
 	"regexp"
+	"sort"
 	"sync"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/Walker088/gorealestate/common"
+	"github.com/Walker088/gorealestate/config"
 	e "github.com/Walker088/gorealestate/error"
-	"github.com/jackc/pgx/v5/pgxpool"
+	ghttp "github.com/Walker088/gorealestate/http"
+	"github.com/Walker088/gorealestate/logger"
+	"github.com/Walker088/gorealestate/metrics"
+	"github.com/Walker088/gorealestate/reference"
+	"github.com/Walker088/gorealestate/report"
+	"github.com/Walker088/gorealestate/store"
 )
 
 const (
@@ -25,16 +32,14 @@ const (
 	CopyZipContentToFileError = "PV00003"
 	OpenZippedFileError       = "PV00004"
 	ReadZippedFileError       = "PV00005"
-	CheckRecordExistsError    = "PV00006"
 	HttpRequestError          = "PV00007"
 	ReadZipFileFromLocalError = "PV00008"
 	CreateZipReaderError      = "PV00009"
 	UnmarshalCsvError         = "PV00010"
+	SaveRowsError             = "PV00011"
+	UnknownDistrictError      = "PV00013"
 
 	currentPackage = "github.com/Walker088/gorealestate/crawler/plvr"
-	apiUrl         = "https://plvr.land.moi.gov.tw/DownloadSeason?season=%s&type=zip&fileName=lvr_landcsv.zip"
-	startDate      = "2013-01-01"
-	storePath      = "downloaded/plvr"
 	storeName      = "lvr_landcsv.zip"
 )
 
@@ -50,116 +55,186 @@ type PlvrCrawler struct {
 	cancel context.CancelFunc
 	wg     sync.WaitGroup
 
-	workingDir string
-	logger     *zap.SugaredLogger
-	pool       *pgxpool.Pool
-	ResultsCh  chan string
-	ErrorsCh   chan *e.ErrorData
+	workingDir   string
+	mu           sync.RWMutex
+	cfg          *config.CrawlerConfig
+	limiter      *limiter
+	client       *ghttp.Client
+	recorder     *report.Recorder
+	ref          *reference.Reference
+	transactions store.TransactionStore
+	history      store.HistoryStore
+	logger       *zap.SugaredLogger
+
+	// touched are the seasons of the transaction dates of the inserted and updated rows, "" for the undated ones
+	touchedMu sync.Mutex
+	touched   map[string]bool
+
+	ResultsCh chan string
+	ErrorsCh  chan *e.ErrorData
 }
 
-func New(ctx context.Context, cancel context.CancelFunc, rootDir string, logger *zap.SugaredLogger, pool *pgxpool.Pool) *PlvrCrawler {
+func New(ctx context.Context, cancel context.CancelFunc, rootDir string, cfg *config.CrawlerConfig, client *ghttp.Client, recorder *report.Recorder, ref *reference.Reference, logger *zap.SugaredLogger, transactions store.TransactionStore, history store.HistoryStore) *PlvrCrawler {
 	return &PlvrCrawler{
-		ctx:        ctx,
-		cancel:     cancel,
-		workingDir: fmt.Sprintf("%s/%s", rootDir, storePath),
-		logger:     logger,
-		pool:       pool,
-		ResultsCh:  make(chan string),
-		ErrorsCh:   make(chan *e.ErrorData),
+		ctx:          ctx,
+		cancel:       cancel,
+		workingDir:   fmt.Sprintf("%s/%s", rootDir, cfg.DownloadDir),
+		cfg:          cfg,
+		limiter:      newLimiter(cfg.Concurrency),
+		client:       client,
+		recorder:     recorder,
+		ref:          ref,
+		transactions: transactions,
+		history:      history,
+		logger:       logger,
+		touched:      map[string]bool{},
+		ResultsCh:    make(chan string),
+		ErrorsCh:     make(chan *e.ErrorData),
 	}
 }
 
+// Reload applies a new crawler config, the concurrency, cities, families and api url apply to
+// the seasons not started yet, the season range and download dir to the next run
+func (p *PlvrCrawler) Reload(cfg *config.CrawlerConfig) {
+	p.mu.Lock()
+	p.cfg = cfg
+	p.mu.Unlock()
+	p.limiter.setLimit(cfg.Concurrency)
+	p.logger.Infow("crawler config reloaded", "concurrency", cfg.Concurrency)
+}
+
+func (p *PlvrCrawler) config() *config.CrawlerConfig {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.cfg
+}
+
 func (p *PlvrCrawler) Start() {
-	p.logger.Infof("start crawlering %s", apiUrl)
-	monthToSeason := func(month int) int {
-		if month < 4 {
-			return 1
-		}
-		if month < 7 {
-			return 2
-		}
-		if month < 10 {
-			return 3
-		}
-		return 4
-	}
-	commonToRocEra := func(era int) int {
-		return era - 1911
-	}
+	cfg := p.config()
+	p.logger.Infow("start crawlering", "api_url", cfg.ApiUrl)
 
-	start, _ := time.Parse(time.DateOnly, startDate)
-	today := time.Now()
+	// the seasons are validated with the config
+	start, _, _ := common.RocSeasonToDateRange(cfg.FromSeason)
+	end := time.Now()
+	if cfg.ToSeason != "" {
+		_, to, _ := common.RocSeasonToDateRange(cfg.ToSeason)
+		end = *to
+	}
 
-	for yearSeason := start; yearSeason.Before(today); yearSeason = yearSeason.AddDate(0, 3, 0) {
-		season := monthToSeason(int(yearSeason.Month()))
-		yearSeason := fmt.Sprintf("%dS%d", commonToRocEra(yearSeason.Year()), season)
+	for yearSeason := *start; yearSeason.Before(end); yearSeason = yearSeason.AddDate(0, 3, 0) {
+		yearSeason := common.ToRocSeason(yearSeason.Year(), (int(yearSeason.Month())-1)/3+1)
 
-		os.MkdirAll(fmt.Sprintf("%s/%s", p.workingDir, yearSeason), 0644)
+		os.MkdirAll(fmt.Sprintf("%s/%s", p.workingDir, yearSeason), 0755)
 		zipFilePath := fmt.Sprintf("%s/%s/%s", p.workingDir, yearSeason, storeName)
 
+		if !p.limiter.acquire(p.ctx) {
+			break
+		}
 		p.wg.Add(1)
-		go p.crawl(yearSeason, zipFilePath)
-		break
+		go func() {
+			defer p.limiter.release()
+			p.crawl(yearSeason, zipFilePath)
+		}()
 	}
 
 	p.wg.Wait()
 	p.cancel()
 }
 
-func (p *PlvrCrawler) Stop() {
-	p.logger.Infof("stop crawlering %s", apiUrl)
+// Touched returns the sorted seasons of the transaction dates of the rows inserted or updated so
+// far, e.g., for flagging them only, and whether rows without a valid date were inserted
+func (p *PlvrCrawler) Touched() ([]string, bool) {
+	p.touchedMu.Lock()
+	defer p.touchedMu.Unlock()
+	seasons := []string{}
+	for season := range p.touched {
+		if season != "" {
+			seasons = append(seasons, season)
+		}
+	}
+	sort.Strings(seasons)
+	return seasons, p.touched[""]
 }
 
-func (p *PlvrCrawler) crawl(yearSeason string, zipFilePath string) {
-	recordExists := func(yearSeason string) (bool, error) {
-		var exists bool
-		query := `
-		SELECT EXISTS(SELECT 1 FROM plvr_download_history WHERE remote_addr = $1)
-		`
-		err := p.pool.QueryRow(context.Background(), query, fmt.Sprintf(apiUrl, yearSeason)).Scan(&exists)
-		if err != nil {
-			return false, err
-		}
-		return exists, nil
+func (p *PlvrCrawler) touch(date *time.Time) {
+	season := ""
+	if date != nil {
+		season = common.ToRocSeason(date.Year(), (int(date.Month())-1)/3+1)
 	}
+	p.touchedMu.Lock()
+	p.touched[season] = true
+	p.touchedMu.Unlock()
+}
+
+func (p *PlvrCrawler) Stop() {
+	p.logger.Infow("stop crawlering", "api_url", p.config().ApiUrl)
+}
 
+func (p *PlvrCrawler) crawl(yearSeason string, zipFilePath string) {
 	defer p.wg.Done()
 
+	// the errors are logged along with the fields of the season before being reported
+	l := p.logger.With(logger.FieldSeason, yearSeason)
+	report := func(errData *e.ErrorData) {
+		metrics.SeasonsProcessed.WithLabelValues(metrics.SeasonFailed).Inc()
+		l.Errorw(errData.Message, logger.ErrorFields(errData)...)
+		p.recorder.Error(yearSeason, "", 0, errData)
+		p.ErrorsCh <- errData
+	}
+
 	r := rand.Intn(10)
 	select {
 	case <-p.ctx.Done():
-		p.logger.Debugf("download terminated %s", yearSeason)
+		l.Debug("download terminated")
 		return
 	case <-time.After(time.Duration(r) * time.Second):
-		hasRecord, err := recordExists(yearSeason)
+		started := time.Now()
+		remoteAddr := fmt.Sprintf(p.config().ApiUrl, yearSeason)
+		hasRecord, err := p.history.Imported(context.Background(), remoteAddr)
 		if err != nil {
-			p.ErrorsCh <- e.NewErrorData(
-				CheckRecordExistsError,
-				err.Error(),
-				fmt.Sprintf("%s.download", currentPackage),
-				nil,
-				nil,
-			)
+			report(err)
 			return
 		}
-		if !hasRecord {
-			zipReader, errorData := p.readZipFile(yearSeason, zipFilePath)
-			if errorData != nil {
-				p.ErrorsCh <- errorData
-				return
-			}
-			if err := p.exportZipToDb(zipReader); err != nil {
-				for _, e := range err {
-					p.ErrorsCh <- e
-				}
-				return
+		if hasRecord {
+			metrics.SeasonsProcessed.WithLabelValues(metrics.SeasonSkipped).Inc()
+			l.Debug("season already imported")
+			return
+		}
+		defer func() { metrics.SeasonDuration.Observe(time.Since(started).Seconds()) }()
+		zipReader, errorData := p.readZipFile(l, yearSeason, zipFilePath)
+		if errorData != nil {
+			report(errorData)
+			return
+		}
+		// the errors of the files are logged along with the fields of the file
+		if err := p.exportZipToDb(l, yearSeason, zipReader); err != nil {
+			metrics.SeasonsProcessed.WithLabelValues(metrics.SeasonFailed).Inc()
+			for _, e := range err {
+				p.ErrorsCh <- e
 			}
-			p.ResultsCh <- yearSeason
+			return
 		}
+		metrics.SeasonsProcessed.WithLabelValues(metrics.SeasonImported).Inc()
+		l.Info("season imported")
+		p.markImported(l, yearSeason, remoteAddr)
+		p.ResultsCh <- yearSeason
 	}
 }
 
-func (p *PlvrCrawler) readZipFile(yearSeason string, zipFilePath string) (*zip.Reader, *e.ErrorData) {
+// markImported records the download of a season whose files are all saved, the next runs skip
+// it, a season not over yet is still published to, it is downloaded again by the next runs
+func (p *PlvrCrawler) markImported(l *zap.SugaredLogger, yearSeason string, remoteAddr string) {
+	if _, end, _ := common.RocSeasonToDateRange(yearSeason); end.After(time.Now()) {
+		l.Debug("season not over, not recorded as imported")
+		return
+	}
+	if errData := p.history.MarkImported(context.Background(), remoteAddr); errData != nil {
+		l.Errorw(errData.Message, logger.ErrorFields(errData)...)
+		p.recorder.Error(yearSeason, "", 0, errData)
+	}
+}
+
+func (p *PlvrCrawler) readZipFile(l *zap.SugaredLogger, yearSeason string, zipFilePath string) (*zip.Reader, *e.ErrorData) {
 	fileExists := func(path string) (bool, error) {
 		_, err := os.Stat(path)
 		if err == nil {
@@ -172,48 +247,40 @@ func (p *PlvrCrawler) readZipFile(yearSeason string, zipFilePath string) (*zip.R
 	}
 	exists, _ := fileExists(zipFilePath)
 	if exists {
-		p.logger.Debugf("found downloaded zip file %s", zipFilePath)
+		l.Debugw("found downloaded zip file", logger.FieldFile, zipFilePath)
 		zipBytes, err := os.ReadFile(zipFilePath)
 		if err != nil {
-			return nil, e.NewErrorData(
+			return nil, e.Wrap(
 				ReadZipFileFromLocalError,
-				err.Error(),
+				err,
 				fmt.Sprintf("%s.readZipFile", currentPackage),
-				nil,
-				nil,
 			)
 		}
 		zipReader, err := zip.NewReader(bytes.NewReader(zipBytes), int64(len(zipBytes)))
 		if err != nil {
-			return nil, e.NewErrorData(
+			return nil, e.Wrap(
 				CreateZipReaderError,
-				err.Error(),
+				err,
 				fmt.Sprintf("%s.readZipFile", currentPackage),
-				nil,
-				nil,
 			)
 		}
 		return zipReader, nil
 	} else {
-		remoteZip := fmt.Sprintf(apiUrl, yearSeason)
-		p.logger.Debugf("zip file not found, trying to download it from %s", remoteZip)
-		resp, err := http.Get(remoteZip)
+		remoteZip := fmt.Sprintf(p.config().ApiUrl, yearSeason)
+		l.Debugw("zip file not found, trying to download it", "url", remoteZip)
+		body, status, err := p.client.Get(p.ctx, remoteZip)
 		if err != nil {
-			return nil, e.NewErrorData(
+			return nil, e.Wrap(
 				HttpRequestError,
-				err.Error(),
+				err,
 				fmt.Sprintf("%s.readZipFile", currentPackage),
-				nil,
-				nil,
 			)
 		}
-		defer resp.Body.Close()
-		body, err := io.ReadAll(resp.Body)
-		if resp.StatusCode != 200 {
-			var inner interface{} = fmt.Sprintf("[%d] body %s", resp.StatusCode, string(body))
+		if status != 200 {
+			var inner interface{} = fmt.Sprintf("[%d] body %s", status, string(body))
 			return nil, e.NewErrorData(
 				HttpStatusError,
-				err.Error(),
+				fmt.Sprintf("unexpected http status %d of %s", status, remoteZip),
 				fmt.Sprintf("%s.readZipFile", currentPackage),
 				nil,
 				&inner,
@@ -221,53 +288,59 @@ func (p *PlvrCrawler) readZipFile(yearSeason string, zipFilePath string) (*zip.R
 		}
 		zipReader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
 		if err != nil {
-			return nil, e.NewErrorData(
+			return nil, e.Wrap(
 				CopyZipContentToFileError,
-				err.Error(),
+				err,
 				fmt.Sprintf("%s.readZipFile", currentPackage),
-				nil,
-				nil,
 			)
 		}
+		// keeps the download for the next runs, the import does not depend on it
+		if err := os.WriteFile(zipFilePath, body, 0644); err != nil {
+			l.Warnw("unable to store the zip file", logger.FieldFile, zipFilePath, "error", err.Error())
+		}
 		return zipReader, nil
 	}
 }
 
-func (p *PlvrCrawler) exportZipToDb(zip *zip.Reader) []*e.ErrorData {
+func (p *PlvrCrawler) exportZipToDb(l *zap.SugaredLogger, yearSeason string, zip *zip.Reader) []*e.ErrorData {
 	var errors []*e.ErrorData
 	for _, zf := range zip.File {
 		fileName := zf.FileHeader.Name
-		if !isTargetFile.MatchString(fileName) {
-			p.logger.Debugf("file %s is omitted", fileName)
+		if !isTargetFile.MatchString(fileName) || !p.selected(fileName) {
+			l.Debugw("file omitted", logger.FieldFile, fileName)
 			continue
 		}
+		l := l.With(logger.FieldCity, fileName[:1], logger.FieldFile, fileName)
+		fail := func(errData *e.ErrorData) {
+			l.Errorw(errData.Message, logger.ErrorFields(errData)...)
+			// the rejected rows of a SaveRowsError are already recorded one by one
+			if errData.Code != SaveRowsError {
+				p.recorder.Error(yearSeason, fileName, 0, errData)
+			}
+			errors = append(errors, errData)
+		}
 		f, err := zf.Open()
 		if err != nil {
-			errors = append(errors, e.NewErrorData(
+			fail(e.Wrap(
 				OpenZippedFileError,
-				err.Error(),
+				err,
 				fmt.Sprintf("%s.exportZipToDb", currentPackage),
-				nil,
-				nil,
 			))
 			return errors
 		}
 		defer f.Close()
-		p.logger.Debugf("Opened file %s", fileName)
+		l.Debug("file opened")
 		content, err := io.ReadAll(f)
 		if err != nil {
-			errors = append(errors, e.NewErrorData(
+			fail(e.Wrap(
 				ReadZippedFileError,
-				err.Error(),
+				err,
 				fmt.Sprintf("%s.exportZipToDb", currentPackage),
-				nil,
-				nil,
 			))
 			return errors
 		}
-		errData := p.parseAndSave(fileName, content)
-		if errData != nil {
-			errors = append(errors, errData)
+		if errData := p.parseAndSave(l, yearSeason, fileName, content); errData != nil {
+			fail(errData)
 		}
 	}
 	if len(errors) > 0 {
@@ -276,33 +349,133 @@ func (p *PlvrCrawler) exportZipToDb(zip *zip.Reader) []*e.ErrorData {
 	return nil
 }
 
-func (p *PlvrCrawler) parseAndSave(fileName string, content []byte) *e.ErrorData {
-	if isHouseSale.MatchString(fileName) {
+// family returns the file family of a target file, e.g., house_sale of a_lvr_land_a.csv
+func family(fileName string) string {
+	suffix := fileName[len(fileName)-5 : len(fileName)-4]
+	for name, f := range config.Families {
+		if f == suffix {
+			return name
+		}
+	}
+	return suffix
+}
+
+// selected tells whether the city and the family of the file are configured to be imported
+func (p *PlvrCrawler) selected(fileName string) bool {
+	city, family := fileName[:1], fileName[len(fileName)-5:len(fileName)-4]
+	cfg := p.config()
+	cityOk := len(cfg.Cities) == 0
+	for _, c := range cfg.Cities {
+		cityOk = cityOk || c == city
+	}
+	familyOk := false
+	for _, f := range cfg.Families {
+		familyOk = familyOk || config.Families[f] == family
+	}
+	return cityOk && familyOk
+}
+
+func (p *PlvrCrawler) parseAndSave(l *zap.SugaredLogger, yearSeason string, fileName string, content []byte) *e.ErrorData {
+	started := time.Now()
+	defer func() {
+		metrics.IngestDuration.WithLabelValues(family(fileName)).Observe(time.Since(started).Seconds())
+	}()
+	switch {
+	case isHouseSale.MatchString(fileName):
 		items, err := NewHouseSaleItems(content)
 		if err != nil {
 			return err
 		}
-		for _, item := range items {
-			item.save(p.pool, string(fileName[0]))
-		}
-	}
-	if isNewHouse.MatchString(fileName) {
+		return importRows(p, l, yearSeason, fileName, store.HouseSaleTable, items)
+	case isNewHouse.MatchString(fileName):
 		items, err := NewNewHouseItems(content)
 		if err != nil {
 			return err
 		}
-		for _, item := range items {
-			item.save(p.pool, string(fileName[0]))
-		}
-	}
-	if isRental.MatchString(fileName) {
+		return importRows(p, l, yearSeason, fileName, store.NewHouseTable, items)
+	case isRental.MatchString(fileName):
 		items, err := NewRentalItems(content)
 		if err != nil {
 			return err
 		}
-		for _, item := range items {
-			item.save(p.pool, string(fileName[0]))
+		return importRows(p, l, yearSeason, fileName, store.RentalTable, items)
+	}
+	return nil
+}
+
+// plvrRow is a parsed row of any of the file families
+type plvrRow interface {
+	dateAndDistrict() (string, string)
+	save(ctx context.Context, s store.TransactionStore, ref *reference.Reference, city string) (store.SaveResult, *e.ErrorData)
+}
+
+// importRows prepares the partitions of the transaction years of rows in table, then saves them
+func importRows[T plvrRow](p *PlvrCrawler, l *zap.SugaredLogger, yearSeason string, fileName string, table string, rows []T) *e.ErrorData {
+	city := fileName[:1]
+	dates, districts := make([]string, len(rows)), make([]string, len(rows))
+	for i, row := range rows {
+		dates[i], districts[i] = row.dateAndDistrict()
+	}
+	if err := p.transactions.Prepare(p.ctx, table, years(dates)); err != nil {
+		return err
+	}
+	return p.saveRows(l, yearSeason, fileName, dates, districts, func(i int) (store.SaveResult, *e.ErrorData) {
+		return rows[i].save(context.Background(), p.transactions, p.ref, city)
+	})
+}
+
+// saveRows saves the rows of a file and records their counts, each failure is logged and
+// recorded with its row, i.e., the line of the csv file after the chinese and the english
+// headers, and the file fails when any row does, the rows of districts unknown on their
+// transaction date, neither current nor a former name, are saved and recorded as warnings
+func (p *PlvrCrawler) saveRows(l *zap.SugaredLogger, yearSeason string, fileName string, dates []string, districts []string, save func(i int) (store.SaveResult, *e.ErrorData)) *e.ErrorData {
+	n, city := len(districts), fileName[:1]
+	rows := report.Rows{Read: n}
+	for i := 0; i < n; i++ {
+		date, _ := common.RocEraToCommonEra(dates[i])
+		if !p.ref.KnownDistrict(city, districts[i], date) {
+			warning := e.NewErrorData(
+				UnknownDistrictError,
+				fmt.Sprintf("unknown district %q of %s", districts[i], p.ref.CityName(city)),
+				fmt.Sprintf("%s.saveRows", currentPackage),
+				nil,
+				nil,
+			)
+			l.Warnw(warning.Message, append([]interface{}{logger.FieldRow, i + 3}, logger.ErrorFields(warning)...)...)
+			p.recorder.Error(yearSeason, fileName, i+3, warning)
+		}
+		result, errData := save(i)
+		switch {
+		case errData != nil:
+			rows.Rejected++
+			l.Errorw(errData.Message, append([]interface{}{logger.FieldRow, i + 3}, logger.ErrorFields(errData)...)...)
+			p.recorder.Error(yearSeason, fileName, i+3, errData)
+		case result == store.Inserted:
+			rows.Inserted++
+			p.touch(date)
+		case result == store.Updated:
+			rows.Updated++
+			p.touch(date)
+		default:
+			rows.Skipped++
 		}
 	}
+	p.recorder.File(yearSeason, fileName, city, rows)
+	f := family(fileName)
+	metrics.RowsParsed.WithLabelValues(f, city).Add(float64(rows.Read))
+	metrics.RowsSaved.WithLabelValues(f, city, metrics.RowInserted).Add(float64(rows.Inserted))
+	metrics.RowsSaved.WithLabelValues(f, city, metrics.RowUpdated).Add(float64(rows.Updated))
+	metrics.RowsSaved.WithLabelValues(f, city, metrics.RowSkipped).Add(float64(rows.Skipped))
+	metrics.RowsSaved.WithLabelValues(f, city, metrics.RowRejected).Add(float64(rows.Rejected))
+	if rows.Rejected > 0 {
+		return e.NewErrorData(
+			SaveRowsError,
+			fmt.Sprintf("%d of %d rows of %s not saved", rows.Rejected, n, fileName),
+			fmt.Sprintf("%s.saveRows", currentPackage),
+			nil,
+			nil,
+		)
+	}
+	l.Debugw("file saved", "inserted", rows.Inserted, "updated", rows.Updated, "skipped", rows.Skipped)
 	return nil
 }
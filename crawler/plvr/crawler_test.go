@@ -0,0 +1,178 @@
+package plvr
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/common"
+	"github.com/Walker088/gorealestate/config"
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/reference"
+	"github.com/Walker088/gorealestate/report"
+	"github.com/Walker088/gorealestate/store"
+)
+
+const testSeason = "104S1"
+
+// rejectingStore fails the save of the rows of serial
+type rejectingStore struct {
+	*store.Memory
+	serial string
+}
+
+func (s *rejectingStore) Save(ctx context.Context, t store.Transaction) (store.SaveResult, *e.ErrorData) {
+	if t.Values[0] == s.serial {
+		return store.Unchanged, e.NewErrorData(store.InsertTransactionError, "rejected", "rejectingStore.Save", nil, nil)
+	}
+	return s.Memory.Save(ctx, t)
+}
+
+// csvFile renders rows under the chinese and the english headers of a plvr file
+func csvFile(rows ...string) string {
+	return strings.Join(append([]string{
+		"編號,鄉鎮市區,土地位置建物門牌,交易年月日,建物移轉總面積平方公尺,總價元",
+		"serial number,district,address,transaction date,building area,total price",
+	}, rows...), "\n")
+}
+
+func zipOf(t *testing.T, files map[string]string) *zip.Reader {
+	t.Helper()
+	buf := &bytes.Buffer{}
+	w := zip.NewWriter(buf)
+	for name, content := range files {
+		f, err := w.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		f.Write([]byte(content))
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	r, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return r
+}
+
+// newTestCrawler crawls h, 桃園市, whose 中壢市 became 中壢區 on 2014-12-25
+func newTestCrawler(transactions store.TransactionStore) (*PlvrCrawler, *report.Recorder) {
+	ref := reference.New(
+		[]reference.City{{Code: "h", NameEn: "Taoyuan City", NameZh: "桃園市", Districts: []reference.District{
+			{NameZh: "中壢區", NameEn: "Zhongli District"},
+		}}},
+		[]reference.FormerDistrict{
+			{City: "h", NameZh: "中壢市", CurrentCity: "h", CurrentDistrict: "中壢區", Changed: time.Date(2014, 12, 25, 0, 0, 0, 0, time.UTC)},
+		},
+	)
+	cfg := &config.CrawlerConfig{Families: []string{"house_sale", "rental"}, Concurrency: 1}
+	recorder := report.NewRecorder("test")
+	ctx, cancel := context.WithCancel(context.Background())
+	return New(ctx, cancel, ".", cfg, nil, recorder, ref, zap.NewNop().Sugar(), transactions, store.NewMemory()), recorder
+}
+
+func errorCounts(run *report.Run) map[string]int {
+	counts := map[string]int{}
+	for _, err := range run.Errors {
+		counts[fmt.Sprintf("%s %s", err.File, err.Code)] += err.Count
+	}
+	return counts
+}
+
+func TestExportZipToDb(t *testing.T) {
+	mem := store.NewMemory()
+	p, recorder := newTestCrawler(mem)
+	zr := zipOf(t, map[string]string{
+		"h_lvr_land_a.csv": csvFile(
+			"A1,中壢區,桃園市中壢區中正路1號,1040105,50,5000000",
+			"A1,中壢區,桃園市中壢區中正路1號,1040105,50,5000000",
+			"A2,中壢區,桃園市中壢區中正路1號,1040105,50,5000000",
+			"A3,中壢市,桃園市中壢市中正路2號,1031224,50,5000000",
+			"A4,中壢市,桃園市中壢市中正路3號,1040105,50,5000000",
+			"A5,中壢區,桃園市中壢區中正路4號,,50,5000000",
+			"A6,中壢區,桃園市中壢區中正路4號,,50,5000000",
+			"A5,中壢區,桃園市中壢區中正路4號,,50,5000000",
+			"A1,中壢區,桃園市中壢區中正路1號,1040105,50,5100000",
+		),
+		"h_lvr_land_b.csv": csvFile("B1,中壢區,桃園市中壢區中正路5號,1040105,50,5000000"),
+	})
+
+	if errs := p.exportZipToDb(zap.NewNop().Sugar(), testSeason, zr); errs != nil {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+
+	run := recorder.Run(report.StatusFinished)
+	// the second A1 is a duplicate and the last one corrects its price, A2 is another sale of
+	// the same address, date and price, A5 and A6 have no date, the second A5 is a duplicate too
+	want := report.Rows{Read: 9, Inserted: 6, Updated: 1, Skipped: 2}
+	if len(run.Files) != 1 || run.Files[0].File != "h_lvr_land_a.csv" || run.Files[0].Rows != want {
+		t.Fatalf("expected the rows %+v of h_lvr_land_a.csv only, got %+v", want, run.Files)
+	}
+	if n := len(mem.Transactions(store.HouseSaleTable)); n != 6 {
+		t.Errorf("expected 6 transactions, got %d", n)
+	}
+	if n := len(mem.Transactions(store.NewHouseTable)); n != 0 {
+		t.Errorf("expected the new houses to be omitted, got %d transactions", n)
+	}
+	if years := mem.Years(store.HouseSaleTable); !reflect.DeepEqual(years, []int{2014, 2015}) {
+		t.Errorf("expected the years 2014 and 2015 to be prepared, got %v", years)
+	}
+	if seasons, undated := p.Touched(); !reflect.DeepEqual(seasons, []string{"103S4", "104S1"}) || !undated {
+		t.Errorf("expected the seasons 103S4 and 104S1 and undated rows to be touched, got %v %v", seasons, undated)
+	}
+	// 中壢市 is accepted before its change only, A4 is the row 7 of the file
+	if len(run.Errors) != 1 || run.Errors[0].Code != UnknownDistrictError || run.Errors[0].Count != 1 || run.Errors[0].Samples[0].Row != 7 {
+		t.Errorf("expected a single %s on row 7, got %+v", UnknownDistrictError, run.Errors)
+	}
+}
+
+func TestExportZipToDbRejectedRows(t *testing.T) {
+	p, recorder := newTestCrawler(&rejectingStore{Memory: store.NewMemory(), serial: "R2"})
+	zr := zipOf(t, map[string]string{
+		"h_lvr_land_c.csv": csvFile(
+			"R1,中壢區,桃園市中壢區中正路1號,1040105,50,20000",
+			"R2,中壢區,桃園市中壢區中正路2號,1040105,50,20000",
+		),
+	})
+
+	errs := p.exportZipToDb(zap.NewNop().Sugar(), testSeason, zr)
+	if len(errs) != 1 || errs[0].Code != SaveRowsError {
+		t.Fatalf("expected a single %s, got %v", SaveRowsError, errs)
+	}
+
+	run := recorder.Run(report.StatusFinished)
+	want := report.Rows{Read: 2, Inserted: 1, Rejected: 1}
+	if run.Rows != want {
+		t.Errorf("expected the rows %+v, got %+v", want, run.Rows)
+	}
+	// the rejected row is recorded once, the failure of the file is not recorded on top of it
+	counts := errorCounts(run)
+	if !reflect.DeepEqual(counts, map[string]int{"h_lvr_land_c.csv " + DbInsertionError: 1}) {
+		t.Errorf("expected a single %s, got %v", DbInsertionError, counts)
+	}
+}
+
+func TestMarkImported(t *testing.T) {
+	p, _ := newTestCrawler(store.NewMemory())
+	now := time.Now()
+	current := common.ToRocSeason(now.Year(), (int(now.Month())-1)/3+1)
+
+	p.markImported(zap.NewNop().Sugar(), testSeason, "past")
+	p.markImported(zap.NewNop().Sugar(), current, "current")
+
+	// the current season is still published to, it is downloaded again by the next runs
+	for addr, want := range map[string]bool{"past": true, "current": false} {
+		if imported, _ := p.history.Imported(context.Background(), addr); imported != want {
+			t.Errorf("expected %s to be imported %v, got %v", addr, want, imported)
+		}
+	}
+}
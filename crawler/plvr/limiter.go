@@ -0,0 +1,61 @@
+package plvr
+
+import (
+	"context"
+	"sync"
+)
+
+// limiter bounds the seasons crawled at once, unlike a buffered channel its limit can be
+// changed while running, a lower limit lets the running seasons finish
+type limiter struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	limit  int
+	active int
+}
+
+func newLimiter(limit int) *limiter {
+	l := &limiter{limit: limit}
+	l.cond = sync.NewCond(&l.mu)
+	return l
+}
+
+// acquire blocks until a slot is free, false is returned when ctx is done
+func (l *limiter) acquire(ctx context.Context) bool {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.cond.Broadcast()
+		case <-done:
+		}
+	}()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for l.active >= l.limit && ctx.Err() == nil {
+		l.cond.Wait()
+	}
+	if ctx.Err() != nil {
+		return false
+	}
+	l.active++
+	return true
+}
+
+func (l *limiter) release() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.active--
+	l.cond.Broadcast()
+}
+
+func (l *limiter) setLimit(limit int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.limit = limit
+	l.cond.Broadcast()
+}
@@ -0,0 +1,161 @@
+package plvr
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Walker088/gorealestate/config"
+)
+
+// holders acquire l in n goroutines and hold their slot until release is closed, the most
+// slots held at once are reported by peak
+type holders struct {
+	l       *limiter
+	mu      sync.Mutex
+	held    int
+	peak    int
+	release chan struct{}
+	wg      sync.WaitGroup
+}
+
+func startHolders(ctx context.Context, l *limiter, n int) *holders {
+	h := &holders{l: l, release: make(chan struct{})}
+	for i := 0; i < n; i++ {
+		h.wg.Add(1)
+		go func() {
+			defer h.wg.Done()
+			if !l.acquire(ctx) {
+				return
+			}
+			h.mu.Lock()
+			h.held++
+			if h.held > h.peak {
+				h.peak = h.held
+			}
+			h.mu.Unlock()
+			<-h.release
+			h.mu.Lock()
+			h.held--
+			h.mu.Unlock()
+			l.release()
+		}()
+	}
+	return h
+}
+
+func (h *holders) count() (held int, peak int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.held, h.peak
+}
+
+// waitHeld waits for n slots to be held, the count is returned when it differs after a while
+func (h *holders) waitHeld(n int) int {
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		held, _ := h.count()
+		if held == n || time.Now().After(deadline) {
+			return held
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestLimiterSetLimit(t *testing.T) {
+	l := newLimiter(1)
+	h := startHolders(context.Background(), l, 5)
+	if held := h.waitHeld(1); held != 1 {
+		t.Fatalf("expected 1 slot held, got %d", held)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, peak := h.count(); peak != 1 {
+		t.Errorf("expected at most 1 slot held at once, got %d", peak)
+	}
+
+	// a higher limit wakes the waiting ones
+	l.setLimit(3)
+	if held := h.waitHeld(3); held != 3 {
+		t.Fatalf("expected 3 slots held after raising the limit, got %d", held)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if _, peak := h.count(); peak != 3 {
+		t.Errorf("expected at most 3 slots held at once, got %d", peak)
+	}
+	close(h.release)
+	h.wg.Wait()
+
+	// a lower limit lets the running ones finish and holds the next ones
+	l = newLimiter(3)
+	h = startHolders(context.Background(), l, 3)
+	if held := h.waitHeld(3); held != 3 {
+		t.Fatalf("expected 3 slots held, got %d", held)
+	}
+	l.setLimit(1)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if l.acquire(ctx) {
+		t.Error("expected no slot while the running ones exceed the lower limit")
+	}
+	close(h.release)
+	h.wg.Wait()
+	if !l.acquire(context.Background()) {
+		t.Error("expected a slot once the running ones are done")
+	}
+}
+
+func TestLimiterCancel(t *testing.T) {
+	l := newLimiter(1)
+	if !l.acquire(context.Background()) {
+		t.Fatal("expected a free slot")
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan bool)
+	go func() { done <- l.acquire(ctx) }()
+	cancel()
+	select {
+	case ok := <-done:
+		if ok {
+			t.Error("expected the acquire of a canceled context to fail")
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("expected the acquire to return once canceled")
+	}
+}
+
+// TestReloadWhileRunning reloads the crawler while its seasons hold and release the limiter, as
+// the config watcher does, go test -race checks the reload against the running seasons
+func TestReloadWhileRunning(t *testing.T) {
+	p, _ := newTestCrawler(nil)
+	h := startHolders(p.ctx, p.limiter, 6)
+	if held := h.waitHeld(1); held != 1 {
+		t.Fatalf("expected the concurrency 1 of the config, got %d slots held", held)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			_ = p.config().ApiUrl
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 1; i <= 4; i++ {
+			p.Reload(&config.CrawlerConfig{Concurrency: i, ApiUrl: "https://example.com/%s"})
+		}
+	}()
+	wg.Wait()
+
+	if held := h.waitHeld(4); held != 4 {
+		t.Errorf("expected the reloaded concurrency 4 to take effect, got %d slots held", held)
+	}
+	if cfg := p.config(); cfg.Concurrency != 4 || cfg.ApiUrl != "https://example.com/%s" {
+		t.Errorf("expected the reloaded config, got %+v", cfg)
+	}
+	close(h.release)
+	h.wg.Wait()
+	p.cancel()
+}
@@ -5,27 +5,40 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/Walker088/gorealestate/address"
 	"github.com/Walker088/gorealestate/common"
 	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/reference"
+	"github.com/Walker088/gorealestate/store"
 	"github.com/gocarina/gocsv"
-	"github.com/jackc/pgx/v5/pgxpool"
 )
 
 var (
-	DbInsertionError      = "PS00001"
-	RocEraFormattingError = "PS00002"
+	DbInsertionError = "PS00001"
 
-	cityMap = map[string]string{
-		"a": "Taipei", "b": "Taichung", "c": "Keelung", "d": "Tainan",
-		"e": "Kaohsiung", "f": "New Taipei", "g": "Yilan", "h": "Taoyuan",
-		"j": "Hsinchu Country", "k": "Miaoli", "l": "Taichung Country", "m": "Nantou",
-		"n": "Changhua", "p": "Yunlin", "q": "Chiayi Country", "r": "Tainan County",
-		"s": "Kaohsiung County", "t": "Pingtung", "u": "Hualien", "v": "Taitung",
-		"x": "Penghu", "y": "Yangmingshan", "w": "Kinmen", "z": "Lianjiang",
-		"i": "Chiayi", "o": "Hsinchu",
+	// the columns of the new house and rental tables, house sales add a few
+	baseColumns = []string{
+		"serial_number", "city", "district", "transaction_type", "address", "land_shifting_area_sqm",
+		"urban_land_use", "non_urban_land_use", "non_urban_land_designation", "transaction_date_raw", "transaction_date", "transaction_pen_number",
+		"floor", "total_floor", "building_type", "primary_use", "primary_material",
+		"construction_complete_date_raw", "construction_complete_date", "building_area_sqm", "number_of_rooms", "number_of_living_rooms",
+		"number_of_bathrooms", "partitioned", "has_management_organization", "total_price", "unit_price_per_sqm",
+		"parking_type", "parking_area_sqm", "parking_price", "notes",
 	}
+	transactionColumns = columns(baseColumns, address.ColumnNames)
+	houseSaleColumns   = columns(baseColumns, []string{
+		"main_building_area_sqm", "subsidiary_building_area_sqm", "balcony_area_sqm", "elevator", "transaction_identifier",
+	}, address.ColumnNames)
 )
 
+func columns(groups ...[]string) []string {
+	cols := []string{}
+	for _, g := range groups {
+		cols = append(cols, g...)
+	}
+	return cols
+}
+
 type HouseSaleItem struct {
 	SerialNumber                string `csv:"編號"`
 	District                    string `csv:"鄉鎮市區" db:"district"`
@@ -70,65 +83,56 @@ func NewHouseSaleItems(csvBytes []byte) ([]HouseSaleItem, *e.ErrorData) {
 	items := []HouseSaleItem{}
 
 	if err := gocsv.UnmarshalBytes([]byte(cleaned), &items); err != nil {
-		return nil, e.NewErrorData(
+		return nil, e.Wrap(
 			UnmarshalCsvError,
-			err.Error(),
+			err,
 			fmt.Sprintf("%s.parse", currentPackage),
-			nil,
-			nil,
 		)
 	}
 	return items, nil
 }
-func (h HouseSaleItem) save(pool *pgxpool.Pool, city string) *e.ErrorData {
-	query := `
-	INSERT INTO plvr_land_house_sale (
-		serial_number, city, district, transaction_type, address, land_shifting_area_sqm,
-		urban_land_use, non_urban_land_use, non_urban_land_designation, transaction_date_raw, transaction_date, transaction_pen_number,
-		floor, total_floor, building_type, primary_use, primary_material, 
-		construction_complete_date_raw, construction_complete_date, building_area_sqm, number_of_rooms, number_of_living_rooms, 
-		number_of_bathrooms, partitioned, has_management_organization, total_price, unit_price_per_sqm, 
-		parking_type, parking_area_sqm, parking_price, notes, main_building_area_sqm, 
-		subsidiary_building_area_sqm, balcony_area_sqm, elevator, transaction_identifier
-	)
-	VALUES (
-		$1, $2, $3, $4, $5, $6, 
-		$7, $8, $9, $10, $11, $12,
-		$13, $14, $15, $16, $17, 
-		$18, $19, $20, $21, $22, 
-		$23, $24, $25, $26, $27,
-		$28, $29, $30, $31, $32,
-		$33, $34, $35, $36
-	);
-	`
+
+// transaction maps the row to the columns of HouseSaleTable
+func (h HouseSaleItem) transaction(city string) store.Transaction {
 	transacDate, _ := common.RocEraToCommonEra(h.TransactionDateRaw)
 	constructDate, _ := common.RocEraToCommonEra(h.ConstructionCompleteDateRaw)
-	if _, err := pool.Exec(
-		context.Background(),
-		query,
-		h.SerialNumber, city, h.District, h.TransactionType, h.Address, h.LandShiftingArea,
-		h.UrbanLandUse, h.NonUrbanLandUse, h.NonUrbanLandDesignation, h.TransactionDateRaw, transacDate, h.TransactionPenNumber,
-		h.Floor, h.TotalFloor, h.BuildingType, h.PrimaryUse, h.PrimaryMaterial,
-		h.ConstructionCompleteDateRaw, constructDate, h.BuildingAreaSqm, h.NumberOfRooms, h.NumberOfLivingRooms,
-		h.NumberOfBathrooms, h.Partitioned, h.HasManagementOrganization, h.TotalPrice, h.UnitPrice,
-		h.ParkingType, h.ParkingArea, h.ParkingPrice, h.Notes, h.MainBuildingArea,
-		h.SubsidiaryBuildingArea, h.BalconyArea, h.Elevator, h.TransactionIdentifier,
-	); err != nil {
-		return e.NewErrorData(
+	return store.Transaction{
+		Table:   store.HouseSaleTable,
+		Columns: houseSaleColumns,
+		Values: append([]any{
+			h.SerialNumber, city, h.District, h.TransactionType, h.Address, h.LandShiftingArea,
+			h.UrbanLandUse, h.NonUrbanLandUse, h.NonUrbanLandDesignation, h.TransactionDateRaw, transacDate, h.TransactionPenNumber,
+			h.Floor, h.TotalFloor, h.BuildingType, h.PrimaryUse, h.PrimaryMaterial,
+			h.ConstructionCompleteDateRaw, constructDate, h.BuildingAreaSqm, h.NumberOfRooms, h.NumberOfLivingRooms,
+			h.NumberOfBathrooms, h.Partitioned, h.HasManagementOrganization, h.TotalPrice, h.UnitPrice,
+			h.ParkingType, h.ParkingArea, h.ParkingPrice, h.Notes,
+			h.MainBuildingArea, h.SubsidiaryBuildingArea, h.BalconyArea, h.Elevator, h.TransactionIdentifier,
+		}, address.Parse(h.Address, h.District).Columns()...),
+	}
+}
+
+func (h HouseSaleItem) dateAndDistrict() (string, string) {
+	return h.TransactionDateRaw, h.District
+}
+
+// save inserts the row, or updates it when it is already imported with other values
+func (h HouseSaleItem) save(ctx context.Context, s store.TransactionStore, ref *reference.Reference, city string) (store.SaveResult, *e.ErrorData) {
+	result, errData := s.Save(ctx, h.transaction(city))
+	if errData != nil {
+		return store.Unchanged, e.NewErrorData(
 			DbInsertionError,
-			fmt.Sprintf("Error: %s on %s", err.Error(), h.toString(city)),
+			fmt.Sprintf("Error: %s on %s", errData.Message, h.toString(ref.CityName(city))),
 			fmt.Sprintf("%s.HouseSaleItem.save", currentPackage),
 			nil,
 			nil,
-		)
+		).WithCause(errData)
 	}
-	return nil
+	return result, nil
 }
-func (h HouseSaleItem) toString(cityCode string) string {
-	city := cityMap[cityCode]
+func (h HouseSaleItem) toString(cityName string) string {
 	return fmt.Sprintf(`
 	[HouseSaleItem] [%s] City=%s District=%s TransacType=%s TransacDate=%s
-	`, h.SerialNumber, city, h.District, h.TransactionType, h.TransactionDateRaw)
+	`, h.SerialNumber, cityName, h.District, h.TransactionType, h.TransactionDateRaw)
 }
 
 type NewHouseItem struct {
@@ -170,62 +174,55 @@ func NewNewHouseItems(csvBytes []byte) ([]NewHouseItem, *e.ErrorData) {
 	items := []NewHouseItem{}
 
 	if err := gocsv.UnmarshalBytes([]byte(cleaned), &items); err != nil {
-		return nil, e.NewErrorData(
+		return nil, e.Wrap(
 			UnmarshalCsvError,
-			err.Error(),
+			err,
 			fmt.Sprintf("%s.parse", currentPackage),
-			nil,
-			nil,
 		)
 	}
 	return items, nil
 }
-func (n NewHouseItem) save(pool *pgxpool.Pool, city string) *e.ErrorData {
-	query := `
-	INSERT INTO plvr_land_new_house (
-		serial_number, city, district, transaction_type, address, land_shifting_area_sqm,
-		urban_land_use, non_urban_land_use, non_urban_land_designation, transaction_date_raw, transaction_date, transaction_pen_number,
-		floor, total_floor, building_type, primary_use, primary_material, 
-		construction_complete_date_raw, construction_complete_date, building_area_sqm, number_of_rooms, number_of_living_rooms, 
-		number_of_bathrooms, partitioned, has_management_organization, total_price, unit_price_per_sqm, 
-		parking_type, parking_area_sqm, parking_price, notes
-	)
-	VALUES (
-		$1, $2, $3, $4, $5, $6, 
-		$7, $8, $9, $10, $11, $12, 
-		$13, $14, $15, $16, $17,
-		$18, $19, $20, $21, $22,
-		$23, $24, $25, $26, $27,
-		$28, $29, $30, $31
-	);
-	`
+
+// transaction maps the row to the columns of NewHouseTable
+func (n NewHouseItem) transaction(city string) store.Transaction {
 	transacDate, _ := common.RocEraToCommonEra(n.TransactionDateRaw)
 	constructDate, _ := common.RocEraToCommonEra(n.ConstructionCompleteDateRaw)
-	if _, err := pool.Exec(
-		context.Background(),
-		query,
-		n.SerialNumber, city, n.District, n.TransactionType, n.Address, n.LandShiftingArea,
-		n.UrbanLandUse, n.NonUrbanLandUse, n.NonUrbanLandDesignation, n.TransactionDateRaw, transacDate, n.TransactionPenNumber,
-		n.Floor, n.TotalFloor, n.BuildingType, n.PrimaryUse, n.PrimaryMaterial,
-		n.ConstructionCompleteDateRaw, constructDate, n.BuildingAreaSqm, n.NumberOfRooms, n.NumberOfLivingRooms,
-		n.NumberOfBathrooms, n.Partitioned, n.HasManagementOrganization, n.TotalPrice, n.UnitPrice,
-		n.ParkingType, n.ParkingArea, n.ParkingPrice, n.Notes,
-	); err != nil {
-		return e.NewErrorData(
+	return store.Transaction{
+		Table:   store.NewHouseTable,
+		Columns: transactionColumns,
+		Values: append([]any{
+			n.SerialNumber, city, n.District, n.TransactionType, n.Address, n.LandShiftingArea,
+			n.UrbanLandUse, n.NonUrbanLandUse, n.NonUrbanLandDesignation, n.TransactionDateRaw, transacDate, n.TransactionPenNumber,
+			n.Floor, n.TotalFloor, n.BuildingType, n.PrimaryUse, n.PrimaryMaterial,
+			n.ConstructionCompleteDateRaw, constructDate, n.BuildingAreaSqm, n.NumberOfRooms, n.NumberOfLivingRooms,
+			n.NumberOfBathrooms, n.Partitioned, n.HasManagementOrganization, n.TotalPrice, n.UnitPrice,
+			n.ParkingType, n.ParkingArea, n.ParkingPrice, n.Notes,
+		}, address.Parse(n.Address, n.District).Columns()...),
+	}
+}
+
+func (n NewHouseItem) dateAndDistrict() (string, string) {
+	return n.TransactionDateRaw, n.District
+}
+
+// save inserts the row, or updates it when it is already imported with other values
+func (n NewHouseItem) save(ctx context.Context, s store.TransactionStore, ref *reference.Reference, city string) (store.SaveResult, *e.ErrorData) {
+	result, errData := s.Save(ctx, n.transaction(city))
+	if errData != nil {
+		return store.Unchanged, e.NewErrorData(
 			DbInsertionError,
-			fmt.Sprintf("Error: %s on %s", err.Error(), n.toString(city)),
+			fmt.Sprintf("Error: %s on %s", errData.Message, n.toString(ref.CityName(city))),
 			fmt.Sprintf("%s.NewHouseItem.save", currentPackage),
 			nil,
 			nil,
-		)
+		).WithCause(errData)
 	}
-	return nil
+	return result, nil
 }
-func (n NewHouseItem) toString(cityCode string) string {
-	city := cityMap[cityCode]
+func (n NewHouseItem) toString(cityName string) string {
 	return fmt.Sprintf(`
 	[NewHouseItem] [%s] City=%s District=%s TransacType=%s TransacDate=%s
-	`, n.SerialNumber, city, n.District, n.TransactionType, n.TransactionDateRaw)
+	`, n.SerialNumber, cityName, n.District, n.TransactionType, n.TransactionDateRaw)
 }
 
 type RentalItem struct {
@@ -268,60 +265,53 @@ func NewRentalItems(csvBytes []byte) ([]RentalItem, *e.ErrorData) {
 	items := []RentalItem{}
 
 	if err := gocsv.UnmarshalBytes([]byte(cleaned), &items); err != nil {
-		return nil, e.NewErrorData(
+		return nil, e.Wrap(
 			UnmarshalCsvError,
-			err.Error(),
+			err,
 			fmt.Sprintf("%s.parse", currentPackage),
-			nil,
-			nil,
 		)
 	}
 	return items, nil
 }
-func (r RentalItem) save(pool *pgxpool.Pool, city string) *e.ErrorData {
-	query := `
-	INSERT INTO plvr_land_rental (
-		serial_number, city, district, transaction_type, address, land_shifting_area_sqm,
-		urban_land_use, non_urban_land_use, non_urban_land_designation, transaction_date_raw, transaction_date, transaction_pen_number,
-		floor, total_floor, building_type, primary_use, primary_material, 
-		construction_complete_date_raw, construction_complete_date, building_area_sqm, number_of_rooms, number_of_living_rooms, 
-		number_of_bathrooms, partitioned, has_management_organization, total_price, unit_price_per_sqm, 
-		parking_type, parking_area_sqm, parking_price, notes
-	)
-	VALUES (
-		$1, $2, $3, $4, $5, $6, 
-		$7, $8, $9, $10, $11, $12,
-		$13, $14, $15, $16, $17,
-		$18, $19, $20, $21, $22,
-		$23, $24, $25, $26, $27,
-		$28, $29, $30, $31
-	);
-	`
+
+// transaction maps the row to the columns of RentalTable
+func (r RentalItem) transaction(city string) store.Transaction {
 	transacDate, _ := common.RocEraToCommonEra(r.TransactionDateRaw)
 	constructDate, _ := common.RocEraToCommonEra(r.ConstructionCompleteDateRaw)
-	if _, err := pool.Exec(
-		context.Background(),
-		query,
-		r.SerialNumber, city, r.District, r.TransactionType, r.Address, r.LandShiftingArea,
-		r.UrbanLandUse, r.NonUrbanLandUse, r.NonUrbanLandDesignation, r.TransactionDateRaw, transacDate, r.TransactionPenNumber,
-		r.Floor, r.TotalFloor, r.BuildingType, r.PrimaryUse, r.PrimaryMaterial,
-		r.ConstructionCompleteDateRaw, constructDate, r.BuildingAreaSqm, r.NumberOfRooms, r.NumberOfLivingRooms,
-		r.NumberOfBathrooms, r.Partitioned, r.HasManagementOrganization, r.TotalPrice, r.UnitPrice,
-		r.ParkingType, r.ParkingArea, r.ParkingPrice, r.Notes,
-	); err != nil {
-		return e.NewErrorData(
+	return store.Transaction{
+		Table:   store.RentalTable,
+		Columns: transactionColumns,
+		Values: append([]any{
+			r.SerialNumber, city, r.District, r.TransactionType, r.Address, r.LandShiftingArea,
+			r.UrbanLandUse, r.NonUrbanLandUse, r.NonUrbanLandDesignation, r.TransactionDateRaw, transacDate, r.TransactionPenNumber,
+			r.Floor, r.TotalFloor, r.BuildingType, r.PrimaryUse, r.PrimaryMaterial,
+			r.ConstructionCompleteDateRaw, constructDate, r.BuildingAreaSqm, r.NumberOfRooms, r.NumberOfLivingRooms,
+			r.NumberOfBathrooms, r.Partitioned, r.HasManagementOrganization, r.TotalPrice, r.UnitPrice,
+			r.ParkingType, r.ParkingArea, r.ParkingPrice, r.Notes,
+		}, address.Parse(r.Address, r.District).Columns()...),
+	}
+}
+
+func (r RentalItem) dateAndDistrict() (string, string) {
+	return r.TransactionDateRaw, r.District
+}
+
+// save inserts the row, or updates it when it is already imported with other values
+func (r RentalItem) save(ctx context.Context, s store.TransactionStore, ref *reference.Reference, city string) (store.SaveResult, *e.ErrorData) {
+	result, errData := s.Save(ctx, r.transaction(city))
+	if errData != nil {
+		return store.Unchanged, e.NewErrorData(
 			DbInsertionError,
-			fmt.Sprintf("Error: %s on %s", err.Error(), r.toString(city)),
-			fmt.Sprintf("%s.NewHouseItem.save", currentPackage),
+			fmt.Sprintf("Error: %s on %s", errData.Message, r.toString(ref.CityName(city))),
+			fmt.Sprintf("%s.RentalItem.save", currentPackage),
 			nil,
 			nil,
-		)
+		).WithCause(errData)
 	}
-	return nil
+	return result, nil
 }
-func (r RentalItem) toString(cityCode string) string {
-	city := cityMap[cityCode]
+func (r RentalItem) toString(cityName string) string {
 	return fmt.Sprintf(`
 	[RentalItem] [%s] City=%s District=%s TransacType=%s TransacDate=%s
-	`, r.SerialNumber, city, r.District, r.TransactionType, r.TransactionDateRaw)
+	`, r.SerialNumber, cityName, r.District, r.TransactionType, r.TransactionDateRaw)
 }
@@ -0,0 +1,24 @@
+package plvr
+
+import (
+	"sort"
+
+	"github.com/Walker088/gorealestate/common"
+)
+
+// years returns the sorted years of the roc dates the store is prepared for before their rows
+// are inserted, the invalid dates are skipped, their rows land in the default partition
+func years(rocDates []string) []int {
+	set := map[int]bool{}
+	for _, raw := range rocDates {
+		if date, err := common.RocEraToCommonEra(raw); err == nil && date != nil {
+			set[date.Year()] = true
+		}
+	}
+	sorted := make([]int, 0, len(set))
+	for year := range set {
+		sorted = append(sorted, year)
+	}
+	sort.Ints(sorted)
+	return sorted
+}
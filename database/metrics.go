@@ -0,0 +1,86 @@
+package database
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/Walker088/gorealestate/metrics"
+)
+
+var (
+	slowQueries = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: metrics.Namespace,
+		Subsystem: "database",
+		Name:      "slow_queries_total",
+		Help:      "Queries taking longer than database.slow_query.",
+	})
+)
+
+func init() {
+	metrics.Registry.MustRegister(slowQueries)
+}
+
+// poolCollector reads the stats of the pool on every scrape
+type poolCollector struct {
+	pool   *PgPool
+	gauges map[string]*prometheus.Desc
+	counts map[string]*prometheus.Desc
+}
+
+// NewPoolCollector exports the stats of pool, see PoolStats
+func NewPoolCollector(pool *PgPool) prometheus.Collector {
+	desc := func(name string, help string) *prometheus.Desc {
+		return prometheus.NewDesc(prometheus.BuildFQName(metrics.Namespace, "database", name), help, nil, nil)
+	}
+	return &poolCollector{
+		pool: pool,
+		gauges: map[string]*prometheus.Desc{
+			"acquired":     desc("pool_acquired_conns", "Connections of the pool currently acquired."),
+			"idle":         desc("pool_idle_conns", "Connections of the pool currently idle."),
+			"constructing": desc("pool_constructing_conns", "Connections of the pool being opened."),
+			"total":        desc("pool_total_conns", "Connections of the pool, acquired, idle and being opened."),
+			"max":          desc("pool_max_conns", "Max connections of the pool, i.e., database.max_conns."),
+		},
+		counts: map[string]*prometheus.Desc{
+			"acquires":          desc("pool_acquires_total", "Connections acquired from the pool."),
+			"empty_acquires":    desc("pool_empty_acquires_total", "Acquires which waited for a connection as none was idle."),
+			"canceled_acquires": desc("pool_canceled_acquires_total", "Acquires canceled by their context."),
+			"acquire_seconds":   desc("pool_acquire_duration_seconds_total", "Time spent acquiring the connections."),
+			"new_conns":         desc("pool_new_conns_total", "Connections opened by the pool."),
+			"lifetime_destroys": desc("pool_max_lifetime_destroys_total", "Connections closed after database.max_conn_lifetime."),
+			"idle_destroys":     desc("pool_max_idle_destroys_total", "Connections closed after database.max_conn_idle_time."),
+		},
+	}
+}
+
+func (c *poolCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range c.gauges {
+		ch <- d
+	}
+	for _, d := range c.counts {
+		ch <- d
+	}
+}
+
+func (c *poolCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.pool.Stats()
+	for key, v := range map[string]int32{
+		"acquired":     s.AcquiredConns,
+		"idle":         s.IdleConns,
+		"constructing": s.ConstructingConns,
+		"total":        s.TotalConns,
+		"max":          s.MaxConns,
+	} {
+		ch <- prometheus.MustNewConstMetric(c.gauges[key], prometheus.GaugeValue, float64(v))
+	}
+	for key, v := range map[string]float64{
+		"acquires":          float64(s.AcquireCount),
+		"empty_acquires":    float64(s.EmptyAcquireCount),
+		"canceled_acquires": float64(s.CanceledAcquireCount),
+		"acquire_seconds":   s.AcquireDuration.Seconds(),
+		"new_conns":         float64(s.NewConnsCount),
+		"lifetime_destroys": float64(s.MaxLifetimeDestroyCount),
+		"idle_destroys":     float64(s.MaxIdleDestroyCount),
+	} {
+		ch <- prometheus.MustNewConstMetric(c.counts[key], prometheus.CounterValue, v)
+	}
+}
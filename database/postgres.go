@@ -3,6 +3,7 @@ package database
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/jackc/pgx/v5/pgxpool"
 	"go.uber.org/zap"
@@ -16,23 +17,29 @@ const (
 
 	DbConnStringParsingError = "DB00001"
 	DbTxPoolCreatingError    = "DB00002"
+	DbHealthCheckError       = "DB00004"
+
+	// maxConnectBackoff caps the doubled wait between the connection attempts on startup
+	maxConnectBackoff = 30 * time.Second
 )
 
 type PgPool struct {
 	pool   *pgxpool.Pool
+	config *config.PgConfig
 	logger *zap.SugaredLogger
 }
 
+// New creates the pool and waits for the database to accept a connection, the attempt is
+// retried config.ConnectRetries times with a doubled backoff, e.g., while the container of the
+// database starts, the queries slower than config.SlowQuery are logged as warnings
 func New(config *config.PgConfig, logger *zap.SugaredLogger) (*PgPool, *e.ErrorData) {
 
 	poolConf, err := pgxpool.ParseConfig(config.ToConnString())
 	if err != nil {
-		return nil, e.NewErrorData(
+		return nil, e.Wrap(
 			DbConnStringParsingError,
-			err.Error(),
+			err,
 			fmt.Sprintf("%s.New", currentPackage),
-			nil,
-			nil,
 		)
 	}
 	poolConf.MinConns = config.MinConns
@@ -41,24 +48,66 @@ func New(config *config.PgConfig, logger *zap.SugaredLogger) (*PgPool, *e.ErrorD
 	poolConf.MaxConnLifetime = config.MaxConnLifetime
 	poolConf.MaxConnLifetimeJitter = config.MaxConnLifetimeJitter
 	poolConf.HealthCheckPeriod = config.HealthCheckPeriod
+	if config.SlowQuery > 0 {
+		poolConf.ConnConfig.Tracer = newSlowQueryTracer(config.SlowQuery, logger)
+	}
 
-	pool, err := pgxpool.NewWithConfig(context.Background(), poolConf)
+	pool, err := connect(poolConf, config, logger)
 	if err != nil {
-		return nil, e.NewErrorData(
+		return nil, e.Wrap(
 			DbTxPoolCreatingError,
-			err.Error(),
+			err,
 			fmt.Sprintf("%s.New", currentPackage),
-			nil,
-			nil,
 		)
 	}
-	logger.Debugf("pgx connection pool initialized on %s", poolConf.ConnString())
+	logger.Debugf("pgx connection pool initialized on %s", config.Redacted())
 	return &PgPool{
 		pool:   pool,
+		config: config,
 		logger: logger,
 	}, nil
 }
 
+// connect creates the pool and pings the database until it answers or the retries run out,
+// the error of the last attempt is returned
+func connect(poolConf *pgxpool.Config, config *config.PgConfig, logger *zap.SugaredLogger) (*pgxpool.Pool, error) {
+	backoff := config.ConnectBackoff
+	for attempt := 0; ; attempt++ {
+		pool, err := pgxpool.NewWithConfig(context.Background(), poolConf)
+		if err == nil {
+			ctx, cancel := context.WithTimeout(context.Background(), config.HealthTimeout)
+			err = pool.Ping(ctx)
+			cancel()
+			if err == nil {
+				return pool, nil
+			}
+			pool.Close()
+		}
+		if attempt >= config.ConnectRetries {
+			return nil, err
+		}
+		logger.Warnf("[database] %s not ready, retrying in %s (%d/%d): %s", config.Redacted(), backoff, attempt+1, config.ConnectRetries, err)
+		time.Sleep(backoff)
+		if backoff *= 2; backoff > maxConnectBackoff {
+			backoff = maxConnectBackoff
+		}
+	}
+}
+
+// Health pings the database within config.HealthTimeout
+func (p *PgPool) Health(ctx context.Context) *e.ErrorData {
+	ctx, cancel := context.WithTimeout(ctx, p.config.HealthTimeout)
+	defer cancel()
+	if err := p.pool.Ping(ctx); err != nil {
+		return e.Wrap(
+			DbHealthCheckError,
+			err,
+			fmt.Sprintf("%s.Health", currentPackage),
+		)
+	}
+	return nil
+}
+
 func (p *PgPool) ShutDownPool() {
 	p.pool.Close()
 	p.logger.Debug("pgx connection pool shutted down")
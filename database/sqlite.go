@@ -0,0 +1,127 @@
+package database
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"sync"
+
+	"go.uber.org/zap"
+	"modernc.org/sqlite"
+
+	"github.com/Walker088/gorealestate/config"
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	SQLiteOpenError = "DB00003"
+)
+
+func init() {
+	// the analyses take the median of the groups, postgres has percentile_cont
+	sqlite.MustRegisterFunction("median", &sqlite.FunctionImpl{
+		NArgs:         1,
+		Deterministic: true,
+		MakeAggregate: func(ctx sqlite.FunctionContext) (sqlite.AggregateFunction, error) {
+			return &median{}, nil
+		},
+	})
+	// the flags match the notes by regular expressions, sqlite takes X REGEXP Y as regexp(Y, X)
+	sqlite.MustRegisterFunction("regexp", &sqlite.FunctionImpl{
+		NArgs:         2,
+		Deterministic: true,
+		Scalar:        matchRegexp,
+	})
+}
+
+// NewSQLite opens the database file of config.SQLitePath, the file is created when missing
+func NewSQLite(config *config.PgConfig, logger *zap.SugaredLogger) (*sql.DB, *e.ErrorData) {
+	db, err := sql.Open("sqlite", config.SQLiteDSN())
+	if err == nil {
+		err = db.Ping()
+	}
+	if err != nil {
+		return nil, e.Wrap(
+			SQLiteOpenError,
+			err,
+			fmt.Sprintf("%s.NewSQLite", currentPackage),
+		)
+	}
+	// sqlite takes a single writer, the concurrent imports queue on the connection instead of
+	// failing on a locked database
+	db.SetMaxOpenConns(1)
+	logger.Debugf("sqlite database opened on %s", config.SQLitePath)
+	return db, nil
+}
+
+// median is the aggregate of the continuous median, i.e., the mean of the middle values, as
+// percentile_cont(0.5) of postgres, nulls are ignored
+type median struct {
+	values []float64
+}
+
+func (m *median) Step(ctx *sqlite.FunctionContext, args []driver.Value) error {
+	switch v := args[0].(type) {
+	case int64:
+		m.values = append(m.values, float64(v))
+	case float64:
+		m.values = append(m.values, v)
+	case nil:
+	default:
+		return fmt.Errorf("median of %T", v)
+	}
+	return nil
+}
+
+func (m *median) WindowInverse(ctx *sqlite.FunctionContext, args []driver.Value) error {
+	return errors.New("median is not a window function")
+}
+
+func (m *median) WindowValue(ctx *sqlite.FunctionContext) (driver.Value, error) {
+	n := len(m.values)
+	if n == 0 {
+		return nil, nil
+	}
+	sort.Float64s(m.values)
+	if n%2 == 1 {
+		return m.values[n/2], nil
+	}
+	return (m.values[n/2-1] + m.values[n/2]) / 2, nil
+}
+
+func (m *median) Final(ctx *sqlite.FunctionContext) {}
+
+// patterns caches the compiled patterns of regexp, the flags match every row against a few ones
+var patterns sync.Map
+
+// matchRegexp tells whether the text of args[1] matches the pattern of args[0], a null text
+// matches nothing, as ~ of postgres
+func matchRegexp(ctx *sqlite.FunctionContext, args []driver.Value) (driver.Value, error) {
+	pattern, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("regexp pattern of %T", args[0])
+	}
+	var text string
+	switch v := args[1].(type) {
+	case nil:
+		return nil, nil
+	case string:
+		text = v
+	case []byte:
+		text = string(v)
+	default:
+		text = fmt.Sprint(v)
+	}
+	re, ok := patterns.Load(pattern)
+	if !ok {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		re, _ = patterns.LoadOrStore(pattern, compiled)
+	}
+	return re.(*regexp.Regexp).MatchString(text), nil
+}
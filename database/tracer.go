@@ -0,0 +1,72 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+)
+
+const (
+	// maxLoggedQuery truncates the logged statements, e.g., the long insert of a transaction
+	maxLoggedQuery = 500
+)
+
+type queryStartKey struct{}
+
+type queryStart struct {
+	at  time.Time
+	sql string
+}
+
+// slowQueryTracer logs the queries taking at least threshold, a query of rows takes until
+// its rows are closed, i.e., the reading of the rows counts
+type slowQueryTracer struct {
+	threshold time.Duration
+	logger    *zap.SugaredLogger
+}
+
+func newSlowQueryTracer(threshold time.Duration, logger *zap.SugaredLogger) *slowQueryTracer {
+	return &slowQueryTracer{
+		threshold: threshold,
+		logger:    logger,
+	}
+}
+
+func (t *slowQueryTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, queryStartKey{}, queryStart{at: time.Now(), sql: data.SQL})
+}
+
+func (t *slowQueryTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(queryStartKey{}).(queryStart)
+	if !ok {
+		return
+	}
+	d := time.Since(start.at)
+	if d < t.threshold {
+		return
+	}
+	slowQueries.Inc()
+	fields := []interface{}{"duration", d, "sql", compactQuery(start.sql)}
+	if data.Err != nil {
+		fields = append(fields, "error", data.Err.Error())
+	}
+	t.logger.Warnw("[database] slow query", fields...)
+}
+
+// compactQuery puts query on a single line, truncated to maxLoggedQuery bytes on a rune
+// boundary, e.g., of a chinese literal
+func compactQuery(query string) string {
+	query = strings.Join(strings.Fields(query), " ")
+	if len(query) <= maxLoggedQuery {
+		return query
+	}
+	n := maxLoggedQuery
+	for n > 0 && !utf8.RuneStart(query[n]) {
+		n--
+	}
+	return query[:n] + "..."
+}
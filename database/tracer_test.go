@@ -0,0 +1,62 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/jackc/pgx/v5"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestCompactQuery(t *testing.T) {
+	if got := compactQuery("SELECT *\n\t FROM  plvr_land_house_sale\n WHERE city = $1"); got != "SELECT * FROM plvr_land_house_sale WHERE city = $1" {
+		t.Errorf("expected the query on a single line, got %s", got)
+	}
+	short := strings.Repeat("a", maxLoggedQuery)
+	if got := compactQuery(short); got != short {
+		t.Errorf("expected a query of %d bytes to be kept, got %d bytes", maxLoggedQuery, len(got))
+	}
+	// the 3 byte runes cross the limit at every offset
+	for offset := 0; offset < 3; offset++ {
+		query := "INSERT INTO plvr_land_house_sale (district) VALUES ('" + strings.Repeat("a", offset) + strings.Repeat("大安區", 200) + "')"
+		got := compactQuery(query)
+		if !utf8.ValidString(got) {
+			t.Errorf("offset %d: expected a valid utf-8 query, got %q", offset, got)
+		}
+		if !strings.HasSuffix(got, "...") || len(got) > maxLoggedQuery+len("...") || len(got) < maxLoggedQuery-2+len("...") {
+			t.Errorf("offset %d: expected the query truncated to %d bytes, got %d bytes", offset, maxLoggedQuery, len(got))
+		}
+		if !strings.HasPrefix(query, strings.TrimSuffix(got, "...")) {
+			t.Errorf("offset %d: expected a prefix of the query, got %s", offset, got)
+		}
+	}
+}
+
+func TestSlowQueryTracer(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	tracer := newSlowQueryTracer(time.Hour, zap.New(core).Sugar())
+	query := "SELECT * FROM plvr_land_house_sale WHERE district = '" + strings.Repeat("臺", 300) + "'"
+
+	ctx := tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: query})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	if logs.Len() != 0 {
+		t.Fatalf("expected a fast query not to be logged, got %v", logs.All())
+	}
+
+	tracer.threshold = 0
+	ctx = tracer.TraceQueryStart(context.Background(), nil, pgx.TraceQueryStartData{SQL: query})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{})
+	entries := logs.All()
+	if len(entries) != 1 {
+		t.Fatalf("expected the slow query to be logged once, got %v", entries)
+	}
+	sql, ok := entries[0].ContextMap()["sql"].(string)
+	if !ok || !utf8.ValidString(sql) || len(sql) > maxLoggedQuery+len("...") {
+		t.Errorf("expected the logged query truncated on a rune boundary, got %q", sql)
+	}
+}
@@ -1,6 +1,9 @@
 package error
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+)
 
 type Error struct {
 	ErrorData
@@ -16,6 +19,8 @@ type ErrorData struct {
 	Target  string       `json:"target,omitempty"`
 	Details []Error      `json:"details,omitempty"`
 	Inner   *interface{} `json:"innererror,omitempty"`
+	// Cause is the underlying error, it is not serialized but kept for errors.Is and errors.As
+	Cause error `json:"-"`
 }
 
 func NewError(errorData ErrorData) *Error {
@@ -40,6 +45,44 @@ func NewErrorData(code string, message string, target string, details []Error, i
 	}
 }
 
+// Wrap builds the error data of code from err, the message is the one of err and err is kept as the cause
+func Wrap(code string, err error, target string) *ErrorData {
+	return NewErrorData(code, err.Error(), target, nil, nil).WithCause(err)
+}
+
+// WithCause keeps err as the underlying error of e, it returns e
+func (e *ErrorData) WithCause(err error) *ErrorData {
+	e.Cause = err
+	return e
+}
+
+// Error implements error, Error and InnerError implement it through the embedded ErrorData
+func (e *ErrorData) Error() string {
+	if e.Target == "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Message)
+	}
+	return fmt.Sprintf("%s: %s (%s)", e.Code, e.Message, e.Target)
+}
+
+func (e *ErrorData) Unwrap() error {
+	return e.Cause
+}
+
+// Is reports whether the error data in the chain of err has code, e.g., e.Is(err, plvr.HttpStatusError)
+func Is(err error, code string) bool {
+	for err != nil {
+		var data *ErrorData
+		if !errors.As(err, &data) {
+			return false
+		}
+		if data.Code == code {
+			return true
+		}
+		err = data.Cause
+	}
+	return false
+}
+
 func (e *Error) ToString() string {
 	return fmt.Sprintf(
 		"Error{code=%s, message=%s, target=%s, details=%v, innererror=%v}",
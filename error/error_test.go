@@ -0,0 +1,110 @@
+package error
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestIs(t *testing.T) {
+	inner := Wrap("ST00001", io.ErrUnexpectedEOF, "store.Save")
+	outer := NewErrorData("PV00011", "rows not saved", "plvr.saveRows", nil, nil).WithCause(inner)
+	wrapped := fmt.Errorf("season 104S1: %w", outer)
+
+	for _, code := range []string{"PV00011", "ST00001"} {
+		if !Is(wrapped, code) {
+			t.Errorf("expected %s in the chain", code)
+		}
+	}
+	if Is(wrapped, "ST00002") || Is(io.EOF, "ST00001") || Is(nil, "ST00001") {
+		t.Error("expected a code missing from the chain not to match")
+	}
+	if !errors.Is(wrapped, io.ErrUnexpectedEOF) {
+		t.Error("expected the cause to be unwrapped")
+	}
+	var data *ErrorData
+	if !errors.As(wrapped, &data) || data != outer {
+		t.Errorf("expected the outer error data, got %v", data)
+	}
+}
+
+func TestWrap(t *testing.T) {
+	err := Wrap("DB00004", io.EOF, "database.Health")
+	if err.Message != io.EOF.Error() || err.Cause != io.EOF || err.Unwrap() != io.EOF {
+		t.Errorf("expected the message and the cause of io.EOF, got %+v", err)
+	}
+	if s := err.Error(); s != "DB00004: EOF (database.Health)" {
+		t.Errorf("unexpected message %s", s)
+	}
+	if s := NewErrorData("DB00004", "down", "", nil, nil).Error(); s != "DB00004: down" {
+		t.Errorf("unexpected message without a target %s", s)
+	}
+}
+
+func TestLookup(t *testing.T) {
+	info, ok := Lookup("RP00003")
+	if !ok || info.Code != "RP00003" || info.HTTPStatus != 404 {
+		t.Errorf("expected RP00003 to be a registered 404, got %+v", info)
+	}
+	info, ok = Lookup("XX00001")
+	if ok || info.Code != "XX00001" || info.HTTPStatus != 500 || info.Retryable {
+		t.Errorf("expected an unknown code to be a 500, got %+v", info)
+	}
+	codes := Codes()
+	for i := 1; i < len(codes); i++ {
+		if codes[i-1].Code >= codes[i].Code {
+			t.Fatalf("expected the codes to be sorted, got %s before %s", codes[i-1].Code, codes[i].Code)
+		}
+	}
+}
+
+// codeConstant matches the error code constants of the packages, e.g., SaveRowsError = "PV00011"
+var codeConstant = regexp.MustCompile(`(?m)^\s*\w+\s*=\s*"([A-Z][A-Z0-9]\d{5})"`)
+
+func TestRegistryCoversPackageCodes(t *testing.T) {
+	seen := map[string]string{}
+	err := filepath.WalkDir("..", func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && strings.HasPrefix(d.Name(), ".") && path != ".." {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, ".go") || strings.HasSuffix(path, "_test.go") {
+			return nil
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		for _, m := range codeConstant.FindAllStringSubmatch(string(b), -1) {
+			if other, ok := seen[m[1]]; ok {
+				t.Errorf("code %s is declared by both %s and %s", m[1], other, path)
+			}
+			seen[m[1]] = path
+			if _, ok := Lookup(m[1]); !ok {
+				t.Errorf("code %s of %s is not registered", m[1], path)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(seen) == 0 {
+		t.Fatal("expected the packages to declare error codes")
+	}
+	if len(seen) != len(registry) {
+		for code := range registry {
+			if _, ok := seen[code]; !ok {
+				t.Errorf("registered code %s is not declared by any package", code)
+			}
+		}
+	}
+}
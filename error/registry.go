@@ -0,0 +1,139 @@
+package error
+
+import (
+	"net/http"
+	"sort"
+)
+
+// Severity tells how much of the work an error stops
+type Severity string
+
+const (
+	// SeverityWarning is a failure of a single item, e.g., a row, the rest goes on
+	SeverityWarning Severity = "warning"
+	// SeverityError is a failure of an operation, e.g., a season or a request
+	SeverityError Severity = "error"
+	// SeverityFatal is a failure the application can not start or go on with
+	SeverityFatal Severity = "fatal"
+)
+
+// CodeInfo describes an error code, callers branch on it instead of on the codes themselves
+type CodeInfo struct {
+	Code        string   `json:"code"`
+	Description string   `json:"description"`
+	Severity    Severity `json:"severity"`
+	Retryable   bool     `json:"retryable"`
+	HTTPStatus  int      `json:"http_status"`
+}
+
+// registry lists every error code of the application, the packages keep their own constants
+// of the codes, a duplicated code does not compile
+var registry = map[string]CodeInfo{
+	// address
+	"AD00001": {Description: "unable to query the addresses", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	"AD00002": {Description: "unable to update the address columns", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	// analysis
+	"AN00001": {Description: "invalid analysis filter", Severity: SeverityError, HTTPStatus: http.StatusBadRequest},
+	"AN00002": {Description: "analysis query failed", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	"AN00003": {Description: "unable to scan an analysis row", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	"AN00004": {Description: "unable to flag the transactions", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	// api
+	"AP00001": {Description: "invalid request parameter", Severity: SeverityError, HTTPStatus: http.StatusBadRequest},
+	"AP00002": {Description: "unable to start the server", Severity: SeverityFatal, HTTPStatus: http.StatusInternalServerError},
+	"AP00003": {Description: "unable to shut the server down", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	"AP00004": {Description: "missing or wrong admin token", Severity: SeverityWarning, HTTPStatus: http.StatusUnauthorized},
+	// config
+	"C000001": {Description: "config file not found", Severity: SeverityFatal, HTTPStatus: http.StatusInternalServerError},
+	"C000002": {Description: "unable to read the config", Severity: SeverityFatal, HTTPStatus: http.StatusInternalServerError},
+	"C000003": {Description: "invalid config", Severity: SeverityFatal, HTTPStatus: http.StatusBadRequest},
+	"C000004": {Description: "unable to watch the config files", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	// common
+	"CM00001": {Description: "invalid roc season", Severity: SeverityWarning, HTTPStatus: http.StatusBadRequest},
+	"CM00002": {Description: "invalid roc date", Severity: SeverityWarning, HTTPStatus: http.StatusBadRequest},
+	// database
+	"DB00001": {Description: "invalid connection string", Severity: SeverityFatal, HTTPStatus: http.StatusInternalServerError},
+	"DB00002": {Description: "unable to create the connection pool", Severity: SeverityFatal, Retryable: true, HTTPStatus: http.StatusServiceUnavailable},
+	"DB00003": {Description: "unable to open the sqlite database", Severity: SeverityFatal, HTTPStatus: http.StatusInternalServerError},
+	"DB00004": {Description: "database health check failed", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusServiceUnavailable},
+	// export
+	"EX00001": {Description: "invalid export options", Severity: SeverityError, HTTPStatus: http.StatusBadRequest},
+	"EX00002": {Description: "export query failed", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	"EX00003": {Description: "unable to write the export", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	// geocode
+	"GC00001": {Description: "unable to read the geocoding reference", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	"GC00002": {Description: "unable to load the geocoding reference", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	"GC00003": {Description: "geocoding failed", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	// migrations
+	"MR00001": {Description: "unable to open the database", Severity: SeverityFatal, Retryable: true, HTTPStatus: http.StatusServiceUnavailable},
+	"MR00002": {Description: "unable to create the migration driver", Severity: SeverityFatal, Retryable: true, HTTPStatus: http.StatusServiceUnavailable},
+	"MR00003": {Description: "unable to create the migration instance", Severity: SeverityFatal, HTTPStatus: http.StatusInternalServerError},
+	"MR00004": {Description: "migration failed", Severity: SeverityFatal, HTTPStatus: http.StatusInternalServerError},
+	"MR00005": {Description: "unable to read the migrations", Severity: SeverityFatal, HTTPStatus: http.StatusInternalServerError},
+	"MR00006": {Description: "invalid migration version or steps", Severity: SeverityError, HTTPStatus: http.StatusBadRequest},
+	"MR00007": {Description: "unable to create the schema", Severity: SeverityFatal, Retryable: true, HTTPStatus: http.StatusServiceUnavailable},
+	// plvr parser
+	"PS00001": {Description: "unable to insert a row", Severity: SeverityWarning, HTTPStatus: http.StatusInternalServerError},
+	// plvr crawler
+	"PV00001": {Description: "unexpected http status of the plvr api", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusBadGateway},
+	"PV00002": {Description: "unable to create the zip file", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	"PV00003": {Description: "invalid downloaded zip file", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusBadGateway},
+	"PV00004": {Description: "unable to open a zipped file", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	"PV00005": {Description: "unable to read a zipped file", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	"PV00007": {Description: "plvr api request failed", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusBadGateway},
+	"PV00008": {Description: "unable to read the stored zip file", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	"PV00009": {Description: "invalid stored zip file", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	"PV00010": {Description: "unable to parse a plvr csv file", Severity: SeverityError, HTTPStatus: http.StatusInternalServerError},
+	"PV00011": {Description: "rows of a plvr csv file not saved", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	"PV00013": {Description: "district missing from the reference data", Severity: SeverityWarning, HTTPStatus: http.StatusBadRequest},
+	// reference
+	"RF00001": {Description: "unable to load the city and district reference", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	"RF00002": {Description: "city not found", Severity: SeverityError, HTTPStatus: http.StatusNotFound},
+	// report
+	"RP00001": {Description: "unable to save the crawl run report", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	"RP00002": {Description: "unable to query the crawl run reports", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	"RP00003": {Description: "crawl run not found", Severity: SeverityError, HTTPStatus: http.StatusNotFound},
+	// store
+	"ST00001": {Description: "unable to insert a transaction", Severity: SeverityWarning, HTTPStatus: http.StatusInternalServerError},
+	"ST00002": {Description: "unable to prepare a transaction table, e.g., create a yearly partition", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	"ST00003": {Description: "unable to check the download history", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+	"ST00004": {Description: "feature not supported by the database driver, e.g., postgis on sqlite", Severity: SeverityError, HTTPStatus: http.StatusNotImplemented},
+	"ST00005": {Description: "unable to record an imported download in the history", Severity: SeverityError, Retryable: true, HTTPStatus: http.StatusInternalServerError},
+}
+
+// unknown describes the codes missing from the registry
+var unknown = CodeInfo{Severity: SeverityError, HTTPStatus: http.StatusInternalServerError}
+
+// Lookup returns the description of code, false when it is not registered
+func Lookup(code string) (CodeInfo, bool) {
+	info, ok := registry[code]
+	if !ok {
+		info = unknown
+	}
+	info.Code = code
+	return info, ok
+}
+
+// Codes lists the registered codes sorted by code
+func Codes() []CodeInfo {
+	codes := make([]CodeInfo, 0, len(registry))
+	for code := range registry {
+		info, _ := Lookup(code)
+		codes = append(codes, info)
+	}
+	sort.Slice(codes, func(i, j int) bool { return codes[i].Code < codes[j].Code })
+	return codes
+}
+
+// Info returns the description of the code of e, unregistered codes are errors of status 500
+func (e *ErrorData) Info() CodeInfo {
+	info, _ := Lookup(e.Code)
+	return info
+}
+
+func (e *ErrorData) Retryable() bool {
+	return e.Info().Retryable
+}
+
+func (e *ErrorData) HTTPStatus() int {
+	return e.Info().HTTPStatus
+}
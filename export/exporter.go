@@ -0,0 +1,80 @@
+package export
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/analysis"
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/store"
+)
+
+const (
+	currentPackage = "github.com/Walker088/gorealestate/export"
+
+	InvalidOptionsError = "EX00001"
+	QueryError          = "EX00002"
+	WriteError          = "EX00003"
+)
+
+var (
+	// Tables maps the exported families to the transaction tables
+	Tables = map[string]string{
+		"house_sale": "plvr_land_house_sale",
+		"new_house":  "plvr_land_new_house",
+		"rental":     "plvr_land_rental",
+	}
+)
+
+type Exporter struct {
+	db     store.Querier
+	logger *zap.SugaredLogger
+}
+
+type Options struct {
+	analysis.Filter
+	Table string `json:"table"` // house_sale, new_house or rental
+}
+
+func New(db store.Querier, logger *zap.SugaredLogger) *Exporter {
+	return &Exporter{
+		db:     db,
+		logger: logger,
+	}
+}
+
+// Validate checks the table and the filter before anything is written
+func (o *Options) Validate() *e.ErrorData {
+	_, _, _, errData := o.source("Options.Validate")
+	return errData
+}
+
+// source resolves the table or clean view and the sql conditions of the options
+func (o *Options) source(target string) (string, string, []any, *e.ErrorData) {
+	if o.Table == "" {
+		o.Table = "house_sale"
+	}
+	table, ok := Tables[o.Table]
+	if !ok {
+		names := []string{}
+		for name := range Tables {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		return "", "", nil, e.NewErrorData(
+			InvalidOptionsError,
+			fmt.Sprintf("unknown table %s, expect one of %s", o.Table, strings.Join(names, ", ")),
+			fmt.Sprintf("%s.%s", currentPackage, target),
+			nil,
+			nil,
+		)
+	}
+	where, args, errData := o.Filter.Where()
+	if errData != nil {
+		return "", "", nil, errData
+	}
+	return analysis.CleanTable(table, o.IncludeFlagged), where, args, nil
+}
@@ -0,0 +1,261 @@
+package export
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/store"
+)
+
+const (
+	PartitionByCity   = "city"
+	PartitionBySeason = "season"
+)
+
+type FileOptions struct {
+	Options
+	Format      string   `json:"format"`                 // csv, jsonl or parquet
+	PartitionBy []string `json:"partition_by,omitempty"` // city and/or season
+}
+
+// Validate checks the format, partitions, table and filter before anything is written
+func (o *FileOptions) Validate() *e.ErrorData {
+	details := []e.Error{}
+	known := false
+	for _, f := range Formats {
+		known = known || f == o.Format
+	}
+	if !known {
+		details = append(details, *e.NewError(*e.NewErrorData(
+			InvalidOptionsError,
+			fmt.Sprintf("unsupported format %s, expect one of %s", o.Format, strings.Join(Formats, ", ")),
+			"format",
+			nil,
+			nil,
+		)))
+	}
+	for _, p := range o.PartitionBy {
+		if p != PartitionByCity && p != PartitionBySeason {
+			details = append(details, *e.NewError(*e.NewErrorData(
+				InvalidOptionsError,
+				fmt.Sprintf("unsupported partition %s, expect city or season", p),
+				"partition_by",
+				nil,
+				nil,
+			)))
+		}
+	}
+	if errData := o.Options.Validate(); errData != nil {
+		details = append(details, *e.NewError(*errData))
+	}
+	if len(details) > 0 {
+		return e.NewErrorData(
+			InvalidOptionsError,
+			"invalid export options",
+			fmt.Sprintf("%s.FileOptions.Validate", currentPackage),
+			details,
+			nil,
+		)
+	}
+	return nil
+}
+
+// partition returns the hive style directory of a record, e.g., city=a/season=112S1
+func (o *FileOptions) partition(r *Record) string {
+	dirs := []string{}
+	for _, p := range o.PartitionBy {
+		switch p {
+		case PartitionByCity:
+			dirs = append(dirs, "city="+r.City)
+		case PartitionBySeason:
+			dirs = append(dirs, "season="+r.season())
+		}
+	}
+	return filepath.Join(dirs...)
+}
+
+// query orders the rows by the partitions, so that each partition is written at once
+func (x *Exporter) query(ctx context.Context, opt *FileOptions, target string) (store.Rows, *e.ErrorData) {
+	if errData := opt.Validate(); errData != nil {
+		return nil, errData
+	}
+	table, where, args, errData := opt.source(target)
+	if errData != nil {
+		return nil, errData
+	}
+	d := x.db.Dialect()
+	order := ""
+	for _, p := range opt.PartitionBy {
+		if p == PartitionByCity {
+			order += "city, "
+		}
+		if p == PartitionBySeason {
+			order += d.Year("transaction_date") + ", " + d.Quarter("transaction_date") + ", "
+		}
+	}
+	query := fmt.Sprintf(
+		"SELECT %s FROM %s WHERE %s ORDER BY %stransaction_date",
+		selectList(d, table), table, where, order,
+	)
+	rows, err := x.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.%s", currentPackage, target),
+		)
+	}
+	return rows, nil
+}
+
+// Write streams the transactions into w as a single file, partitions are ignored
+func (x *Exporter) Write(ctx context.Context, w io.Writer, opt *FileOptions) (int64, *e.ErrorData) {
+	single := *opt
+	single.PartitionBy = nil
+	opt = &single
+	rows, errData := x.query(ctx, opt, "Write")
+	if errData != nil {
+		return 0, errData
+	}
+	defer rows.Close()
+
+	rw, err := newRecordWriter(opt.Format, w)
+	if err != nil {
+		return 0, e.Wrap(
+			WriteError,
+			err,
+			fmt.Sprintf("%s.Write", currentPackage),
+		)
+	}
+	var count int64
+	for rows.Next() {
+		var r Record
+		if err := rows.Scan(r.targets()...); err != nil {
+			return count, e.Wrap(
+				QueryError,
+				err,
+				fmt.Sprintf("%s.Write", currentPackage),
+			)
+		}
+		if err := rw.write(&r); err != nil {
+			return count, e.Wrap(
+				WriteError,
+				err,
+				fmt.Sprintf("%s.Write", currentPackage),
+			)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return count, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.Write", currentPackage),
+		)
+	}
+	if err := rw.close(); err != nil {
+		return count, e.Wrap(
+			WriteError,
+			err,
+			fmt.Sprintf("%s.Write", currentPackage),
+		)
+	}
+	x.logger.Debugf("[export] %d %s records written", count, opt.Format)
+	return count, nil
+}
+
+// Export writes the transactions to path, or when partitioned, to a directory tree
+// under path, e.g., path/city=a/season=112S1/part.parquet
+func (x *Exporter) Export(ctx context.Context, path string, opt *FileOptions) (int64, *e.ErrorData) {
+	if errData := opt.Validate(); errData != nil {
+		return 0, errData
+	}
+	if len(opt.PartitionBy) == 0 {
+		f, err := os.Create(path)
+		if err != nil {
+			return 0, e.Wrap(
+				WriteError,
+				err,
+				fmt.Sprintf("%s.Export", currentPackage),
+			)
+		}
+		defer f.Close()
+		return x.Write(ctx, f, opt)
+	}
+
+	rows, errData := x.query(ctx, opt, "Export")
+	if errData != nil {
+		return 0, errData
+	}
+	defer rows.Close()
+
+	var (
+		count     int64
+		current   = ""
+		file      *os.File
+		rw        recordWriter
+		errorData = func(code string, err error) *e.ErrorData {
+			return e.Wrap(
+				code,
+				err,
+				fmt.Sprintf("%s.Export", currentPackage),
+			)
+		}
+	)
+	closePartition := func() error {
+		if rw == nil {
+			return nil
+		}
+		defer file.Close()
+		return rw.close()
+	}
+	openPartition := func(dir string) error {
+		if err := os.MkdirAll(filepath.Join(path, dir), 0755); err != nil {
+			return err
+		}
+		f, err := os.Create(filepath.Join(path, dir, "part."+opt.Format))
+		if err != nil {
+			return err
+		}
+		file = f
+		rw, err = newRecordWriter(opt.Format, f)
+		return err
+	}
+
+	for rows.Next() {
+		var r Record
+		if err := rows.Scan(r.targets()...); err != nil {
+			closePartition()
+			return count, errorData(QueryError, err)
+		}
+		if dir := opt.partition(&r); dir != current || rw == nil {
+			if err := closePartition(); err != nil {
+				return count, errorData(WriteError, err)
+			}
+			if err := openPartition(dir); err != nil {
+				return count, errorData(WriteError, err)
+			}
+			x.logger.Debugf("[export] writing partition %s", dir)
+			current = dir
+		}
+		if err := rw.write(&r); err != nil {
+			closePartition()
+			return count, errorData(WriteError, err)
+		}
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		closePartition()
+		return count, errorData(QueryError, err)
+	}
+	if err := closePartition(); err != nil {
+		return count, errorData(WriteError, err)
+	}
+	x.logger.Debugf("[export] %d %s records written under %s", count, opt.Format, path)
+	return count, nil
+}
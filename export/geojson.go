@@ -0,0 +1,230 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/store"
+)
+
+type geometry struct {
+	Type        string      `json:"type"`
+	Coordinates interface{} `json:"coordinates"`
+}
+
+type feature struct {
+	Type       string      `json:"type"`
+	Geometry   geometry    `json:"geometry"`
+	Properties interface{} `json:"properties"`
+}
+
+type transactionProperties struct {
+	SerialNumber      string  `json:"serial_number"`
+	City              string  `json:"city"`
+	District          string  `json:"district"`
+	Address           string  `json:"address"`
+	BuildingType      string  `json:"building_type"`
+	TransactionDate   string  `json:"transaction_date"`
+	TotalPrice        int64   `json:"total_price"`
+	UnitPricePerSqm   int64   `json:"unit_price_per_sqm"`
+	BuildingAreaSqm   float64 `json:"building_area_sqm"`
+	GeocodeLevel      string  `json:"geocode_level"`
+	GeocodeConfidence float64 `json:"geocode_confidence"`
+}
+
+type districtProperties struct {
+	City            string  `json:"city"`
+	District        string  `json:"district"`
+	Transactions    int     `json:"transactions"`
+	MeanPerSqm      float64 `json:"mean_price_per_sqm"`
+	MedianPerSqm    float64 `json:"median_price_per_sqm"`
+	DistinctPoints  int     `json:"distinct_points"`
+	GeocodedPercent float64 `json:"geocoded_percent"`
+}
+
+// featureWriter streams the features of a collection, so that large exports take constant memory
+type featureWriter struct {
+	w     *bufio.Writer
+	count int
+}
+
+func newFeatureWriter(w io.Writer) (*featureWriter, error) {
+	fw := &featureWriter{w: bufio.NewWriter(w)}
+	_, err := fw.w.WriteString(`{"type":"FeatureCollection","features":[` + "\n")
+	return fw, err
+}
+
+func (fw *featureWriter) write(f *feature) error {
+	b, err := json.Marshal(f)
+	if err != nil {
+		return err
+	}
+	if fw.count > 0 {
+		if _, err := fw.w.WriteString(",\n"); err != nil {
+			return err
+		}
+	}
+	fw.count++
+	_, err = fw.w.Write(b)
+	return err
+}
+
+func (fw *featureWriter) close() error {
+	if _, err := fw.w.WriteString("\n]}\n"); err != nil {
+		return err
+	}
+	return fw.w.Flush()
+}
+
+// GeoJSON writes the geocoded transactions as a point FeatureCollection
+func (x *Exporter) GeoJSON(ctx context.Context, w io.Writer, opt *Options) (int, *e.ErrorData) {
+	table, where, args, errData := opt.source("GeoJSON")
+	if errData != nil {
+		return 0, errData
+	}
+	query := fmt.Sprintf(`
+	SELECT COALESCE(serial_number, ''), city, COALESCE(district, ''), COALESCE(address, ''), COALESCE(building_type, ''),
+		%s, CAST(COALESCE(total_price, 0) AS BIGINT), CAST(COALESCE(unit_price_per_sqm, 0) AS BIGINT),
+		CAST(COALESCE(building_area_sqm, 0) AS DOUBLE PRECISION), geocode_level,
+		CAST(geocode_confidence AS DOUBLE PRECISION), lon, lat
+	FROM %s
+	WHERE %s AND lat IS NOT NULL
+	`, x.db.Dialect().Date("transaction_date"), table, where)
+	rows, err := x.db.Query(ctx, query, args...)
+	if err != nil {
+		return 0, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.GeoJSON", currentPackage),
+		)
+	}
+	defer rows.Close()
+
+	return x.writeFeatures(w, rows, "GeoJSON", func(rows store.Rows) (*feature, error) {
+		var p transactionProperties
+		var lon, lat float64
+		if err := rows.Scan(
+			&p.SerialNumber, &p.City, &p.District, &p.Address, &p.BuildingType,
+			&p.TransactionDate, &p.TotalPrice, &p.UnitPricePerSqm,
+			&p.BuildingAreaSqm, &p.GeocodeLevel, &p.GeocodeConfidence, &lon, &lat,
+		); err != nil {
+			return nil, err
+		}
+		return &feature{
+			Type:       "Feature",
+			Geometry:   geometry{Type: "Point", Coordinates: [2]float64{lon, lat}},
+			Properties: p,
+		}, nil
+	})
+}
+
+// DistrictGeoJSON writes a polygon per district, i.e., the convex hull of its geocoded
+// transactions, along with the unit price statistics, the points are collected by array_agg of postgres
+func (x *Exporter) DistrictGeoJSON(ctx context.Context, w io.Writer, opt *Options) (int, *e.ErrorData) {
+	if _, errData := store.PgPool(x.db, "the district geojson", fmt.Sprintf("%s.DistrictGeoJSON", currentPackage)); errData != nil {
+		return 0, errData
+	}
+	table, where, args, errData := opt.source("DistrictGeoJSON")
+	if errData != nil {
+		return 0, errData
+	}
+	query := fmt.Sprintf(`
+	WITH src AS (
+		SELECT city, COALESCE(district, '') AS district, unit_price_per_sqm, lon, lat
+		FROM %s
+		WHERE %s
+	), stats AS (
+		SELECT city, district, COUNT(*)::INT AS n,
+			COALESCE(AVG(unit_price_per_sqm) FILTER (WHERE unit_price_per_sqm > 0), 0)::FLOAT8 AS mean,
+			COALESCE(percentile_cont(0.5) WITHIN GROUP (ORDER BY unit_price_per_sqm) FILTER (WHERE unit_price_per_sqm > 0), 0) AS median,
+			COUNT(lat)::FLOAT8 / COUNT(*) * 100 AS geocoded
+		FROM src
+		GROUP BY 1, 2
+	), points AS (
+		SELECT city, district, array_agg(lon) AS lons, array_agg(lat) AS lats
+		FROM (SELECT DISTINCT city, district, lon, lat FROM src WHERE lat IS NOT NULL) d
+		GROUP BY 1, 2
+	)
+	SELECT s.city, s.district, s.n, s.mean, s.median, s.geocoded, p.lons, p.lats
+	FROM stats s
+	JOIN points p USING (city, district)
+	ORDER BY s.city, s.district
+	`, table, where)
+	rows, err := x.db.Query(ctx, query, args...)
+	if err != nil {
+		return 0, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.DistrictGeoJSON", currentPackage),
+		)
+	}
+	defer rows.Close()
+
+	return x.writeFeatures(w, rows, "DistrictGeoJSON", func(rows store.Rows) (*feature, error) {
+		var p districtProperties
+		var lons, lats []float64
+		if err := rows.Scan(
+			&p.City, &p.District, &p.Transactions, &p.MeanPerSqm, &p.MedianPerSqm, &p.GeocodedPercent, &lons, &lats,
+		); err != nil {
+			return nil, err
+		}
+		points := make([]point, len(lons))
+		for i := range lons {
+			points[i] = point{lons[i], lats[i]}
+		}
+		p.DistinctPoints = len(points)
+		return &feature{
+			Type:       "Feature",
+			Geometry:   geometry{Type: "Polygon", Coordinates: [][][2]float64{convexHull(points)}},
+			Properties: p,
+		}, nil
+	})
+}
+
+func (x *Exporter) writeFeatures(w io.Writer, rows store.Rows, target string, toFeature func(store.Rows) (*feature, error)) (int, *e.ErrorData) {
+	fw, err := newFeatureWriter(w)
+	if err != nil {
+		return 0, e.Wrap(
+			WriteError,
+			err,
+			fmt.Sprintf("%s.%s", currentPackage, target),
+		)
+	}
+	for rows.Next() {
+		f, err := toFeature(rows)
+		if err != nil {
+			return fw.count, e.Wrap(
+				QueryError,
+				err,
+				fmt.Sprintf("%s.%s", currentPackage, target),
+			)
+		}
+		if err := fw.write(f); err != nil {
+			return fw.count, e.Wrap(
+				WriteError,
+				err,
+				fmt.Sprintf("%s.%s", currentPackage, target),
+			)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fw.count, e.Wrap(
+			QueryError,
+			err,
+			fmt.Sprintf("%s.%s", currentPackage, target),
+		)
+	}
+	if err := fw.close(); err != nil {
+		return fw.count, e.Wrap(
+			WriteError,
+			err,
+			fmt.Sprintf("%s.%s", currentPackage, target),
+		)
+	}
+	x.logger.Debugf("[export] %d features written by %s", fw.count, target)
+	return fw.count, nil
+}
@@ -0,0 +1,68 @@
+package export
+
+import "sort"
+
+// degenerateBuffer is the half width in degrees of the square drawn around districts
+// having less than three distinct points
+const degenerateBuffer = 0.001
+
+type point struct{ x, y float64 }
+
+// convexHull returns the closed ring of the convex hull by the monotone chain algorithm
+func convexHull(points []point) [][2]float64 {
+	sort.Slice(points, func(i, j int) bool {
+		if points[i].x == points[j].x {
+			return points[i].y < points[j].y
+		}
+		return points[i].x < points[j].x
+	})
+	uniq := points[:0]
+	for _, p := range points {
+		if len(uniq) == 0 || p != uniq[len(uniq)-1] {
+			uniq = append(uniq, p)
+		}
+	}
+	if len(uniq) < 3 {
+		return square(uniq)
+	}
+
+	cross := func(o, a, b point) float64 {
+		return (a.x-o.x)*(b.y-o.y) - (a.y-o.y)*(b.x-o.x)
+	}
+	hull := make([]point, 0, 2*len(uniq))
+	for _, p := range uniq {
+		for len(hull) >= 2 && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+	for i, lower := len(uniq)-2, len(hull)+1; i >= 0; i-- {
+		p := uniq[i]
+		for len(hull) >= lower && cross(hull[len(hull)-2], hull[len(hull)-1], p) <= 0 {
+			hull = hull[:len(hull)-1]
+		}
+		hull = append(hull, p)
+	}
+
+	// collinear points
+	if len(hull) < 4 {
+		return square(uniq)
+	}
+	// the last point equals the first one, i.e., a closed ring in counter-clockwise order
+	ring := make([][2]float64, 0, len(hull))
+	for _, p := range hull {
+		ring = append(ring, [2]float64{p.x, p.y})
+	}
+	return ring
+}
+
+// square draws a small closed ring around the centroid of the points
+func square(points []point) [][2]float64 {
+	var cx, cy float64
+	for _, p := range points {
+		cx, cy = cx+p.x, cy+p.y
+	}
+	cx, cy = cx/float64(len(points)), cy/float64(len(points))
+	b := degenerateBuffer
+	return [][2]float64{{cx - b, cy - b}, {cx + b, cy - b}, {cx + b, cy + b}, {cx - b, cy + b}, {cx - b, cy - b}}
+}
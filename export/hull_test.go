@@ -0,0 +1,43 @@
+package export
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestConvexHull(t *testing.T) {
+	// the inner, duplicated and collinear points are dropped
+	points := []point{{0, 0}, {2, 0}, {1, 1}, {2, 2}, {0, 2}, {1, 0}, {2, 2}, {0, 1}}
+	want := [][2]float64{{0, 0}, {2, 0}, {2, 2}, {0, 2}, {0, 0}}
+	if got := convexHull(points); !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the counter-clockwise ring %v, got %v", want, got)
+	}
+}
+
+func TestConvexHullDegenerate(t *testing.T) {
+	b := degenerateBuffer
+	for name, points := range map[string][]point{
+		"single":    {{121.5, 25}},
+		"duplicate": {{121.5, 25}, {121.5, 25}},
+		"collinear": {{121.5 - b, 25}, {121.5, 25}, {121.5 + b, 25}},
+	} {
+		want := [][2]float64{{121.5 - b, 25 - b}, {121.5 + b, 25 - b}, {121.5 + b, 25 + b}, {121.5 - b, 25 + b}, {121.5 - b, 25 - b}}
+		if got := convexHull(points); !near(got, want) {
+			t.Errorf("%s: expected the square %v around the centroid, got %v", name, want, got)
+		}
+	}
+}
+
+func near(a, b [][2]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		for j := range a[i] {
+			if d := a[i][j] - b[i][j]; d > 1e-9 || d < -1e-9 {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,181 @@
+package export
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Walker088/gorealestate/common"
+	"github.com/Walker088/gorealestate/store"
+)
+
+// Record is an exported transaction, the columns are named after the db tags of plvr.HouseSaleItem,
+// the ones only available on house sales are left empty for the other tables, city and district
+// are the current administrative units and city_raw and district_raw the ones of the plvr files
+type Record struct {
+	SerialNumber              string   `db:"serial_number" json:"serial_number"`
+	City                      string   `db:"city" json:"city"`
+	District                  string   `db:"district" json:"district"`
+	CityRaw                   string   `db:"city_raw" json:"city_raw"`
+	DistrictRaw               string   `db:"district_raw" json:"district_raw"`
+	TransactionType           string   `db:"transaction_type" json:"transaction_type"`
+	Address                   string   `db:"address" json:"address"`
+	AddressNormalized         string   `db:"address_normalized" json:"address_normalized"`
+	LandShiftingArea          *float64 `db:"land_shifting_area_sqm" json:"land_shifting_area_sqm"`
+	UrbanLandUse              string   `db:"urban_land_use" json:"urban_land_use"`
+	NonUrbanLandUse           string   `db:"non_urban_land_use" json:"non_urban_land_use"`
+	NonUrbanLandDesignation   string   `db:"non_urban_land_designation" json:"non_urban_land_designation"`
+	TransactionDate           *string  `db:"transaction_date" json:"transaction_date" export:"date"`
+	TransactionPenNumber      string   `db:"transaction_pen_number" json:"transaction_pen_number"`
+	Floor                     string   `db:"floor" json:"floor"`
+	TotalFloor                string   `db:"total_floor" json:"total_floor"`
+	BuildingType              string   `db:"building_type" json:"building_type"`
+	PrimaryUse                string   `db:"primary_use" json:"primary_use"`
+	PrimaryMaterial           string   `db:"primary_material" json:"primary_material"`
+	ConstructionCompleteDate  *string  `db:"construction_complete_date" json:"construction_complete_date" export:"date"`
+	BuildingAreaSqm           *float64 `db:"building_area_sqm" json:"building_area_sqm"`
+	NumberOfRooms             *int32   `db:"number_of_rooms" json:"number_of_rooms"`
+	NumberOfLivingRooms       *int32   `db:"number_of_living_rooms" json:"number_of_living_rooms"`
+	NumberOfBathrooms         *int32   `db:"number_of_bathrooms" json:"number_of_bathrooms"`
+	Partitioned               string   `db:"partitioned" json:"partitioned"`
+	HasManagementOrganization string   `db:"has_management_organization" json:"has_management_organization"`
+	TotalPrice                *int64   `db:"total_price" json:"total_price"`
+	UnitPrice                 *int64   `db:"unit_price_per_sqm" json:"unit_price_per_sqm"`
+	ParkingType               string   `db:"parking_type" json:"parking_type"`
+	ParkingArea               *float64 `db:"parking_area_sqm" json:"parking_area_sqm"`
+	ParkingPrice              *int64   `db:"parking_price" json:"parking_price"`
+	Notes                     string   `db:"notes" json:"notes"`
+	MainBuildingArea          *float64 `db:"main_building_area_sqm" json:"main_building_area_sqm" export:"house_sale"`
+	SubsidiaryBuildingArea    *float64 `db:"subsidiary_building_area_sqm" json:"subsidiary_building_area_sqm" export:"house_sale"`
+	BalconyArea               *float64 `db:"balcony_area_sqm" json:"balcony_area_sqm" export:"house_sale"`
+	Elevator                  string   `db:"elevator" json:"elevator" export:"house_sale"`
+	TransactionIdentifier     string   `db:"transaction_identifier" json:"transaction_identifier" export:"house_sale"`
+	Lat                       *float64 `db:"lat" json:"lat"`
+	Lon                       *float64 `db:"lon" json:"lon"`
+	Flags                     []string `db:"flags" json:"flags"`
+}
+
+type recordField struct {
+	name      string
+	kind      reflect.Kind // the pointed kind of the nullable fields
+	date      bool
+	houseSale bool
+}
+
+var (
+	recordFields = func() []recordField {
+		t := reflect.TypeOf(Record{})
+		fields := make([]recordField, t.NumField())
+		for i := range fields {
+			f := t.Field(i)
+			kind := f.Type.Kind()
+			if kind == reflect.Pointer {
+				kind = f.Type.Elem().Kind()
+			}
+			fields[i] = recordField{
+				name:      f.Tag.Get("db"),
+				kind:      kind,
+				date:      f.Tag.Get("export") == "date",
+				houseSale: f.Tag.Get("export") == "house_sale",
+			}
+		}
+		return fields
+	}()
+)
+
+// selectList renders the columns of the table in the order of the Record fields
+func selectList(d store.Dialect, table string) string {
+	cols := make([]string, len(recordFields))
+	for i, f := range recordFields {
+		expr := f.name
+		if f.houseSale && !strings.HasPrefix(table, Tables["house_sale"]) {
+			expr = "NULL"
+		}
+		switch {
+		case f.date:
+			cols[i] = d.Date(expr)
+		case f.kind == reflect.String:
+			cols[i] = fmt.Sprintf("COALESCE(CAST(%s AS TEXT), '')", expr)
+		case f.kind == reflect.Float64:
+			cols[i] = fmt.Sprintf("CAST(%s AS DOUBLE PRECISION)", expr)
+		case f.kind == reflect.Int64:
+			cols[i] = fmt.Sprintf("CAST(%s AS BIGINT)", expr)
+		case f.kind == reflect.Int32:
+			cols[i] = fmt.Sprintf("CAST(%s AS INTEGER)", expr)
+		default:
+			cols[i] = expr
+		}
+	}
+	return strings.Join(cols, ", ")
+}
+
+func header() []string {
+	names := make([]string, len(recordFields))
+	for i, f := range recordFields {
+		names[i] = f.name
+	}
+	return names
+}
+
+// targets returns the pointers of the fields to scan into
+func (r *Record) targets() []any {
+	v := reflect.ValueOf(r).Elem()
+	targets := make([]any, v.NumField())
+	for i := range targets {
+		targets[i] = v.Field(i).Addr().Interface()
+	}
+	return targets
+}
+
+// values returns the field values with the nulls as nil, dates are kept as strings
+func (r *Record) values() []interface{} {
+	v := reflect.ValueOf(r).Elem()
+	values := make([]interface{}, v.NumField())
+	for i := range values {
+		f := v.Field(i)
+		switch {
+		case f.Kind() == reflect.Pointer && f.IsNil():
+			values[i] = nil
+		case f.Kind() == reflect.Pointer:
+			values[i] = f.Elem().Interface()
+		case f.Kind() == reflect.Slice:
+			values[i] = strings.Join(f.Interface().([]string), "|")
+		default:
+			values[i] = f.Interface()
+		}
+	}
+	return values
+}
+
+// csvRow formats the fields for csv, nulls are empty and flags are separated by |
+func (r *Record) csvRow() []string {
+	values := r.values()
+	row := make([]string, len(values))
+	for i, v := range values {
+		switch v := v.(type) {
+		case nil:
+			row[i] = ""
+		case string:
+			row[i] = v
+		case float64:
+			row[i] = strconv.FormatFloat(v, 'f', -1, 64)
+		default:
+			row[i] = fmt.Sprint(v)
+		}
+	}
+	return row
+}
+
+// season returns the ROC season of the transaction, e.g., 112S1
+func (r *Record) season() string {
+	if r.TransactionDate == nil {
+		return "unknown"
+	}
+	t, err := time.Parse(time.DateOnly, *r.TransactionDate)
+	if err != nil {
+		return "unknown"
+	}
+	return common.ToRocSeason(t.Year(), (int(t.Month())-1)/3+1)
+}
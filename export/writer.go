@@ -0,0 +1,122 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/writer"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatJSONL   = "jsonl"
+	FormatParquet = "parquet"
+
+	// parquet buffers a row group in memory before flushing it
+	parquetRowGroupSize = 16 * 1024 * 1024
+)
+
+var (
+	Formats = []string{FormatCSV, FormatJSONL, FormatParquet}
+
+	epoch = time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+)
+
+type recordWriter interface {
+	write(r *Record) error
+	close() error
+}
+
+func newRecordWriter(format string, w io.Writer) (recordWriter, error) {
+	switch format {
+	case FormatCSV:
+		cw := csv.NewWriter(w)
+		return &csvWriter{w: cw}, cw.Write(header())
+	case FormatJSONL:
+		bw := bufio.NewWriter(w)
+		return &jsonlWriter{w: bw, enc: json.NewEncoder(bw)}, nil
+	case FormatParquet:
+		pw, err := writer.NewCSVWriterFromWriter(parquetSchema(), w, 1)
+		if err != nil {
+			return nil, err
+		}
+		pw.RowGroupSize = parquetRowGroupSize
+		pw.CompressionType = parquet.CompressionCodec_SNAPPY
+		return &parquetWriter{w: pw}, nil
+	}
+	return nil, fmt.Errorf("unsupported format %s, expect one of %v", format, Formats)
+}
+
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func (c *csvWriter) write(r *Record) error {
+	return c.w.Write(r.csvRow())
+}
+
+func (c *csvWriter) close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+type jsonlWriter struct {
+	w   *bufio.Writer
+	enc *json.Encoder
+}
+
+func (j *jsonlWriter) write(r *Record) error {
+	return j.enc.Encode(r)
+}
+
+func (j *jsonlWriter) close() error {
+	return j.w.Flush()
+}
+
+type parquetWriter struct {
+	w *writer.CSVWriter
+}
+
+func (p *parquetWriter) write(r *Record) error {
+	values := r.values()
+	for i, f := range recordFields {
+		if f.date && values[i] != nil {
+			t, err := time.Parse(time.DateOnly, values[i].(string))
+			if err != nil {
+				return err
+			}
+			values[i] = int32(t.Sub(epoch).Hours() / 24)
+		}
+	}
+	return p.w.Write(values)
+}
+
+func (p *parquetWriter) close() error {
+	return p.w.WriteStop()
+}
+
+// parquetSchema renders the metadata of the typed parquet columns, every column is optional
+func parquetSchema() []string {
+	md := make([]string, len(recordFields))
+	for i, f := range recordFields {
+		typ := "type=BYTE_ARRAY, convertedtype=UTF8"
+		switch {
+		case f.date:
+			typ = "type=INT32, convertedtype=DATE"
+		case f.kind == reflect.Float64:
+			typ = "type=DOUBLE"
+		case f.kind == reflect.Int64:
+			typ = "type=INT64"
+		case f.kind == reflect.Int32:
+			typ = "type=INT32"
+		}
+		md[i] = fmt.Sprintf("name=%s, %s, repetitiontype=OPTIONAL", f.name, typ)
+	}
+	return md
+}
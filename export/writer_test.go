@@ -0,0 +1,150 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xitongsys/parquet-go-source/buffer"
+	"github.com/xitongsys/parquet-go/parquet"
+	"github.com/xitongsys/parquet-go/reader"
+
+	"github.com/Walker088/gorealestate/store"
+)
+
+func testRecord() *Record {
+	date, price, area, rooms := "2023-02-01", int64(12000000), 80.5, int32(3)
+	return &Record{
+		SerialNumber:    "RPOOMLNKJHIFFAA67CA",
+		City:            "a",
+		District:        "大安區",
+		TransactionDate: &date,
+		BuildingAreaSqm: &area,
+		NumberOfRooms:   &rooms,
+		TotalPrice:      &price,
+		Flags:           []string{"special_relationship", "outlier"},
+	}
+}
+
+func TestRecordColumns(t *testing.T) {
+	names := header()
+	if names[0] != "serial_number" || names[len(names)-1] != "flags" {
+		t.Errorf("expected the columns from serial_number to flags, got %v", names)
+	}
+	seen := map[string]bool{}
+	for _, name := range names {
+		if name == "" || seen[name] {
+			t.Errorf("expected a distinct db tag on every field, got %q twice or empty", name)
+		}
+		seen[name] = true
+	}
+
+	// the columns of the house sales only are nulls of the other tables
+	list := selectList(store.DialectSQLite, Tables["rental"])
+	if !strings.Contains(list, "CAST(NULL AS DOUBLE PRECISION)") || strings.Contains(list, "CAST(balcony_area_sqm") {
+		t.Errorf("expected the house sale columns to be null on rentals, got %s", list)
+	}
+}
+
+func TestRecordCSV(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := newRecordWriter(FormatCSV, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.write(testRecord()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(buf).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 2 || !reflect.DeepEqual(rows[0], header()) {
+		t.Fatalf("expected the header and a row, got %v", rows)
+	}
+	row := map[string]string{}
+	for i, name := range rows[0] {
+		row[name] = rows[1][i]
+	}
+	want := map[string]string{
+		"transaction_date":   "2023-02-01",
+		"building_area_sqm":  "80.5",
+		"number_of_rooms":    "3",
+		"total_price":        "12000000",
+		"unit_price_per_sqm": "",
+		"flags":              "special_relationship|outlier",
+	}
+	for name, value := range want {
+		if row[name] != value {
+			t.Errorf("expected %s to be %q, got %q", name, value, row[name])
+		}
+	}
+}
+
+func TestParquetSchema(t *testing.T) {
+	buf := &bytes.Buffer{}
+	w, err := newRecordWriter(FormatParquet, buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.write(testRecord()); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.close(); err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := buffer.NewBufferFile(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	pr, err := reader.NewParquetColumnReader(file, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pr.ReadStop()
+	if n := pr.GetNumRows(); n != 1 {
+		t.Fatalf("expected a single row, got %d", n)
+	}
+
+	columns := map[string]int64{}
+	types := map[string]parquet.Type{}
+	for i, el := range pr.Footer.Schema[1:] {
+		name := pr.SchemaHandler.GetExName(i + 1)
+		columns[name] = int64(i)
+		types[name] = el.GetType()
+		if el.GetRepetitionType() != parquet.FieldRepetitionType_OPTIONAL {
+			t.Errorf("expected %s to be optional", name)
+		}
+	}
+	if len(columns) != len(recordFields) {
+		t.Fatalf("expected %d columns, got %d", len(recordFields), len(columns))
+	}
+	for name, want := range map[string]parquet.Type{
+		"serial_number":     parquet.Type_BYTE_ARRAY,
+		"transaction_date":  parquet.Type_INT32,
+		"building_area_sqm": parquet.Type_DOUBLE,
+		"number_of_rooms":   parquet.Type_INT32,
+		"total_price":       parquet.Type_INT64,
+	} {
+		if types[name] != want {
+			t.Errorf("expected %s to be %s, got %s", name, want, types[name])
+		}
+	}
+
+	// dates are the days since the epoch, 2023-02-01 is the day 19389
+	for name, want := range map[string]interface{}{"transaction_date": int32(19389), "total_price": int64(12000000), "unit_price_per_sqm": nil} {
+		values, _, _, err := pr.ReadColumnByIndex(columns[name], 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(values) != 1 || values[0] != want {
+			t.Errorf("expected %s to be %v, got %v", name, want, values)
+		}
+	}
+}
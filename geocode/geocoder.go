@@ -0,0 +1,213 @@
+package geocode
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	currentPackage = "github.com/Walker088/gorealestate/geocode"
+
+	ReadReferenceError = "GC00001"
+	LoadReferenceError = "GC00002"
+	GeocodeError       = "GC00003"
+
+	// LevelNone is the level of the transactions matched by no reference
+	LevelNone = "none"
+
+	loadBatchSize = 500
+)
+
+var (
+	tables = []string{"plvr_land_house_sale", "plvr_land_new_house", "plvr_land_rental"}
+
+	// matchSteps run from the most to the least precise, each step only geocodes the rows
+	// left over by the previous ones, a reference matches a transaction at most once, i.e.,
+	// a road section referenced in several villages is ambiguous and left to the road step
+	matchSteps = []matchStep{
+		{
+			level:      "road_section",
+			confidence: 0.9,
+			reference: `
+			SELECT city, district, road, section, MIN(lat) AS lat, MIN(lon) AS lon
+			FROM geo_reference WHERE kind = 'road' AND section > 0
+			GROUP BY 1, 2, 3, 4 HAVING count(*) = 1`,
+			cond: "r.road = t.address_road AND r.section = t.address_section",
+		},
+		{
+			level:      "road",
+			confidence: 0.75,
+			reference: `
+			SELECT city, district, road, AVG(lat) AS lat, AVG(lon) AS lon
+			FROM geo_reference WHERE kind = 'road' GROUP BY 1, 2, 3`,
+			cond: "r.road = t.address_road",
+		},
+		{
+			level:      "village",
+			confidence: 0.6,
+			reference: `
+			SELECT city, district, village, MIN(lat) AS lat, MIN(lon) AS lon
+			FROM geo_reference WHERE kind = 'village'
+			GROUP BY 1, 2, 3 HAVING count(*) = 1`,
+			cond: "r.village = t.address_village",
+		},
+		{
+			level:      "district",
+			confidence: 0.3,
+			reference: `
+			SELECT DISTINCT ON (city, district) city, district, lat, lon
+			FROM (
+				SELECT city, district, lat, lon, 0 AS priority FROM geo_reference WHERE kind = 'district'
+				UNION ALL
+				SELECT city, district, AVG(lat), AVG(lon), 1 FROM geo_reference WHERE kind <> 'district' GROUP BY 1, 2
+			) d
+			ORDER BY city, district, priority`,
+			cond: "TRUE",
+		},
+	}
+)
+
+type matchStep struct {
+	level      string
+	confidence float64
+	reference  string
+	cond       string
+}
+
+type Geocoder struct {
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+}
+
+type Summary struct {
+	Table string `json:"table"`
+	Level string `json:"level"`
+	Rows  int64  `json:"rows"`
+}
+
+func New(pool *pgxpool.Pool, logger *zap.SugaredLogger) *Geocoder {
+	return &Geocoder{
+		pool:   pool,
+		logger: logger,
+	}
+}
+
+// Load upserts the references of a csv or geojson file into geo_reference
+func (g *Geocoder) Load(ctx context.Context, path string, source string) (int, *e.ErrorData) {
+	refs, errData := ReadReferences(path)
+	if errData != nil {
+		return 0, errData
+	}
+	query := `
+	INSERT INTO geo_reference (kind, city, district, village, road, section, lat, lon, source)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	ON CONFLICT (kind, city, district, village, road, section)
+	DO UPDATE SET lat = EXCLUDED.lat, lon = EXCLUDED.lon, source = EXCLUDED.source, loaded_time = now()
+	`
+	err := pgx.BeginFunc(ctx, g.pool, func(tx pgx.Tx) error {
+		batch := &pgx.Batch{}
+		for i := range refs {
+			kind, section := refs[i].normalize()
+			r := refs[i]
+			batch.Queue(query, kind, r.City, r.District, r.Village, r.Road, section, r.Lat, r.Lon, source)
+			if batch.Len() == loadBatchSize || i == len(refs)-1 {
+				if err := tx.SendBatch(ctx, batch).Close(); err != nil {
+					return err
+				}
+				batch = &pgx.Batch{}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, e.Wrap(
+			LoadReferenceError,
+			err,
+			fmt.Sprintf("%s.Load", currentPackage),
+		)
+	}
+	g.logger.Infof("[geocode] %d references loaded from %s", len(refs), path)
+	return len(refs), nil
+}
+
+// Geocode matches the parsed transaction addresses to the references, the rows without a match
+// get the level none and are only matched again once a reference is loaded since, reset clears
+// the coordinates geocoded before so that they are matched again
+func (g *Geocoder) Geocode(ctx context.Context, reset bool) ([]Summary, *e.ErrorData) {
+	summaries := []Summary{}
+	// the version of the references is the time the latest one was loaded
+	var version *time.Time
+	if err := g.pool.QueryRow(ctx, "SELECT max(loaded_time) FROM geo_reference").Scan(&version); err != nil {
+		return nil, e.Wrap(
+			GeocodeError,
+			err,
+			fmt.Sprintf("%s.Geocode", currentPackage),
+		)
+	}
+	if version == nil {
+		g.logger.Info("[geocode] no reference loaded, transactions not geocoded")
+		return summaries, nil
+	}
+	for _, table := range tables {
+		if reset {
+			query := fmt.Sprintf(`
+			UPDATE %s SET lat = NULL, lon = NULL, geocode_level = NULL, geocode_confidence = NULL, geocode_version = NULL
+			WHERE geocode_level IS NOT NULL
+			`, table)
+			if _, err := g.pool.Exec(ctx, query); err != nil {
+				return nil, e.Wrap(
+					GeocodeError,
+					err,
+					fmt.Sprintf("%s.Geocode", currentPackage),
+				)
+			}
+		}
+		for _, step := range matchSteps {
+			query := fmt.Sprintf(`
+			UPDATE %s t
+			SET lat = r.lat, lon = r.lon, geocode_level = $1, geocode_confidence = $2, geocode_version = $3
+			FROM (%s) r, ref_plvr_land_city c
+			WHERE t.lat IS NULL AND (t.geocode_version IS NULL OR t.geocode_version < $3)
+				AND c.city_code = t.city
+				AND r.city = COALESCE(NULLIF(t.address_city, ''), replace(c.city_name_zh, '台', '臺'))
+				AND r.district = t.address_district
+				AND %s
+			`, table, step.reference, step.cond)
+			tag, err := g.pool.Exec(ctx, query, step.level, step.confidence, *version)
+			if err != nil {
+				return nil, e.NewErrorData(
+					GeocodeError,
+					fmt.Sprintf("%s on %s: %s", step.level, table, err.Error()),
+					fmt.Sprintf("%s.Geocode", currentPackage),
+					nil,
+					nil,
+				).WithCause(err)
+			}
+			summaries = append(summaries, Summary{Table: table, Level: step.level, Rows: tag.RowsAffected()})
+		}
+		query := fmt.Sprintf(`
+		UPDATE %s SET geocode_level = $1, geocode_version = $2
+		WHERE lat IS NULL AND (geocode_version IS NULL OR geocode_version < $2)
+		`, table)
+		tag, err := g.pool.Exec(ctx, query, LevelNone, *version)
+		if err != nil {
+			return nil, e.NewErrorData(
+				GeocodeError,
+				fmt.Sprintf("%s on %s: %s", LevelNone, table, err.Error()),
+				fmt.Sprintf("%s.Geocode", currentPackage),
+				nil,
+				nil,
+			).WithCause(err)
+		}
+		summaries = append(summaries, Summary{Table: table, Level: LevelNone, Rows: tag.RowsAffected()})
+	}
+	g.logger.Infof("[geocode] transactions geocoded: %+v", summaries)
+	return summaries, nil
+}
@@ -0,0 +1,162 @@
+package geocode
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gocarina/gocsv"
+
+	"github.com/Walker088/gorealestate/address"
+	"github.com/Walker088/gorealestate/common"
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	KindRoad     = "road"
+	KindVillage  = "village"
+	KindDistrict = "district"
+)
+
+// Reference is a centroid of a road segment, village or district, e.g.,
+//
+//	city,district,village,road,section,lat,lon
+//	臺北市,大安區,,忠孝東路,四,25.0416,121.5436
+type Reference struct {
+	City     string  `csv:"city"`
+	District string  `csv:"district"`
+	Village  string  `csv:"village"`
+	Road     string  `csv:"road"`
+	Section  string  `csv:"section"`
+	Lat      float64 `csv:"lat"`
+	Lon      float64 `csv:"lon"`
+}
+
+type featureCollection struct {
+	Features []struct {
+		Properties map[string]interface{} `json:"properties"`
+		Geometry   struct {
+			Coordinates interface{} `json:"coordinates"`
+		} `json:"geometry"`
+	} `json:"features"`
+}
+
+// ReadReferences reads a csv or geojson reference file by its extension
+func ReadReferences(path string) ([]Reference, *e.ErrorData) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, e.Wrap(
+			ReadReferenceError,
+			err,
+			fmt.Sprintf("%s.ReadReferences", currentPackage),
+		)
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return readCSV(f)
+	case ".json", ".geojson":
+		return readGeoJSON(f)
+	}
+	return nil, e.NewErrorData(
+		ReadReferenceError,
+		fmt.Sprintf("unsupported reference file %s, expect .csv or .geojson", path),
+		fmt.Sprintf("%s.ReadReferences", currentPackage),
+		nil,
+		nil,
+	)
+}
+
+func readCSV(r io.Reader) ([]Reference, *e.ErrorData) {
+	refs := []Reference{}
+	if err := gocsv.Unmarshal(r, &refs); err != nil {
+		return nil, e.Wrap(
+			ReadReferenceError,
+			err,
+			fmt.Sprintf("%s.readCSV", currentPackage),
+		)
+	}
+	return refs, nil
+}
+
+// readGeoJSON takes the properties of each feature and the mean of its coordinates as the centroid
+func readGeoJSON(r io.Reader) ([]Reference, *e.ErrorData) {
+	var fc featureCollection
+	if err := json.NewDecoder(r).Decode(&fc); err != nil {
+		return nil, e.Wrap(
+			ReadReferenceError,
+			err,
+			fmt.Sprintf("%s.readGeoJSON", currentPackage),
+		)
+	}
+	prop := func(props map[string]interface{}, key string) string {
+		if v, ok := props[key]; ok && v != nil {
+			return fmt.Sprint(v)
+		}
+		return ""
+	}
+
+	refs := []Reference{}
+	for _, feature := range fc.Features {
+		var lat, lon float64
+		var n int
+		var walk func(c interface{})
+		walk = func(c interface{}) {
+			coords, ok := c.([]interface{})
+			if !ok || len(coords) == 0 {
+				return
+			}
+			if x, ok := coords[0].(float64); ok && len(coords) >= 2 {
+				y, _ := coords[1].(float64)
+				lon, lat, n = lon+x, lat+y, n+1
+				return
+			}
+			for _, child := range coords {
+				walk(child)
+			}
+		}
+		walk(feature.Geometry.Coordinates)
+		if n == 0 {
+			continue
+		}
+		refs = append(refs, Reference{
+			City:     prop(feature.Properties, "city"),
+			District: prop(feature.Properties, "district"),
+			Village:  prop(feature.Properties, "village"),
+			Road:     prop(feature.Properties, "road"),
+			Section:  prop(feature.Properties, "section"),
+			Lat:      lat / float64(n),
+			Lon:      lon / float64(n),
+		})
+	}
+	return refs, nil
+}
+
+// normalize aligns the reference with the parsed transaction addresses, roads with
+// sections, e.g., 忠孝東路四段, are split into the road and the section
+func (r *Reference) normalize() (kind string, section int) {
+	r.City = address.Normalize(r.City)
+	r.District = address.Normalize(r.District)
+	r.Village = address.Normalize(r.Village)
+	if r.Road != "" {
+		parsed := address.Parse(r.Road, "")
+		if parsed.Road != "" {
+			r.Road, section = parsed.Road, parsed.Section
+		}
+	}
+	if s := strings.TrimSuffix(address.Normalize(r.Section), "段"); s != "" {
+		section, _ = common.ChineseNumeralToInt(s)
+	}
+
+	switch {
+	case r.Road != "":
+		return KindRoad, section
+	case r.Village != "":
+		return KindVillage, 0
+	}
+	return KindDistrict, 0
+}
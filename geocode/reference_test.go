@@ -0,0 +1,68 @@
+package geocode
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeReference(t *testing.T, name string, content string) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(file, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestReadReferences(t *testing.T) {
+	csv := writeReference(t, "refs.csv", "city,district,village,road,section,lat,lon\n臺北市,大安區,,忠孝東路,四,25.0416,121.5436\n")
+	refs, errData := ReadReferences(csv)
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	want := []Reference{{City: "臺北市", District: "大安區", Road: "忠孝東路", Section: "四", Lat: 25.0416, Lon: 121.5436}}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("expected %+v, got %+v", want, refs)
+	}
+
+	// the centroid is the mean of the coordinates, the features without any are skipped
+	geojson := writeReference(t, "refs.geojson", `{"features": [
+		{"properties": {"city": "臺北市", "district": "大安區", "village": "錦安里"},
+		 "geometry": {"coordinates": [[[121.0, 25.0], [122.0, 25.0], [122.0, 26.0], [121.0, 26.0]]]}},
+		{"properties": {"city": "臺北市"}, "geometry": {"coordinates": []}}
+	]}`)
+	refs, errData = ReadReferences(geojson)
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	want = []Reference{{City: "臺北市", District: "大安區", Village: "錦安里", Lat: 25.5, Lon: 121.5}}
+	if !reflect.DeepEqual(refs, want) {
+		t.Errorf("expected %+v, got %+v", want, refs)
+	}
+
+	if _, errData := ReadReferences(writeReference(t, "refs.txt", "")); errData == nil || errData.Code != ReadReferenceError {
+		t.Errorf("expected a %s, got %v", ReadReferenceError, errData)
+	}
+}
+
+func TestReferenceNormalize(t *testing.T) {
+	for _, c := range []struct {
+		ref     Reference
+		kind    string
+		section int
+		road    string
+	}{
+		{ref: Reference{City: "台北市", District: "大安區", Road: "忠孝東路四段"}, kind: KindRoad, section: 4, road: "忠孝東路"},
+		{ref: Reference{City: "台北市", District: "大安區", Road: "忠孝東路", Section: "４段"}, kind: KindRoad, section: 4, road: "忠孝東路"},
+		{ref: Reference{City: "台北市", District: "大安區", Village: "錦安里"}, kind: KindVillage},
+		{ref: Reference{City: "台北市", District: "大安區"}, kind: KindDistrict},
+	} {
+		r := c.ref
+		kind, section := r.normalize()
+		if kind != c.kind || section != c.section || r.Road != c.road || r.City != "臺北市" {
+			t.Errorf("expected %+v to be the %s %s section %d, got the %s %s section %d of %s", c.ref, c.kind, c.road, c.section, kind, r.Road, section, r.City)
+		}
+	}
+}
@@ -4,33 +4,75 @@ import (
 	"net"
 	"net/http"
 	"net/url"
+	"sync"
 	"time"
+
+	"github.com/Walker088/gorealestate/config"
 )
 
 const (
 	DefaultUserAgent        = "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/110.0.0.0 Safari/537.36 Edg/110.0.1587.69"
 	DefaultMaxBody    int64 = 1024 * 1024 * 1024 // 1GB
 	DefaultRetryTimes       = 2
+	DefaultTimeout          = 180 * time.Second // Google's timeout
 )
 
 var (
 	DefaultRetryHTTPCodes = []int{500, 502, 503, 504, 522, 524, 408}
 )
 
+// Client is safe for concurrent use, its options can be replaced while running
 type Client struct {
-	*http.Client
-	opt *Options
+	mu     sync.RWMutex
+	client *http.Client
+	opt    *Options
 }
 
 // Options is custom http.client options
 type Options struct {
+	Timeout        time.Duration
 	MaxBodySize    int64
 	RetryTimes     int
 	RetryHTTPCodes []int
+	UserAgent      string
 	ProxyFunc      func(*http.Request) (*url.URL, error)
 }
 
+// NewOptions converts the http section of the config, the proxy is validated on load
+func NewOptions(cfg *config.HTTPConfig) *Options {
+	opt := &Options{
+		Timeout:        cfg.Timeout,
+		MaxBodySize:    cfg.MaxBodySize,
+		RetryTimes:     cfg.RetryTimes,
+		RetryHTTPCodes: cfg.RetryHTTPCodes,
+		UserAgent:      cfg.UserAgent,
+	}
+	if proxy, err := url.Parse(cfg.Proxy); err == nil && cfg.Proxy != "" {
+		opt.ProxyFunc = http.ProxyURL(proxy)
+	}
+	return opt
+}
+
 func New(opt *Options) *Client {
+	c := &Client{}
+	c.SetOptions(opt)
+	return c
+}
+
+// SetOptions replaces the underlying client, the requests in flight complete with the former one
+func (c *Client) SetOptions(opt *Options) {
+	if opt.Timeout <= 0 {
+		opt.Timeout = DefaultTimeout
+	}
+	if opt.MaxBodySize <= 0 {
+		opt.MaxBodySize = DefaultMaxBody
+	}
+	if opt.RetryHTTPCodes == nil {
+		opt.RetryHTTPCodes = DefaultRetryHTTPCodes
+	}
+	if opt.UserAgent == "" {
+		opt.UserAgent = DefaultUserAgent
+	}
 	var proxyFunction = http.ProxyFromEnvironment
 	if opt.ProxyFunc != nil {
 		proxyFunction = opt.ProxyFunc
@@ -50,10 +92,20 @@ func New(opt *Options) *Client {
 			TLSHandshakeTimeout:   10 * time.Second,
 			ExpectContinueTimeout: 1 * time.Second,
 		},
-		Timeout: time.Second * 180, // Google's timeout
+		Timeout: opt.Timeout,
 	}
-	return &Client{
-		Client: httpClient,
-		opt:    opt,
+
+	c.mu.Lock()
+	previous := c.client
+	c.client, c.opt = httpClient, opt
+	c.mu.Unlock()
+	if previous != nil {
+		previous.CloseIdleConnections()
 	}
 }
+
+func (c *Client) current() (*http.Client, *Options) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.client, c.opt
+}
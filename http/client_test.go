@@ -0,0 +1,52 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+// TestSetOptionsWhileRunning replaces the options while requests are running, as the config
+// watcher does, the requests started after it use the new ones
+func TestSetOptionsWhileRunning(t *testing.T) {
+	var calls atomic.Int64
+	agents := sync.Map{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		agents.Store(r.Header.Get("User-Agent"), true)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	c := New(&Options{UserAgent: "before", RetryTimes: 0})
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if i == 4 {
+				c.SetOptions(&Options{UserAgent: "after", RetryTimes: 0})
+			}
+			if _, status, err := c.Get(context.Background(), srv.URL); err != nil || status != http.StatusServiceUnavailable {
+				t.Errorf("expected the status 503, got %d %v", status, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	calls.Store(0)
+	agents = sync.Map{}
+	c.SetOptions(&Options{UserAgent: "retried", RetryTimes: 1})
+	if _, status, _ := c.Get(context.Background(), srv.URL); status != http.StatusServiceUnavailable {
+		t.Errorf("expected the status 503, got %d", status)
+	}
+	if n := calls.Load(); n != 2 {
+		t.Errorf("expected the new retry times to take effect, got %d calls", n)
+	}
+	if _, ok := agents.Load("retried"); !ok {
+		t.Error("expected the new user agent to take effect")
+	}
+}
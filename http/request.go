@@ -1 +1,74 @@
 package http
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Walker088/gorealestate/metrics"
+)
+
+// Get downloads the body of url, the request is retried on the network errors and the
+// retry http codes, the status of the last response is returned along with its body
+func (c *Client) Get(ctx context.Context, url string) ([]byte, int, error) {
+	var (
+		body   []byte
+		status int
+		err    error
+	)
+	client, opt := c.current()
+	for attempt := 0; attempt <= opt.RetryTimes; attempt++ {
+		if attempt > 0 {
+			retried := strconv.Itoa(status)
+			if err != nil {
+				retried = "error"
+			}
+			metrics.HTTPRetries.WithLabelValues(retried).Inc()
+			select {
+			case <-ctx.Done():
+				return nil, 0, ctx.Err()
+			case <-time.After(time.Duration(attempt) * time.Second):
+			}
+		}
+		body, status, err = get(ctx, client, opt, url)
+		if err == nil && !retryable(opt, status) {
+			return body, status, nil
+		}
+	}
+	return body, status, err
+}
+
+func get(ctx context.Context, client *http.Client, opt *Options, url string) ([]byte, int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("User-Agent", opt.UserAgent)
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, opt.MaxBodySize+1))
+	metrics.DownloadedBytes.Add(float64(len(body)))
+	if err != nil {
+		return nil, resp.StatusCode, err
+	}
+	if int64(len(body)) > opt.MaxBodySize {
+		return nil, resp.StatusCode, fmt.Errorf("response body of %s exceeds %d bytes", url, opt.MaxBodySize)
+	}
+	return body, resp.StatusCode, nil
+}
+
+func retryable(opt *Options, status int) bool {
+	for _, code := range opt.RetryHTTPCodes {
+		if code == status {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,33 @@
+package logger
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+// keys of the structured fields, the json file log can be filtered by them, e.g.,
+// jq 'select(.season == "112S1")'
+const (
+	FieldRunID       = "run_id"
+	FieldSeason      = "season"
+	FieldCity        = "city"
+	FieldFile        = "file"
+	FieldRow         = "row"
+	FieldErrorCode   = "error_code"
+	FieldErrorTarget = "error_target"
+)
+
+// NewRunID returns an id of a crawl run, i.e., its start time followed by a random suffix
+func NewRunID() string {
+	b := make([]byte, 4)
+	rand.Read(b)
+	return time.Now().Format("20060102T150405") + "-" + hex.EncodeToString(b)
+}
+
+// ErrorFields returns the code and the target of err as structured fields
+func ErrorFields(err *e.ErrorData) []interface{} {
+	return []interface{}{FieldErrorCode, err.Code, FieldErrorTarget, err.Target}
+}
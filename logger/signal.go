@@ -0,0 +1,37 @@
+//go:build !windows
+
+package logger
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/config"
+)
+
+// HandleSignals switches to the debug level on SIGUSR1 and back to the configured levels on
+// SIGUSR2 until stop is closed, e.g., kill -USR1 <pid>
+func (l *Levels) HandleSignals(logger *zap.SugaredLogger, configured func() *config.LoggerConfig, stop <-chan struct{}) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1, syscall.SIGUSR2)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-stop:
+				return
+			case sig := <-signals:
+				if sig == syscall.SIGUSR1 {
+					l.Debug()
+					logger.Info("[logger] SIGUSR1 received, switched to debug")
+					continue
+				}
+				l.Apply(configured())
+				logger.Infof("[logger] SIGUSR2 received, switched back to console %s and file %s", l.Console.Level(), l.File.Level())
+			}
+		}
+	}()
+}
@@ -0,0 +1,12 @@
+package logger
+
+import (
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/config"
+)
+
+// HandleSignals is a no-op since windows has no SIGUSR1 and SIGUSR2, use the admin endpoint instead
+func (l *Levels) HandleSignals(logger *zap.SugaredLogger, configured func() *config.LoggerConfig, stop <-chan struct{}) {
+	logger.Debug("[logger] log level signals are not supported on windows")
+}
@@ -1,9 +1,10 @@
 package logger
 
 import (
-	"fmt"
 	"io"
+	"net/http"
 	"os"
+	"path/filepath"
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
@@ -12,12 +13,6 @@ import (
 	"github.com/Walker088/gorealestate/config"
 )
 
-const (
-	fileOutputPath = "./logs"
-	logFileName    = "gorealestate.log"
-	errFileName    = "gorealestate_err.log"
-)
-
 type WriteSyncer struct {
 	io.Writer
 }
@@ -26,14 +21,14 @@ func (ws WriteSyncer) Sync() error {
 	return nil
 }
 
-func getWriteSyncer(filename string) zapcore.WriteSyncer {
+func getWriteSyncer(filename string, cfg *config.LoggerConfig) zapcore.WriteSyncer {
 	var ioWriter = &lumberjack.Logger{
 		Filename:   filename,
-		MaxSize:    10, // MB
-		MaxBackups: 3,  // number of backups
-		MaxAge:     14, //days
+		MaxSize:    cfg.MaxSizeMB,  // MB
+		MaxBackups: cfg.MaxBackups, // number of backups
+		MaxAge:     cfg.MaxAgeDays, // days
 		LocalTime:  true,
-		Compress:   false, // disabled by default
+		Compress:   cfg.Compress,
 	}
 	var sw = WriteSyncer{
 		ioWriter,
@@ -41,21 +36,55 @@ func getWriteSyncer(filename string) zapcore.WriteSyncer {
 	return sw
 }
 
-func New(cfg *config.LoggerConfig) *zap.SugaredLogger {
-	if _, err := os.Stat(fileOutputPath); os.IsNotExist(err) {
-		os.MkdirAll(fileOutputPath, 0700)
+// Levels are the levels of the console and file cores, they can be changed while running,
+// the error file always takes the errors and above
+type Levels struct {
+	Console zap.AtomicLevel
+	File    zap.AtomicLevel
+}
+
+// Apply swaps the levels of the cores to the ones of cfg
+func (l *Levels) Apply(cfg *config.LoggerConfig) {
+	l.Console.SetLevel(cfg.GetConsoleLogLvl())
+	l.File.SetLevel(cfg.GetFileLogLvl())
+}
+
+// Debug switches both cores to the debug level until the next Apply
+func (l *Levels) Debug() {
+	l.Console.SetLevel(zapcore.DebugLevel)
+	l.File.SetLevel(zapcore.DebugLevel)
+}
+
+// Handler serves the levels, GET /console returns {"level":"info"} and PUT /console
+// with the same body changes it, likewise for /file
+func (l *Levels) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.Handle("/console", l.Console)
+	mux.Handle("/file", l.File)
+	return mux
+}
+
+func New(cfg *config.LoggerConfig) (*zap.SugaredLogger, *Levels) {
+	if _, err := os.Stat(cfg.Dir); os.IsNotExist(err) {
+		os.MkdirAll(cfg.Dir, 0700)
 	}
 
 	var logger *zap.Logger
 
-	fn := fmt.Sprintf("%s/%s", fileOutputPath, logFileName)
 	consoleEnc := zapcore.NewConsoleEncoder(cfg.EncoderConfig)
 	fileEnc := zapcore.NewJSONEncoder(cfg.EncoderConfig)
-	core := zapcore.NewTee(
-		zapcore.NewCore(consoleEnc, zapcore.AddSync(os.Stdout), zap.NewAtomicLevelAt(cfg.GetConsoleLogLvl())),
-		zapcore.NewCore(fileEnc, zapcore.AddSync(getWriteSyncer(fn)), zap.NewAtomicLevelAt(cfg.GetFileLogLvl())),
-	)
+	levels := &Levels{
+		Console: zap.NewAtomicLevelAt(cfg.GetConsoleLogLvl()),
+		File:    zap.NewAtomicLevelAt(cfg.GetFileLogLvl()),
+	}
+	cores := []zapcore.Core{
+		zapcore.NewCore(consoleEnc, zapcore.AddSync(os.Stdout), levels.Console),
+		zapcore.NewCore(fileEnc, getWriteSyncer(filepath.Join(cfg.Dir, cfg.File), cfg), levels.File),
+	}
+	if cfg.ErrorFile != "" {
+		cores = append(cores, zapcore.NewCore(fileEnc, getWriteSyncer(filepath.Join(cfg.Dir, cfg.ErrorFile), cfg), zap.ErrorLevel))
+	}
 
-	logger = zap.New(core)
-	return logger.Sugar()
+	logger = zap.New(zapcore.NewTee(cores...))
+	return logger.Sugar(), levels
 }
@@ -2,70 +2,255 @@ package main
 
 import (
 	"context"
+	"database/sql"
+	"flag"
 	"fmt"
 	"os"
-	"os/signal"
+	"path/filepath"
+	"strings"
 
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/api"
 	"github.com/Walker088/gorealestate/config"
-	"github.com/Walker088/gorealestate/crawler/plvr"
 	"github.com/Walker088/gorealestate/database"
+	e "github.com/Walker088/gorealestate/error"
 	"github.com/Walker088/gorealestate/logger"
+	"github.com/Walker088/gorealestate/metrics"
 	"github.com/Walker088/gorealestate/migrations"
+	"github.com/Walker088/gorealestate/store"
 )
 
 const (
-	envFile = ".env.development"
+	// configFile and the file of the profile, e.g., config.production.yaml, are optional,
+	// the defaults and the env vars are used without them
+	configFile = "config.yaml"
+	// legacyFile is the former .env file, read when neither configFile nor the file of the
+	// profile is found, i.e., an upgraded deployment keeps its database
+	legacyFile = ".env.development"
+)
+
+var (
+	profile = flag.String("profile", defaultProfile(), "config profile, development, staging or production, defaults to $"+config.ProfileEnv)
+	cfgFile = flag.String("config", "", "config file, replaces config.yaml and the file of the profile")
 )
 
+func defaultProfile() string {
+	if p := os.Getenv(config.ProfileEnv); p != "" {
+		return p
+	}
+	return config.ProfileDevelopment
+}
+
+type command struct {
+	name    string
+	usage   string
+	run     func(app *App, args []string)
+	offline bool // only the config and the logger are loaded, i.e., no database nor migration
+}
+
+var commands = []command{
+	{"crawl", "download the plvr seasons and import them into the database (default)", runCrawl, false},
+	{"serve", "start the api server", runServe, false},
+	{"yield", "estimate gross rental yield per district, building type and season", runYield, false},
+	{"comps", "search comparable sales of a target property", runComps, false},
+	{"flag", "flag the outliers and non arm's length transactions", runFlag, false},
+	{"repeat-sales", "list consecutive sales of the same unit and their annualized return", runRepeatSales, false},
+	{"address", "parse an address, or backfill the address columns of the imported transactions", runAddress, false},
+	{"geocode", "load a geocoding reference file and geocode the transactions", runGeocode, false},
+	{"export", "export the transactions or the district statistics to a file", runExport, false},
+	{"report", "show the reports of the crawl runs, rows and errors per season and file", runReport, false},
+	{"migrate", "show the schema version, migrate up, down or to a version, force a dirty version", runMigrate, true},
+	{"config", "print or validate the effective configuration", runConfig, true},
+}
+
+// App holds the components shared by all the commands, either pool or sqlite is set after the
+// driver of the config
+type App struct {
+	rootDir string
+	config  *config.AppConfig
+	logger  *zap.SugaredLogger
+	levels  *logger.Levels
+	pool    *database.PgPool
+	sqlite  *sql.DB
+	sm      *migrations.SchemaManager
+}
+
 func main() {
-	deadlineChannel := make(chan os.Signal, 1)
-	signal.Notify(deadlineChannel, os.Interrupt)
+	flag.Usage = usage
+	global, rest := splitGlobalFlags(os.Args[1:])
+	flag.CommandLine.Parse(global)
+	name, args := "crawl", rest
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		name, args = args[0], args[1:]
+	}
+	for _, cmd := range commands {
+		if cmd.name == name {
+			app := newApp(cmd.offline)
+			defer app.Close()
+			cmd.run(app, args)
+			return
+		}
+	}
+	usage()
+	os.Exit(2)
+}
 
-	rootDir, _ := os.Getwd()
-	c, err := config.New(fmt.Sprintf("%s/%s", rootDir, envFile))
-	if err != nil {
-		fmt.Println(err.ToString())
-		panic(err)
+// splitGlobalFlags separates the leading global flags, e.g., -profile, from the rest, the
+// flags unknown to the command line go along with the command, i.e., -daemon runs crawl -daemon
+func splitGlobalFlags(args []string) ([]string, []string) {
+	i := 0
+	for i < len(args) && strings.HasPrefix(args[i], "-") {
+		if args[i] == "--" {
+			return args[:i], args[i+1:]
+		}
+		name, _, hasValue := strings.Cut(strings.TrimLeft(args[i], "-"), "=")
+		f := flag.Lookup(name)
+		if f == nil && name != "h" && name != "help" {
+			break
+		}
+		i++
+		if f != nil && !hasValue && i < len(args) {
+			i++ // the value of the flag, e.g., -profile production
+		}
+	}
+	return args[:i], args[i:]
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %s [-profile name] [-config file] <command> [flags]\n\nCommands:\n", os.Args[0])
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "  %-10s %s\n", cmd.name, cmd.usage)
 	}
-	l := logger.New(c.GetLoggerConfig())
-	defer l.Sync()
+	fmt.Fprintln(os.Stderr, "\nFlags:")
+	flag.PrintDefaults()
+}
 
-	sm, err := migrations.New(rootDir, c.GetPgConfig(), l)
+func newApp(offline bool) *App {
+	rootDir, _ := os.Getwd()
+	files := []string{}
+	if *cfgFile != "" {
+		files = append(files, *cfgFile)
+	} else {
+		ext := filepath.Ext(configFile)
+		for _, name := range []string{configFile, fmt.Sprintf("%s.%s%s", strings.TrimSuffix(configFile, ext), *profile, ext)} {
+			path := fmt.Sprintf("%s/%s", rootDir, name)
+			if _, err := os.Stat(path); err == nil {
+				files = append(files, path)
+			}
+		}
+		legacy := fmt.Sprintf("%s/%s", rootDir, legacyFile)
+		if _, err := os.Stat(legacy); err == nil && len(files) == 0 {
+			fmt.Fprintf(os.Stderr, "%s not found, reading the former %s, see the README to move it to %s\n", configFile, legacyFile, configFile)
+			files = append(files, legacy)
+		}
+	}
+	c, err := config.New(*profile, files...)
 	if err != nil {
-		l.DPanic(err.ToString())
+		exitConfigError(err)
 	}
-	defer sm.Stop()
-	if err := sm.Migrate(); err != nil {
-		l.DPanic(err.ToString())
+	l, levels := logger.New(c.GetLoggerConfig())
+	if offline {
+		return &App{
+			rootDir: rootDir,
+			config:  c,
+			logger:  l,
+			levels:  levels,
+		}
 	}
 
-	pool, err := database.New(c.GetPgConfig(), l)
+	app := &App{
+		rootDir: rootDir,
+		config:  c,
+		logger:  l,
+		levels:  levels,
+	}
+	// the pool waits for the database to be ready before the migrations connect to it
+	if c.GetPgConfig().Driver == config.DriverPostgres {
+		app.pool, err = database.New(c.GetPgConfig(), l)
+		if err != nil {
+			app.exit(err)
+		}
+		metrics.Registry.MustRegister(database.NewPoolCollector(app.pool))
+	}
+	app.sm, err = migrations.New(c.GetPgConfig(), l)
 	if err != nil {
-		l.DPanicf("init db conn pool error: %w", err)
+		app.exit(err)
 	}
-	defer pool.ShutDownPool()
+	if err := app.sm.Migrate(); err != nil {
+		app.exit(err)
+	}
+	if c.GetPgConfig().Driver == config.DriverSQLite {
+		app.sqlite, err = database.NewSQLite(c.GetPgConfig(), l)
+		if err != nil {
+			app.exit(err)
+		}
+	}
+	return app
+}
+
+// exit logs err, closes what is already opened and exits with status 1, i.e., the commands
+// never start without a database
+func (a *App) exit(err *e.ErrorData) {
+	a.logger.Error(err.ToString())
+	a.Close()
+	os.Exit(1)
+}
 
-	l.Info("welcome to gorealestate")
+// database returns the store and querier of the configured driver logging through l
+func (a *App) database(l *zap.SugaredLogger) store.Database {
+	if a.sqlite != nil {
+		return store.NewSQLite(a.sqlite, l)
+	}
+	return store.NewPostgres(a.pool.GetPool(), l)
+}
 
-	ctx, cancel := context.WithCancel(context.Background())
-	defer cancel()
+// daemon sets up the runtime controls of the long running commands until stop is closed:
+// the config files are reloaded on changes and swap the log levels, the commands register
+// their own listeners with OnChange, SIGUSR1 and SIGUSR2 switch to debug and back, and the
+// admin server is started
+func (a *App) daemon(stop <-chan struct{}) {
+	a.config.OnChange(func(c *config.AppConfig) {
+		a.levels.Apply(c.GetLoggerConfig())
+	})
+	if err := a.config.WatchConfig(a.logger, stop); err != nil {
+		a.logger.Error(err.ToString())
+	}
+	a.levels.HandleSignals(a.logger, a.config.GetLoggerConfig, stop)
+	a.admin(stop)
+}
 
-	crawler := plvr.New(ctx, cancel, rootDir, l, pool.GetPool())
-	go crawler.Start()
-	for {
-		select {
-		case <-deadlineChannel:
-			l.Info("interrupt signal received")
-			crawler.Stop()
-			return
-		case <-ctx.Done():
-			l.Infof("download finished")
-			return
-		case err := <-crawler.ErrorsCh:
-			l.Infof("[main] error occurred: %s", err)
-		case downloaded := <-crawler.ResultsCh:
-			l.Infof("[main] downloaded: %s", downloaded)
-		}
+// admin starts the admin server until stop is closed when admin.addr is set, it exposes the
+// log levels, the prometheus metrics, and the health and stats of the pool on postgres
+func (a *App) admin(stop <-chan struct{}) {
+	if a.config.GetAdminConfig().Addr == "" {
+		return
 	}
+	admin := api.NewAdmin(a.config.GetAdminConfig(), a.logger, a.levels, a.pool)
+	go func() {
+		if err := admin.Start(); err != nil {
+			a.logger.Error(err.ToString())
+		}
+	}()
+	go func() {
+		<-stop
+		ctx, cancel := context.WithTimeout(context.Background(), a.config.GetServerConfig().ShutdownTimeout)
+		defer cancel()
+		if err := admin.Stop(ctx); err != nil {
+			a.logger.Error(err.ToString())
+		}
+	}()
+}
 
+func (a *App) Close() {
+	if a.pool != nil {
+		a.pool.ShutDownPool()
+	}
+	if a.sqlite != nil {
+		a.sqlite.Close()
+	}
+	if a.sm != nil {
+		a.sm.Stop()
+	}
+	a.logger.Sync()
 }
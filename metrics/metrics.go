@@ -0,0 +1,99 @@
+package metrics
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const (
+	// Namespace prefixes every metric of the application, e.g., gorealestate_crawler_seasons_total
+	Namespace = "gorealestate"
+
+	SeasonImported = "imported"
+	SeasonSkipped  = "skipped" // already imported by a previous run
+	SeasonFailed   = "failed"
+
+	RowInserted = "inserted"
+	RowUpdated  = "updated"
+	RowSkipped  = "skipped"
+	RowRejected = "rejected"
+)
+
+var (
+	// Registry holds the metrics served on /metrics, the collectors of the other packages,
+	// e.g., the pool stats, are registered on it
+	Registry = prometheus.NewRegistry()
+
+	factory = promauto.With(Registry)
+
+	SeasonsProcessed = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "crawler",
+		Name:      "seasons_total",
+		Help:      "Seasons processed by the crawler by result, imported, skipped or failed.",
+	}, []string{"result"})
+	SeasonDuration = factory.NewHistogram(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "crawler",
+		Name:      "season_duration_seconds",
+		Help:      "Duration of the download and the import of a season.",
+		Buckets:   prometheus.ExponentialBuckets(1, 2, 12),
+	})
+
+	DownloadedBytes = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "downloaded_bytes_total",
+		Help:      "Bytes of the response bodies downloaded by the http client.",
+	})
+	HTTPRetries = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "http",
+		Name:      "retries_total",
+		Help:      "Retried requests of the http client by the status of the failed attempt, error for the network errors.",
+	}, []string{"status"})
+
+	RowsParsed = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "ingest",
+		Name:      "rows_parsed_total",
+		Help:      "Rows parsed from the plvr csv files by file family and city code.",
+	}, []string{"family", "city"})
+	RowsSaved = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: Namespace,
+		Subsystem: "ingest",
+		Name:      "rows_saved_total",
+		Help:      "Parsed rows by file family, city code and result, inserted, updated when imported with other values, skipped when already imported or rejected.",
+	}, []string{"family", "city", "result"})
+	IngestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "ingest",
+		Name:      "file_duration_seconds",
+		Help:      "Duration of the parsing and the saving of a plvr csv file by file family.",
+		Buckets:   prometheus.ExponentialBuckets(0.1, 2, 12),
+	}, []string{"family"})
+
+	APIRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: Namespace,
+		Subsystem: "api",
+		Name:      "request_duration_seconds",
+		Help:      "Latency of the api requests by route, method and status.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "status"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics of Registry in the prometheus text format
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+}
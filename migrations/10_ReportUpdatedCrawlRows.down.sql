@@ -0,0 +1,2 @@
+ALTER TABLE crawl_run_file DROP COLUMN IF EXISTS rows_updated;
+COMMENT ON COLUMN crawl_run_file.rows_skipped IS 'rows already imported, the import never updates them';
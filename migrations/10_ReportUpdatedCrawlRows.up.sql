@@ -0,0 +1,3 @@
+ALTER TABLE crawl_run_file ADD COLUMN IF NOT EXISTS rows_updated INT NOT NULL DEFAULT 0;
+COMMENT ON COLUMN crawl_run_file.rows_updated IS 'rows already imported with other values, e.g., corrected since';
+COMMENT ON COLUMN crawl_run_file.rows_skipped IS 'rows already imported with the same values';
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS plvr_land_house_sale_undated_dedup_idx;
+DROP INDEX IF EXISTS plvr_land_new_house_undated_dedup_idx;
+DROP INDEX IF EXISTS plvr_land_rental_undated_dedup_idx;
@@ -0,0 +1,20 @@
+-- the nulls are distinct in the dedup index, i.e., a row without a transaction date was inserted
+-- again by every import, the undated rows all land in the default partition where a partial
+-- index keys them on the serial number alone, NULLS NOT DISTINCT would take postgres 15
+DO $$
+DECLARE
+  t TEXT;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['plvr_land_house_sale', 'plvr_land_new_house', 'plvr_land_rental'] LOOP
+    -- the undated rows imported more than once are kept once
+    EXECUTE format(
+      'DELETE FROM %I a USING %I b WHERE a.ctid > b.ctid AND a.city = b.city AND a.serial_number = b.serial_number '
+      || 'AND a.transaction_date IS NULL AND b.transaction_date IS NULL',
+      t || '_default', t || '_default'
+    );
+    EXECUTE format(
+      'CREATE UNIQUE INDEX IF NOT EXISTS %I ON %I (city, serial_number) WHERE transaction_date IS NULL',
+      t || '_undated_dedup_idx', t || '_default'
+    );
+  END LOOP;
+END $$;
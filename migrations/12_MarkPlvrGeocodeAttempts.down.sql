@@ -0,0 +1,9 @@
+UPDATE plvr_land_house_sale SET geocode_level = NULL WHERE geocode_level = 'none';
+UPDATE plvr_land_new_house SET geocode_level = NULL WHERE geocode_level = 'none';
+UPDATE plvr_land_rental SET geocode_level = NULL WHERE geocode_level = 'none';
+ALTER TABLE plvr_land_house_sale DROP COLUMN IF EXISTS geocode_version;
+ALTER TABLE plvr_land_new_house DROP COLUMN IF EXISTS geocode_version;
+ALTER TABLE plvr_land_rental DROP COLUMN IF EXISTS geocode_version;
+COMMENT ON COLUMN plvr_land_house_sale.geocode_level IS '地理編碼比對層級, e.g., road_section, road, village, district';
+COMMENT ON COLUMN plvr_land_new_house.geocode_level IS '地理編碼比對層級, e.g., road_section, road, village, district';
+COMMENT ON COLUMN plvr_land_rental.geocode_level IS '地理編碼比對層級, e.g., road_section, road, village, district';
@@ -0,0 +1,11 @@
+-- the rows without a match were matched again by every crawl, the version of the reference they
+-- were last matched against is kept, they are only matched again once a reference is loaded since
+ALTER TABLE plvr_land_house_sale ADD COLUMN IF NOT EXISTS geocode_version TIMESTAMP WITH TIME ZONE;
+ALTER TABLE plvr_land_new_house ADD COLUMN IF NOT EXISTS geocode_version TIMESTAMP WITH TIME ZONE;
+ALTER TABLE plvr_land_rental ADD COLUMN IF NOT EXISTS geocode_version TIMESTAMP WITH TIME ZONE;
+COMMENT ON COLUMN plvr_land_house_sale.geocode_version IS '最近比對的參考點版本, i.e., geo_reference.loaded_time 的最大值';
+COMMENT ON COLUMN plvr_land_new_house.geocode_version IS '最近比對的參考點版本, i.e., geo_reference.loaded_time 的最大值';
+COMMENT ON COLUMN plvr_land_rental.geocode_version IS '最近比對的參考點版本, i.e., geo_reference.loaded_time 的最大值';
+COMMENT ON COLUMN plvr_land_house_sale.geocode_level IS '地理編碼比對層級, e.g., road_section, road, village, district or none';
+COMMENT ON COLUMN plvr_land_new_house.geocode_level IS '地理編碼比對層級, e.g., road_section, road, village, district or none';
+COMMENT ON COLUMN plvr_land_rental.geocode_level IS '地理編碼比對層級, e.g., road_section, road, village, district or none';
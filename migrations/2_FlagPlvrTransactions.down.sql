@@ -0,0 +1,7 @@
+DROP VIEW IF EXISTS plvr_land_house_sale_clean;
+DROP VIEW IF EXISTS plvr_land_new_house_clean;
+DROP VIEW IF EXISTS plvr_land_rental_clean;
+
+ALTER TABLE plvr_land_house_sale DROP COLUMN IF EXISTS flags;
+ALTER TABLE plvr_land_new_house DROP COLUMN IF EXISTS flags;
+ALTER TABLE plvr_land_rental DROP COLUMN IF EXISTS flags;
@@ -0,0 +1,16 @@
+ALTER TABLE plvr_land_house_sale ADD COLUMN IF NOT EXISTS flags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE plvr_land_new_house ADD COLUMN IF NOT EXISTS flags TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE plvr_land_rental ADD COLUMN IF NOT EXISTS flags TEXT[] NOT NULL DEFAULT '{}';
+COMMENT ON COLUMN plvr_land_house_sale.flags IS '異常交易標記, e.g., related_party, price_outlier';
+COMMENT ON COLUMN plvr_land_new_house.flags IS '異常交易標記, e.g., related_party, price_outlier';
+COMMENT ON COLUMN plvr_land_rental.flags IS '異常交易標記, e.g., related_party, price_outlier';
+
+CREATE OR REPLACE VIEW plvr_land_house_sale_clean AS
+SELECT * FROM plvr_land_house_sale WHERE cardinality(flags) = 0;
+CREATE OR REPLACE VIEW plvr_land_new_house_clean AS
+SELECT * FROM plvr_land_new_house WHERE cardinality(flags) = 0;
+CREATE OR REPLACE VIEW plvr_land_rental_clean AS
+SELECT * FROM plvr_land_rental WHERE cardinality(flags) = 0;
+COMMENT ON VIEW plvr_land_house_sale_clean IS '實價登錄 - 房屋買賣交易 (排除異常交易)';
+COMMENT ON VIEW plvr_land_new_house_clean IS '實價登錄 - 新成屋交易 (排除異常交易)';
+COMMENT ON VIEW plvr_land_rental_clean IS '實價登錄 - 租房交易 (排除異常交易)';
@@ -0,0 +1,55 @@
+DROP VIEW IF EXISTS plvr_land_house_sale_clean;
+DROP VIEW IF EXISTS plvr_land_new_house_clean;
+DROP VIEW IF EXISTS plvr_land_rental_clean;
+
+DROP INDEX IF EXISTS plvr_land_house_sale_dedup_idx;
+DROP INDEX IF EXISTS plvr_land_house_sale_address_idx;
+ALTER TABLE plvr_land_house_sale
+  DROP COLUMN IF EXISTS address_normalized,
+  DROP COLUMN IF EXISTS address_city,
+  DROP COLUMN IF EXISTS address_district,
+  DROP COLUMN IF EXISTS address_village,
+  DROP COLUMN IF EXISTS address_road,
+  DROP COLUMN IF EXISTS address_section,
+  DROP COLUMN IF EXISTS address_lane,
+  DROP COLUMN IF EXISTS address_alley,
+  DROP COLUMN IF EXISTS address_number_from,
+  DROP COLUMN IF EXISTS address_number_to,
+  DROP COLUMN IF EXISTS address_floor;
+
+DROP INDEX IF EXISTS plvr_land_new_house_dedup_idx;
+DROP INDEX IF EXISTS plvr_land_new_house_address_idx;
+ALTER TABLE plvr_land_new_house
+  DROP COLUMN IF EXISTS address_normalized,
+  DROP COLUMN IF EXISTS address_city,
+  DROP COLUMN IF EXISTS address_district,
+  DROP COLUMN IF EXISTS address_village,
+  DROP COLUMN IF EXISTS address_road,
+  DROP COLUMN IF EXISTS address_section,
+  DROP COLUMN IF EXISTS address_lane,
+  DROP COLUMN IF EXISTS address_alley,
+  DROP COLUMN IF EXISTS address_number_from,
+  DROP COLUMN IF EXISTS address_number_to,
+  DROP COLUMN IF EXISTS address_floor;
+
+DROP INDEX IF EXISTS plvr_land_rental_dedup_idx;
+DROP INDEX IF EXISTS plvr_land_rental_address_idx;
+ALTER TABLE plvr_land_rental
+  DROP COLUMN IF EXISTS address_normalized,
+  DROP COLUMN IF EXISTS address_city,
+  DROP COLUMN IF EXISTS address_district,
+  DROP COLUMN IF EXISTS address_village,
+  DROP COLUMN IF EXISTS address_road,
+  DROP COLUMN IF EXISTS address_section,
+  DROP COLUMN IF EXISTS address_lane,
+  DROP COLUMN IF EXISTS address_alley,
+  DROP COLUMN IF EXISTS address_number_from,
+  DROP COLUMN IF EXISTS address_number_to,
+  DROP COLUMN IF EXISTS address_floor;
+
+CREATE OR REPLACE VIEW plvr_land_house_sale_clean AS
+SELECT * FROM plvr_land_house_sale WHERE cardinality(flags) = 0;
+CREATE OR REPLACE VIEW plvr_land_new_house_clean AS
+SELECT * FROM plvr_land_new_house WHERE cardinality(flags) = 0;
+CREATE OR REPLACE VIEW plvr_land_rental_clean AS
+SELECT * FROM plvr_land_rental WHERE cardinality(flags) = 0;
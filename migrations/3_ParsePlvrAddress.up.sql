@@ -0,0 +1,88 @@
+ALTER TABLE plvr_land_house_sale
+  ADD COLUMN IF NOT EXISTS address_normalized TEXT,
+  ADD COLUMN IF NOT EXISTS address_city TEXT,
+  ADD COLUMN IF NOT EXISTS address_district TEXT,
+  ADD COLUMN IF NOT EXISTS address_village TEXT,
+  ADD COLUMN IF NOT EXISTS address_road TEXT,
+  ADD COLUMN IF NOT EXISTS address_section INT2,
+  ADD COLUMN IF NOT EXISTS address_lane TEXT,
+  ADD COLUMN IF NOT EXISTS address_alley TEXT,
+  ADD COLUMN IF NOT EXISTS address_number_from INT4,
+  ADD COLUMN IF NOT EXISTS address_number_to INT4,
+  ADD COLUMN IF NOT EXISTS address_floor INT2;
+COMMENT ON COLUMN plvr_land_house_sale.address_normalized IS '正規化門牌';
+COMMENT ON COLUMN plvr_land_house_sale.address_city IS '門牌 - 縣市';
+COMMENT ON COLUMN plvr_land_house_sale.address_district IS '門牌 - 鄉鎮市區';
+COMMENT ON COLUMN plvr_land_house_sale.address_village IS '門牌 - 村里';
+COMMENT ON COLUMN plvr_land_house_sale.address_road IS '門牌 - 路街';
+COMMENT ON COLUMN plvr_land_house_sale.address_section IS '門牌 - 段';
+COMMENT ON COLUMN plvr_land_house_sale.address_lane IS '門牌 - 巷';
+COMMENT ON COLUMN plvr_land_house_sale.address_alley IS '門牌 - 弄';
+COMMENT ON COLUMN plvr_land_house_sale.address_number_from IS '門牌 - 號 (起)';
+COMMENT ON COLUMN plvr_land_house_sale.address_number_to IS '門牌 - 號 (迄)';
+COMMENT ON COLUMN plvr_land_house_sale.address_floor IS '門牌 - 樓';
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_house_sale_dedup_idx
+  ON plvr_land_house_sale (city, address_normalized, transaction_date, total_price, building_area_sqm);
+CREATE INDEX IF NOT EXISTS plvr_land_house_sale_address_idx ON plvr_land_house_sale (address_normalized);
+
+ALTER TABLE plvr_land_new_house
+  ADD COLUMN IF NOT EXISTS address_normalized TEXT,
+  ADD COLUMN IF NOT EXISTS address_city TEXT,
+  ADD COLUMN IF NOT EXISTS address_district TEXT,
+  ADD COLUMN IF NOT EXISTS address_village TEXT,
+  ADD COLUMN IF NOT EXISTS address_road TEXT,
+  ADD COLUMN IF NOT EXISTS address_section INT2,
+  ADD COLUMN IF NOT EXISTS address_lane TEXT,
+  ADD COLUMN IF NOT EXISTS address_alley TEXT,
+  ADD COLUMN IF NOT EXISTS address_number_from INT4,
+  ADD COLUMN IF NOT EXISTS address_number_to INT4,
+  ADD COLUMN IF NOT EXISTS address_floor INT2;
+COMMENT ON COLUMN plvr_land_new_house.address_normalized IS '正規化門牌';
+COMMENT ON COLUMN plvr_land_new_house.address_city IS '門牌 - 縣市';
+COMMENT ON COLUMN plvr_land_new_house.address_district IS '門牌 - 鄉鎮市區';
+COMMENT ON COLUMN plvr_land_new_house.address_village IS '門牌 - 村里';
+COMMENT ON COLUMN plvr_land_new_house.address_road IS '門牌 - 路街';
+COMMENT ON COLUMN plvr_land_new_house.address_section IS '門牌 - 段';
+COMMENT ON COLUMN plvr_land_new_house.address_lane IS '門牌 - 巷';
+COMMENT ON COLUMN plvr_land_new_house.address_alley IS '門牌 - 弄';
+COMMENT ON COLUMN plvr_land_new_house.address_number_from IS '門牌 - 號 (起)';
+COMMENT ON COLUMN plvr_land_new_house.address_number_to IS '門牌 - 號 (迄)';
+COMMENT ON COLUMN plvr_land_new_house.address_floor IS '門牌 - 樓';
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_new_house_dedup_idx
+  ON plvr_land_new_house (city, address_normalized, transaction_date, total_price, building_area_sqm);
+CREATE INDEX IF NOT EXISTS plvr_land_new_house_address_idx ON plvr_land_new_house (address_normalized);
+
+ALTER TABLE plvr_land_rental
+  ADD COLUMN IF NOT EXISTS address_normalized TEXT,
+  ADD COLUMN IF NOT EXISTS address_city TEXT,
+  ADD COLUMN IF NOT EXISTS address_district TEXT,
+  ADD COLUMN IF NOT EXISTS address_village TEXT,
+  ADD COLUMN IF NOT EXISTS address_road TEXT,
+  ADD COLUMN IF NOT EXISTS address_section INT2,
+  ADD COLUMN IF NOT EXISTS address_lane TEXT,
+  ADD COLUMN IF NOT EXISTS address_alley TEXT,
+  ADD COLUMN IF NOT EXISTS address_number_from INT4,
+  ADD COLUMN IF NOT EXISTS address_number_to INT4,
+  ADD COLUMN IF NOT EXISTS address_floor INT2;
+COMMENT ON COLUMN plvr_land_rental.address_normalized IS '正規化門牌';
+COMMENT ON COLUMN plvr_land_rental.address_city IS '門牌 - 縣市';
+COMMENT ON COLUMN plvr_land_rental.address_district IS '門牌 - 鄉鎮市區';
+COMMENT ON COLUMN plvr_land_rental.address_village IS '門牌 - 村里';
+COMMENT ON COLUMN plvr_land_rental.address_road IS '門牌 - 路街';
+COMMENT ON COLUMN plvr_land_rental.address_section IS '門牌 - 段';
+COMMENT ON COLUMN plvr_land_rental.address_lane IS '門牌 - 巷';
+COMMENT ON COLUMN plvr_land_rental.address_alley IS '門牌 - 弄';
+COMMENT ON COLUMN plvr_land_rental.address_number_from IS '門牌 - 號 (起)';
+COMMENT ON COLUMN plvr_land_rental.address_number_to IS '門牌 - 號 (迄)';
+COMMENT ON COLUMN plvr_land_rental.address_floor IS '門牌 - 樓';
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_rental_dedup_idx
+  ON plvr_land_rental (city, address_normalized, transaction_date, total_price, building_area_sqm);
+CREATE INDEX IF NOT EXISTS plvr_land_rental_address_idx ON plvr_land_rental (address_normalized);
+
+-- append the new columns to the clean views
+CREATE OR REPLACE VIEW plvr_land_house_sale_clean AS
+SELECT * FROM plvr_land_house_sale WHERE cardinality(flags) = 0;
+CREATE OR REPLACE VIEW plvr_land_new_house_clean AS
+SELECT * FROM plvr_land_new_house WHERE cardinality(flags) = 0;
+CREATE OR REPLACE VIEW plvr_land_rental_clean AS
+SELECT * FROM plvr_land_rental WHERE cardinality(flags) = 0;
@@ -0,0 +1,31 @@
+DROP VIEW IF EXISTS plvr_land_house_sale_clean;
+DROP VIEW IF EXISTS plvr_land_new_house_clean;
+DROP VIEW IF EXISTS plvr_land_rental_clean;
+
+ALTER TABLE plvr_land_house_sale
+  DROP COLUMN IF EXISTS geom,
+  DROP COLUMN IF EXISTS lat,
+  DROP COLUMN IF EXISTS lon,
+  DROP COLUMN IF EXISTS geocode_level,
+  DROP COLUMN IF EXISTS geocode_confidence;
+ALTER TABLE plvr_land_new_house
+  DROP COLUMN IF EXISTS geom,
+  DROP COLUMN IF EXISTS lat,
+  DROP COLUMN IF EXISTS lon,
+  DROP COLUMN IF EXISTS geocode_level,
+  DROP COLUMN IF EXISTS geocode_confidence;
+ALTER TABLE plvr_land_rental
+  DROP COLUMN IF EXISTS geom,
+  DROP COLUMN IF EXISTS lat,
+  DROP COLUMN IF EXISTS lon,
+  DROP COLUMN IF EXISTS geocode_level,
+  DROP COLUMN IF EXISTS geocode_confidence;
+
+CREATE OR REPLACE VIEW plvr_land_house_sale_clean AS
+SELECT * FROM plvr_land_house_sale WHERE cardinality(flags) = 0;
+CREATE OR REPLACE VIEW plvr_land_new_house_clean AS
+SELECT * FROM plvr_land_new_house WHERE cardinality(flags) = 0;
+CREATE OR REPLACE VIEW plvr_land_rental_clean AS
+SELECT * FROM plvr_land_rental WHERE cardinality(flags) = 0;
+
+DROP TABLE IF EXISTS geo_reference;
@@ -0,0 +1,62 @@
+CREATE TABLE IF NOT EXISTS geo_reference (
+  kind TEXT NOT NULL,
+  city TEXT NOT NULL,
+  district TEXT NOT NULL DEFAULT '',
+  village TEXT NOT NULL DEFAULT '',
+  road TEXT NOT NULL DEFAULT '',
+  section INT2 NOT NULL DEFAULT 0,
+  lat DOUBLE PRECISION NOT NULL,
+  lon DOUBLE PRECISION NOT NULL,
+  source TEXT,
+  loaded_time TIMESTAMP WITH TIME ZONE DEFAULT now(),
+  PRIMARY KEY (kind, city, district, village, road, section)
+);
+COMMENT ON TABLE geo_reference IS '地理編碼參考點, e.g., 路段或村里中心點';
+COMMENT ON COLUMN geo_reference.kind IS 'road, village or district';
+
+ALTER TABLE plvr_land_house_sale
+  ADD COLUMN IF NOT EXISTS lat DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS lon DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS geocode_level TEXT,
+  ADD COLUMN IF NOT EXISTS geocode_confidence REAL;
+COMMENT ON COLUMN plvr_land_house_sale.geocode_level IS '地理編碼比對層級, e.g., road_section, road, village, district';
+
+ALTER TABLE plvr_land_new_house
+  ADD COLUMN IF NOT EXISTS lat DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS lon DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS geocode_level TEXT,
+  ADD COLUMN IF NOT EXISTS geocode_confidence REAL;
+COMMENT ON COLUMN plvr_land_new_house.geocode_level IS '地理編碼比對層級, e.g., road_section, road, village, district';
+
+ALTER TABLE plvr_land_rental
+  ADD COLUMN IF NOT EXISTS lat DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS lon DOUBLE PRECISION,
+  ADD COLUMN IF NOT EXISTS geocode_level TEXT,
+  ADD COLUMN IF NOT EXISTS geocode_confidence REAL;
+COMMENT ON COLUMN plvr_land_rental.geocode_level IS '地理編碼比對層級, e.g., road_section, road, village, district';
+
+-- store the coordinates as geometry as well when postgis is available
+DO $$
+BEGIN
+  BEGIN
+    CREATE EXTENSION IF NOT EXISTS postgis;
+  EXCEPTION WHEN OTHERS THEN
+    RAISE NOTICE 'postgis is not available, skipped the geom columns: %', SQLERRM;
+  END;
+  IF EXISTS (SELECT 1 FROM pg_extension WHERE extname = 'postgis') THEN
+    EXECUTE 'ALTER TABLE plvr_land_house_sale ADD COLUMN IF NOT EXISTS geom geometry(Point, 4326)
+      GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(lon, lat), 4326)) STORED';
+    EXECUTE 'ALTER TABLE plvr_land_new_house ADD COLUMN IF NOT EXISTS geom geometry(Point, 4326)
+      GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(lon, lat), 4326)) STORED';
+    EXECUTE 'ALTER TABLE plvr_land_rental ADD COLUMN IF NOT EXISTS geom geometry(Point, 4326)
+      GENERATED ALWAYS AS (ST_SetSRID(ST_MakePoint(lon, lat), 4326)) STORED';
+  END IF;
+END $$;
+
+-- append the new columns to the clean views
+CREATE OR REPLACE VIEW plvr_land_house_sale_clean AS
+SELECT * FROM plvr_land_house_sale WHERE cardinality(flags) = 0;
+CREATE OR REPLACE VIEW plvr_land_new_house_clean AS
+SELECT * FROM plvr_land_new_house WHERE cardinality(flags) = 0;
+CREATE OR REPLACE VIEW plvr_land_rental_clean AS
+SELECT * FROM plvr_land_rental WHERE cardinality(flags) = 0;
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS crawl_run_error;
+DROP TABLE IF EXISTS crawl_run_file;
+DROP TABLE IF EXISTS crawl_run;
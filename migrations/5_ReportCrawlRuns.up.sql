@@ -0,0 +1,35 @@
+CREATE TABLE IF NOT EXISTS crawl_run (
+  run_id TEXT PRIMARY KEY,
+  status TEXT NOT NULL DEFAULT 'running',
+  started_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT now(),
+  finished_time TIMESTAMP WITH TIME ZONE
+);
+COMMENT ON TABLE crawl_run IS '每次下載匯入的執行紀錄';
+COMMENT ON COLUMN crawl_run.status IS 'running, finished or interrupted';
+
+CREATE TABLE IF NOT EXISTS crawl_run_file (
+  run_id TEXT NOT NULL REFERENCES crawl_run (run_id) ON DELETE CASCADE,
+  season TEXT NOT NULL,
+  file_name TEXT NOT NULL,
+  city TEXT NOT NULL,
+  rows_read INT NOT NULL DEFAULT 0,
+  rows_inserted INT NOT NULL DEFAULT 0,
+  rows_skipped INT NOT NULL DEFAULT 0,
+  rows_rejected INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (run_id, season, file_name)
+);
+COMMENT ON TABLE crawl_run_file IS '每次執行各季各檔案的匯入筆數';
+COMMENT ON COLUMN crawl_run_file.rows_skipped IS 'rows already imported, the import never updates them';
+
+CREATE TABLE IF NOT EXISTS crawl_run_error (
+  run_id TEXT NOT NULL REFERENCES crawl_run (run_id) ON DELETE CASCADE,
+  season TEXT NOT NULL,
+  file_name TEXT NOT NULL DEFAULT '',
+  code TEXT NOT NULL,
+  count INT NOT NULL DEFAULT 0,
+  samples JSONB NOT NULL DEFAULT '[]',
+  PRIMARY KEY (run_id, season, file_name, code)
+);
+COMMENT ON TABLE crawl_run_error IS '每次執行各季各檔案的錯誤代碼統計';
+COMMENT ON COLUMN crawl_run_error.file_name IS 'empty for the errors of the whole season, e.g., the download';
+COMMENT ON COLUMN crawl_run_error.samples IS 'first rows and messages of the code, e.g., [{"row": 3, "message": "..."}]';
@@ -0,0 +1,34 @@
+DO $$
+DECLARE
+  t TEXT;
+  cols TEXT;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['plvr_land_house_sale', 'plvr_land_new_house', 'plvr_land_rental'] LOOP
+    EXECUTE format('DROP VIEW IF EXISTS %I', t || '_clean');
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', t, t || '_partitioned');
+    EXECUTE format('CREATE TABLE %I (LIKE %I INCLUDING ALL EXCLUDING INDEXES)', t, t || '_partitioned');
+
+    SELECT string_agg(quote_ident(column_name), ', ' ORDER BY ordinal_position) INTO cols
+    FROM information_schema.columns
+    WHERE table_schema = current_schema() AND table_name = t || '_partitioned' AND is_generated = 'NEVER';
+    EXECUTE format('INSERT INTO %I (%s) SELECT %s FROM %I', t, cols, cols, t || '_partitioned');
+    -- drops the partitions as well
+    EXECUTE format('DROP TABLE %I', t || '_partitioned');
+
+    EXECUTE format(
+      'CREATE UNIQUE INDEX %I ON %I (city, address_normalized, transaction_date, total_price, building_area_sqm)',
+      t || '_dedup_idx', t
+    );
+    EXECUTE format('CREATE INDEX %I ON %I (address_normalized)', t || '_address_idx', t);
+    EXECUTE format('CREATE VIEW %I AS SELECT * FROM %I WHERE cardinality(flags) = 0', t || '_clean', t);
+  END LOOP;
+END $$;
+
+COMMENT ON TABLE plvr_land_house_sale IS '實價登錄 - 房屋買賣交易';
+COMMENT ON TABLE plvr_land_new_house IS '實價登錄 - 新成屋交易';
+COMMENT ON TABLE plvr_land_rental IS '實價登錄 - 租房交易';
+COMMENT ON VIEW plvr_land_house_sale_clean IS '實價登錄 - 房屋買賣交易 (排除異常交易)';
+COMMENT ON VIEW plvr_land_new_house_clean IS '實價登錄 - 新成屋交易 (排除異常交易)';
+COMMENT ON VIEW plvr_land_rental_clean IS '實價登錄 - 租房交易 (排除異常交易)';
+
+DROP FUNCTION IF EXISTS plvr_create_year_partition(TEXT, INT);
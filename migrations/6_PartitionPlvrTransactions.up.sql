@@ -0,0 +1,66 @@
+-- creates the yearly partition of a transaction table, called while importing before the rows
+-- of a new year are inserted, the rows without a transaction date land in the default partition
+CREATE OR REPLACE FUNCTION plvr_create_year_partition(parent_table TEXT, partition_year INT) RETURNS TEXT AS $$
+DECLARE
+  partition_table TEXT := format('%s_y%s', parent_table, partition_year);
+BEGIN
+  EXECUTE format(
+    'CREATE TABLE IF NOT EXISTS %I PARTITION OF %I FOR VALUES FROM (%L) TO (%L)',
+    partition_table, parent_table, make_date(partition_year, 1, 1), make_date(partition_year + 1, 1, 1)
+  );
+  RETURN partition_table;
+EXCEPTION WHEN duplicate_table OR unique_violation THEN
+  -- created concurrently by another import
+  RETURN partition_table;
+END;
+$$ LANGUAGE plpgsql;
+
+DO $$
+DECLARE
+  t TEXT;
+  cols TEXT;
+  y INT;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['plvr_land_house_sale', 'plvr_land_new_house', 'plvr_land_rental'] LOOP
+    EXECUTE format('DROP VIEW IF EXISTS %I', t || '_clean');
+    EXECUTE format('ALTER TABLE %I RENAME TO %I', t, t || '_unpartitioned');
+    -- the columns, defaults, generated columns and comments are kept, the indexes are created below
+    EXECUTE format(
+      'CREATE TABLE %I (LIKE %I INCLUDING ALL EXCLUDING INDEXES) PARTITION BY RANGE (transaction_date)',
+      t, t || '_unpartitioned'
+    );
+    EXECUTE format('CREATE TABLE %I PARTITION OF %I DEFAULT', t || '_default', t);
+    FOR y IN EXECUTE format(
+      'SELECT DISTINCT extract(year FROM transaction_date)::INT FROM %I WHERE transaction_date IS NOT NULL',
+      t || '_unpartitioned'
+    ) LOOP
+      PERFORM plvr_create_year_partition(t, y);
+    END LOOP;
+
+    SELECT string_agg(quote_ident(column_name), ', ' ORDER BY ordinal_position) INTO cols
+    FROM information_schema.columns
+    WHERE table_schema = current_schema() AND table_name = t || '_unpartitioned' AND is_generated = 'NEVER';
+    EXECUTE format('INSERT INTO %I (%s) SELECT %s FROM %I', t, cols, cols, t || '_unpartitioned');
+    EXECUTE format('DROP TABLE %I', t || '_unpartitioned');
+
+    -- the unique index includes transaction_date, i.e., the partition key, as required
+    EXECUTE format(
+      'CREATE UNIQUE INDEX %I ON %I (city, address_normalized, transaction_date, total_price, building_area_sqm)',
+      t || '_dedup_idx', t
+    );
+    EXECUTE format('CREATE INDEX %I ON %I (address_normalized)', t || '_address_idx', t);
+    EXECUTE format('CREATE INDEX %I ON %I (city, district, transaction_date)', t || '_city_district_date_idx', t);
+    EXECUTE format('CREATE INDEX %I ON %I (transaction_date)', t || '_date_idx', t);
+    -- building_type is filtered by prefix, e.g., building_type LIKE '住宅大樓%'
+    EXECUTE format('CREATE INDEX %I ON %I (building_type text_pattern_ops)', t || '_building_type_idx', t);
+
+    EXECUTE format('CREATE VIEW %I AS SELECT * FROM %I WHERE cardinality(flags) = 0', t || '_clean', t);
+  END LOOP;
+END $$;
+
+COMMENT ON TABLE plvr_land_house_sale IS '實價登錄 - 房屋買賣交易';
+COMMENT ON TABLE plvr_land_new_house IS '實價登錄 - 新成屋交易';
+COMMENT ON TABLE plvr_land_rental IS '實價登錄 - 租房交易';
+COMMENT ON VIEW plvr_land_house_sale_clean IS '實價登錄 - 房屋買賣交易 (排除異常交易)';
+COMMENT ON VIEW plvr_land_new_house_clean IS '實價登錄 - 新成屋交易 (排除異常交易)';
+COMMENT ON VIEW plvr_land_rental_clean IS '實價登錄 - 租房交易 (排除異常交易)';
@@ -0,0 +1,10 @@
+ALTER TABLE plvr_land_house_sale DROP CONSTRAINT IF EXISTS plvr_land_house_sale_city_fkey;
+ALTER TABLE plvr_land_new_house DROP CONSTRAINT IF EXISTS plvr_land_new_house_city_fkey;
+ALTER TABLE plvr_land_rental DROP CONSTRAINT IF EXISTS plvr_land_rental_city_fkey;
+
+DROP TABLE IF EXISTS ref_plvr_land_district;
+
+ALTER TABLE ref_plvr_land_city ALTER COLUMN city_name_en DROP NOT NULL, ALTER COLUMN city_name_zh DROP NOT NULL;
+UPDATE ref_plvr_land_city SET city_name_en = replace(city_name_en, 'County', 'Country');
+UPDATE ref_plvr_land_city SET city_name_zh = replace(city_name_zh, '臺', '台') WHERE city_code IN ('a', 'b', 'd', 'l', 'r', 'v');
+COMMENT ON TABLE ref_plvr_land_city IS NULL;
@@ -0,0 +1,149 @@
+-- the official names, i.e., 臺 and County, the english names of the districts are hanyu pinyin
+UPDATE ref_plvr_land_city
+SET city_name_zh = replace(city_name_zh, '台', '臺'), city_name_en = replace(city_name_en, 'Country', 'County');
+ALTER TABLE ref_plvr_land_city ALTER COLUMN city_name_en SET NOT NULL, ALTER COLUMN city_name_zh SET NOT NULL;
+COMMENT ON TABLE ref_plvr_land_city IS '實價登錄檔名的縣市代碼';
+
+CREATE TABLE IF NOT EXISTS ref_plvr_land_district (
+  city_code VARCHAR(1) NOT NULL REFERENCES ref_plvr_land_city (city_code),
+  district_name_zh TEXT NOT NULL,
+  district_name_en TEXT NOT NULL,
+  PRIMARY KEY (city_code, district_name_zh)
+);
+COMMENT ON TABLE ref_plvr_land_district IS '各縣市的鄉鎮市區';
+COMMENT ON COLUMN ref_plvr_land_district.district_name_zh IS 'with 臺, the imported districts are matched after replacing 台';
+
+INSERT INTO ref_plvr_land_district (city_code, district_name_zh, district_name_en)
+VALUES
+('a', '中正區', 'Zhongzheng'), ('a', '大同區', 'Datong'), ('a', '中山區', 'Zhongshan'), ('a', '松山區', 'Songshan'),
+('a', '大安區', 'Da''an'), ('a', '萬華區', 'Wanhua'), ('a', '信義區', 'Xinyi'), ('a', '士林區', 'Shilin'),
+('a', '北投區', 'Beitou'), ('a', '內湖區', 'Neihu'), ('a', '南港區', 'Nangang'), ('a', '文山區', 'Wenshan'),
+
+('b', '中區', 'Central'), ('b', '東區', 'East'), ('b', '南區', 'South'), ('b', '西區', 'West'),
+('b', '北區', 'North'), ('b', '西屯區', 'Xitun'), ('b', '南屯區', 'Nantun'), ('b', '北屯區', 'Beitun'),
+('b', '豐原區', 'Fengyuan'), ('b', '東勢區', 'Dongshi'), ('b', '大甲區', 'Dajia'), ('b', '清水區', 'Qingshui'),
+('b', '沙鹿區', 'Shalu'), ('b', '梧棲區', 'Wuqi'), ('b', '后里區', 'Houli'), ('b', '神岡區', 'Shengang'),
+('b', '潭子區', 'Tanzi'), ('b', '大雅區', 'Daya'), ('b', '新社區', 'Xinshe'), ('b', '石岡區', 'Shigang'),
+('b', '外埔區', 'Waipu'), ('b', '大安區', 'Da''an'), ('b', '烏日區', 'Wuri'), ('b', '大肚區', 'Dadu'),
+('b', '龍井區', 'Longjing'), ('b', '霧峰區', 'Wufeng'), ('b', '太平區', 'Taiping'), ('b', '大里區', 'Dali'),
+('b', '和平區', 'Heping'),
+
+('c', '中正區', 'Zhongzheng'), ('c', '七堵區', 'Qidu'), ('c', '暖暖區', 'Nuannuan'), ('c', '仁愛區', 'Ren''ai'),
+('c', '中山區', 'Zhongshan'), ('c', '安樂區', 'Anle'), ('c', '信義區', 'Xinyi'),
+
+('d', '中西區', 'West Central'), ('d', '東區', 'East'), ('d', '南區', 'South'), ('d', '北區', 'North'),
+('d', '安平區', 'Anping'), ('d', '安南區', 'Annan'), ('d', '永康區', 'Yongkang'), ('d', '歸仁區', 'Guiren'),
+('d', '新化區', 'Xinhua'), ('d', '左鎮區', 'Zuozhen'), ('d', '玉井區', 'Yujing'), ('d', '楠西區', 'Nanxi'),
+('d', '南化區', 'Nanhua'), ('d', '仁德區', 'Rende'), ('d', '關廟區', 'Guanmiao'), ('d', '龍崎區', 'Longqi'),
+('d', '官田區', 'Guantian'), ('d', '麻豆區', 'Madou'), ('d', '佳里區', 'Jiali'), ('d', '西港區', 'Xigang'),
+('d', '七股區', 'Qigu'), ('d', '將軍區', 'Jiangjun'), ('d', '學甲區', 'Xuejia'), ('d', '北門區', 'Beimen'),
+('d', '新營區', 'Xinying'), ('d', '後壁區', 'Houbi'), ('d', '白河區', 'Baihe'), ('d', '東山區', 'Dongshan'),
+('d', '六甲區', 'Liujia'), ('d', '下營區', 'Xiaying'), ('d', '柳營區', 'Liuying'), ('d', '鹽水區', 'Yanshui'),
+('d', '善化區', 'Shanhua'), ('d', '大內區', 'Danei'), ('d', '山上區', 'Shanshang'), ('d', '新市區', 'Xinshi'),
+('d', '安定區', 'Anding'),
+
+('e', '新興區', 'Xinxing'), ('e', '前金區', 'Qianjin'), ('e', '苓雅區', 'Lingya'), ('e', '鹽埕區', 'Yancheng'),
+('e', '鼓山區', 'Gushan'), ('e', '旗津區', 'Qijin'), ('e', '前鎮區', 'Qianzhen'), ('e', '三民區', 'Sanmin'),
+('e', '楠梓區', 'Nanzi'), ('e', '小港區', 'Xiaogang'), ('e', '左營區', 'Zuoying'), ('e', '仁武區', 'Renwu'),
+('e', '大社區', 'Dashe'), ('e', '岡山區', 'Gangshan'), ('e', '路竹區', 'Luzhu'), ('e', '阿蓮區', 'Alian'),
+('e', '田寮區', 'Tianliao'), ('e', '燕巢區', 'Yanchao'), ('e', '橋頭區', 'Qiaotou'), ('e', '梓官區', 'Ziguan'),
+('e', '彌陀區', 'Mituo'), ('e', '永安區', 'Yong''an'), ('e', '湖內區', 'Hunei'), ('e', '鳳山區', 'Fengshan'),
+('e', '大寮區', 'Daliao'), ('e', '林園區', 'Linyuan'), ('e', '鳥松區', 'Niaosong'), ('e', '大樹區', 'Dashu'),
+('e', '旗山區', 'Qishan'), ('e', '美濃區', 'Meinong'), ('e', '六龜區', 'Liugui'), ('e', '內門區', 'Neimen'),
+('e', '杉林區', 'Shanlin'), ('e', '甲仙區', 'Jiaxian'), ('e', '桃源區', 'Taoyuan'), ('e', '那瑪夏區', 'Namaxia'),
+('e', '茂林區', 'Maolin'), ('e', '茄萣區', 'Qieding'),
+
+('f', '板橋區', 'Banqiao'), ('f', '三重區', 'Sanchong'), ('f', '中和區', 'Zhonghe'), ('f', '永和區', 'Yonghe'),
+('f', '新莊區', 'Xinzhuang'), ('f', '新店區', 'Xindian'), ('f', '樹林區', 'Shulin'), ('f', '鶯歌區', 'Yingge'),
+('f', '三峽區', 'Sanxia'), ('f', '淡水區', 'Tamsui'), ('f', '汐止區', 'Xizhi'), ('f', '瑞芳區', 'Ruifang'),
+('f', '土城區', 'Tucheng'), ('f', '蘆洲區', 'Luzhou'), ('f', '五股區', 'Wugu'), ('f', '泰山區', 'Taishan'),
+('f', '林口區', 'Linkou'), ('f', '深坑區', 'Shenkeng'), ('f', '石碇區', 'Shiding'), ('f', '坪林區', 'Pinglin'),
+('f', '三芝區', 'Sanzhi'), ('f', '石門區', 'Shimen'), ('f', '八里區', 'Bali'), ('f', '平溪區', 'Pingxi'),
+('f', '雙溪區', 'Shuangxi'), ('f', '貢寮區', 'Gongliao'), ('f', '金山區', 'Jinshan'), ('f', '萬里區', 'Wanli'),
+('f', '烏來區', 'Wulai'),
+
+('g', '宜蘭市', 'Yilan City'), ('g', '羅東鎮', 'Luodong'), ('g', '蘇澳鎮', 'Su''ao'), ('g', '頭城鎮', 'Toucheng'),
+('g', '礁溪鄉', 'Jiaoxi'), ('g', '壯圍鄉', 'Zhuangwei'), ('g', '員山鄉', 'Yuanshan'), ('g', '冬山鄉', 'Dongshan'),
+('g', '五結鄉', 'Wujie'), ('g', '三星鄉', 'Sanxing'), ('g', '大同鄉', 'Datong'), ('g', '南澳鄉', 'Nan''ao'),
+
+('h', '桃園區', 'Taoyuan'), ('h', '中壢區', 'Zhongli'), ('h', '平鎮區', 'Pingzhen'), ('h', '八德區', 'Bade'),
+('h', '楊梅區', 'Yangmei'), ('h', '蘆竹區', 'Luzhu'), ('h', '大溪區', 'Daxi'), ('h', '龍潭區', 'Longtan'),
+('h', '龜山區', 'Guishan'), ('h', '大園區', 'Dayuan'), ('h', '觀音區', 'Guanyin'), ('h', '新屋區', 'Xinwu'),
+('h', '復興區', 'Fuxing'),
+
+('i', '東區', 'East'), ('i', '西區', 'West'),
+
+('j', '竹北市', 'Zhubei City'), ('j', '竹東鎮', 'Zhudong'), ('j', '新埔鎮', 'Xinpu'), ('j', '關西鎮', 'Guanxi'),
+('j', '湖口鄉', 'Hukou'), ('j', '新豐鄉', 'Xinfeng'), ('j', '芎林鄉', 'Qionglin'), ('j', '橫山鄉', 'Hengshan'),
+('j', '北埔鄉', 'Beipu'), ('j', '寶山鄉', 'Baoshan'), ('j', '峨眉鄉', 'Emei'), ('j', '尖石鄉', 'Jianshi'),
+('j', '五峰鄉', 'Wufeng'),
+
+('k', '苗栗市', 'Miaoli City'), ('k', '苑裡鎮', 'Yuanli'), ('k', '通霄鎮', 'Tongxiao'), ('k', '竹南鎮', 'Zhunan'),
+('k', '頭份市', 'Toufen City'), ('k', '後龍鎮', 'Houlong'), ('k', '卓蘭鎮', 'Zhuolan'), ('k', '大湖鄉', 'Dahu'),
+('k', '公館鄉', 'Gongguan'), ('k', '銅鑼鄉', 'Tongluo'), ('k', '南庄鄉', 'Nanzhuang'), ('k', '頭屋鄉', 'Touwu'),
+('k', '三義鄉', 'Sanyi'), ('k', '西湖鄉', 'Xihu'), ('k', '造橋鄉', 'Zaoqiao'), ('k', '三灣鄉', 'Sanwan'),
+('k', '獅潭鄉', 'Shitan'), ('k', '泰安鄉', 'Tai''an'),
+
+('m', '南投市', 'Nantou City'), ('m', '埔里鎮', 'Puli'), ('m', '草屯鎮', 'Caotun'), ('m', '竹山鎮', 'Zhushan'),
+('m', '集集鎮', 'Jiji'), ('m', '名間鄉', 'Mingjian'), ('m', '鹿谷鄉', 'Lugu'), ('m', '中寮鄉', 'Zhongliao'),
+('m', '魚池鄉', 'Yuchi'), ('m', '國姓鄉', 'Guoxing'), ('m', '水里鄉', 'Shuili'), ('m', '信義鄉', 'Xinyi'),
+('m', '仁愛鄉', 'Ren''ai'),
+
+('n', '彰化市', 'Changhua City'), ('n', '鹿港鎮', 'Lukang'), ('n', '和美鎮', 'Hemei'), ('n', '線西鄉', 'Xianxi'),
+('n', '伸港鄉', 'Shengang'), ('n', '福興鄉', 'Fuxing'), ('n', '秀水鄉', 'Xiushui'), ('n', '花壇鄉', 'Huatan'),
+('n', '芬園鄉', 'Fenyuan'), ('n', '員林市', 'Yuanlin City'), ('n', '溪湖鎮', 'Xihu'), ('n', '田中鎮', 'Tianzhong'),
+('n', '大村鄉', 'Dacun'), ('n', '埔鹽鄉', 'Puyan'), ('n', '埔心鄉', 'Puxin'), ('n', '永靖鄉', 'Yongjing'),
+('n', '社頭鄉', 'Shetou'), ('n', '二水鄉', 'Ershui'), ('n', '北斗鎮', 'Beidou'), ('n', '二林鎮', 'Erlin'),
+('n', '田尾鄉', 'Tianwei'), ('n', '埤頭鄉', 'Pitou'), ('n', '芳苑鄉', 'Fangyuan'), ('n', '大城鄉', 'Dacheng'),
+('n', '竹塘鄉', 'Zhutang'), ('n', '溪州鄉', 'Xizhou'),
+
+('o', '東區', 'East'), ('o', '北區', 'North'), ('o', '香山區', 'Xiangshan'),
+
+('p', '斗六市', 'Douliu City'), ('p', '斗南鎮', 'Dounan'), ('p', '虎尾鎮', 'Huwei'), ('p', '西螺鎮', 'Xiluo'),
+('p', '土庫鎮', 'Tuku'), ('p', '北港鎮', 'Beigang'), ('p', '古坑鄉', 'Gukeng'), ('p', '大埤鄉', 'Dapi'),
+('p', '莿桐鄉', 'Citong'), ('p', '林內鄉', 'Linnei'), ('p', '二崙鄉', 'Erlun'), ('p', '崙背鄉', 'Lunbei'),
+('p', '麥寮鄉', 'Mailiao'), ('p', '東勢鄉', 'Dongshi'), ('p', '褒忠鄉', 'Baozhong'), ('p', '臺西鄉', 'Taixi'),
+('p', '元長鄉', 'Yuanchang'), ('p', '四湖鄉', 'Sihu'), ('p', '口湖鄉', 'Kouhu'), ('p', '水林鄉', 'Shuilin'),
+
+('q', '太保市', 'Taibao City'), ('q', '朴子市', 'Puzi City'), ('q', '布袋鎮', 'Budai'), ('q', '大林鎮', 'Dalin'),
+('q', '民雄鄉', 'Minxiong'), ('q', '溪口鄉', 'Xikou'), ('q', '新港鄉', 'Xingang'), ('q', '六腳鄉', 'Liujiao'),
+('q', '東石鄉', 'Dongshi'), ('q', '義竹鄉', 'Yizhu'), ('q', '鹿草鄉', 'Lucao'), ('q', '水上鄉', 'Shuishang'),
+('q', '中埔鄉', 'Zhongpu'), ('q', '竹崎鄉', 'Zhuqi'), ('q', '梅山鄉', 'Meishan'), ('q', '番路鄉', 'Fanlu'),
+('q', '大埔鄉', 'Dapu'), ('q', '阿里山鄉', 'Alishan'),
+
+('t', '屏東市', 'Pingtung City'), ('t', '潮州鎮', 'Chaozhou'), ('t', '東港鎮', 'Donggang'), ('t', '恆春鎮', 'Hengchun'),
+('t', '萬丹鄉', 'Wandan'), ('t', '長治鄉', 'Changzhi'), ('t', '麟洛鄉', 'Linluo'), ('t', '九如鄉', 'Jiuru'),
+('t', '里港鄉', 'Ligang'), ('t', '鹽埔鄉', 'Yanpu'), ('t', '高樹鄉', 'Gaoshu'), ('t', '萬巒鄉', 'Wanluan'),
+('t', '內埔鄉', 'Neipu'), ('t', '竹田鄉', 'Zhutian'), ('t', '新埤鄉', 'Xinpi'), ('t', '枋寮鄉', 'Fangliao'),
+('t', '新園鄉', 'Xinyuan'), ('t', '崁頂鄉', 'Kanding'), ('t', '林邊鄉', 'Linbian'), ('t', '南州鄉', 'Nanzhou'),
+('t', '佳冬鄉', 'Jiadong'), ('t', '琉球鄉', 'Liuqiu'), ('t', '車城鄉', 'Checheng'), ('t', '滿州鄉', 'Manzhou'),
+('t', '枋山鄉', 'Fangshan'), ('t', '三地門鄉', 'Sandimen'), ('t', '霧臺鄉', 'Wutai'), ('t', '瑪家鄉', 'Majia'),
+('t', '泰武鄉', 'Taiwu'), ('t', '來義鄉', 'Laiyi'), ('t', '春日鄉', 'Chunri'), ('t', '獅子鄉', 'Shizi'),
+('t', '牡丹鄉', 'Mudan'),
+
+('u', '花蓮市', 'Hualien City'), ('u', '鳳林鎮', 'Fenglin'), ('u', '玉里鎮', 'Yuli'), ('u', '新城鄉', 'Xincheng'),
+('u', '吉安鄉', 'Ji''an'), ('u', '壽豐鄉', 'Shoufeng'), ('u', '光復鄉', 'Guangfu'), ('u', '豐濱鄉', 'Fengbin'),
+('u', '瑞穗鄉', 'Ruisui'), ('u', '富里鄉', 'Fuli'), ('u', '秀林鄉', 'Xiulin'), ('u', '萬榮鄉', 'Wanrong'),
+('u', '卓溪鄉', 'Zhuoxi'),
+
+('v', '臺東市', 'Taitung City'), ('v', '成功鎮', 'Chenggong'), ('v', '關山鎮', 'Guanshan'), ('v', '卑南鄉', 'Beinan'),
+('v', '鹿野鄉', 'Luye'), ('v', '池上鄉', 'Chishang'), ('v', '東河鄉', 'Donghe'), ('v', '長濱鄉', 'Changbin'),
+('v', '太麻里鄉', 'Taimali'), ('v', '大武鄉', 'Dawu'), ('v', '綠島鄉', 'Ludao'), ('v', '海端鄉', 'Haiduan'),
+('v', '延平鄉', 'Yanping'), ('v', '金峰鄉', 'Jinfeng'), ('v', '達仁鄉', 'Daren'), ('v', '蘭嶼鄉', 'Lanyu'),
+
+('w', '金城鎮', 'Jincheng'), ('w', '金湖鎮', 'Jinhu'), ('w', '金沙鎮', 'Jinsha'), ('w', '金寧鄉', 'Jinning'),
+('w', '烈嶼鄉', 'Lieyu'), ('w', '烏坵鄉', 'Wuqiu'),
+
+('x', '馬公市', 'Magong City'), ('x', '湖西鄉', 'Huxi'), ('x', '白沙鄉', 'Baisha'), ('x', '西嶼鄉', 'Xiyu'),
+('x', '望安鄉', 'Wang''an'), ('x', '七美鄉', 'Qimei'),
+
+('z', '南竿鄉', 'Nangan'), ('z', '北竿鄉', 'Beigan'), ('z', '莒光鄉', 'Juguang'), ('z', '東引鄉', 'Dongyin')
+ON CONFLICT (city_code, district_name_zh) DO NOTHING;
+
+-- the partitions inherit the keys of their parent
+ALTER TABLE plvr_land_house_sale
+  ADD CONSTRAINT plvr_land_house_sale_city_fkey FOREIGN KEY (city) REFERENCES ref_plvr_land_city (city_code);
+ALTER TABLE plvr_land_new_house
+  ADD CONSTRAINT plvr_land_new_house_city_fkey FOREIGN KEY (city) REFERENCES ref_plvr_land_city (city_code);
+ALTER TABLE plvr_land_rental
+  ADD CONSTRAINT plvr_land_rental_city_fkey FOREIGN KEY (city) REFERENCES ref_plvr_land_city (city_code);
@@ -0,0 +1,17 @@
+DO $$
+DECLARE
+  t TEXT;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['plvr_land_house_sale', 'plvr_land_new_house', 'plvr_land_rental'] LOOP
+    EXECUTE format('DROP VIEW IF EXISTS %I', t || '_clean');
+    EXECUTE format('DROP VIEW IF EXISTS %I', t || '_current');
+    EXECUTE format('CREATE VIEW %I AS SELECT * FROM %I WHERE cardinality(flags) = 0', t || '_clean', t);
+  END LOOP;
+END $$;
+
+COMMENT ON VIEW plvr_land_house_sale_clean IS '實價登錄 - 房屋買賣交易 (排除異常交易)';
+COMMENT ON VIEW plvr_land_new_house_clean IS '實價登錄 - 新成屋交易 (排除異常交易)';
+COMMENT ON VIEW plvr_land_rental_clean IS '實價登錄 - 租房交易 (排除異常交易)';
+
+DROP TABLE IF EXISTS ref_plvr_land_district_history;
+ALTER TABLE ref_plvr_land_city DROP COLUMN IF EXISTS merged_into, DROP COLUMN IF EXISTS merged_date;
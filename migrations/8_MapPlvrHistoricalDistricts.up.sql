@@ -0,0 +1,95 @@
+-- 臺中縣, 臺南縣 and 高雄縣 merged into the special municipalities on 2010-12-25
+ALTER TABLE ref_plvr_land_city
+  ADD COLUMN IF NOT EXISTS merged_into VARCHAR(1) REFERENCES ref_plvr_land_city (city_code),
+  ADD COLUMN IF NOT EXISTS merged_date DATE;
+UPDATE ref_plvr_land_city SET merged_into = 'b', merged_date = DATE '2010-12-25' WHERE city_code = 'l';
+UPDATE ref_plvr_land_city SET merged_into = 'd', merged_date = DATE '2010-12-25' WHERE city_code = 'r';
+UPDATE ref_plvr_land_city SET merged_into = 'e', merged_date = DATE '2010-12-25' WHERE city_code = 's';
+COMMENT ON COLUMN ref_plvr_land_city.merged_into IS 'the current city of the merged counties';
+
+-- the former names of the districts, the targets are checked against ref_plvr_land_district
+CREATE TABLE IF NOT EXISTS ref_plvr_land_district_history (
+  city_code VARCHAR(1) NOT NULL REFERENCES ref_plvr_land_city (city_code),
+  district_name_zh TEXT NOT NULL,
+  current_city_code VARCHAR(1) NOT NULL,
+  current_district_name_zh TEXT NOT NULL,
+  changed_date DATE NOT NULL,
+  PRIMARY KEY (city_code, district_name_zh),
+  FOREIGN KEY (current_city_code, current_district_name_zh) REFERENCES ref_plvr_land_district (city_code, district_name_zh)
+);
+COMMENT ON TABLE ref_plvr_land_district_history IS '鄉鎮市區的舊名與合併後的縣市';
+
+-- the townships became districts of the same name, e.g., 豐原市 to 豐原區
+INSERT INTO ref_plvr_land_district_history (city_code, district_name_zh, current_city_code, current_district_name_zh, changed_date)
+SELECT m.city_code, d, m.current_city_code, regexp_replace(d, '[市鎮鄉]$', '區'), m.changed_date
+FROM (VALUES
+  ('l', 'b', DATE '2010-12-25', ARRAY[
+    '豐原市', '大里市', '太平市', '東勢鎮', '大甲鎮', '清水鎮', '沙鹿鎮', '梧棲鎮', '后里鄉', '神岡鄉', '潭子鄉',
+    '大雅鄉', '新社鄉', '石岡鄉', '外埔鄉', '大安鄉', '烏日鄉', '大肚鄉', '龍井鄉', '霧峰鄉', '和平鄉'
+  ]),
+  ('r', 'd', DATE '2010-12-25', ARRAY[
+    '新營市', '永康市', '鹽水鎮', '白河鎮', '麻豆鎮', '佳里鎮', '新化鎮', '善化鎮', '學甲鎮', '柳營鄉', '後壁鄉',
+    '東山鄉', '下營鄉', '六甲鄉', '官田鄉', '大內鄉', '西港鄉', '七股鄉', '將軍鄉', '北門鄉', '新市鄉', '安定鄉',
+    '山上鄉', '玉井鄉', '楠西鄉', '南化鄉', '左鎮鄉', '仁德鄉', '歸仁鄉', '關廟鄉', '龍崎鄉'
+  ]),
+  ('s', 'e', DATE '2010-12-25', ARRAY[
+    '鳳山市', '岡山鎮', '旗山鎮', '美濃鎮', '林園鄉', '大寮鄉', '大樹鄉', '仁武鄉', '大社鄉', '鳥松鄉', '橋頭鄉',
+    '燕巢鄉', '田寮鄉', '阿蓮鄉', '路竹鄉', '湖內鄉', '茄萣鄉', '永安鄉', '彌陀鄉', '梓官鄉', '六龜鄉', '甲仙鄉',
+    '杉林鄉', '內門鄉', '茂林鄉', '桃源鄉', '那瑪夏鄉'
+  ]),
+  -- 臺北縣 became 新北市 on the same day and 桃園縣 became 桃園市 on 2014-12-25, their codes stayed
+  ('f', 'f', DATE '2010-12-25', ARRAY[
+    '板橋市', '三重市', '中和市', '永和市', '新莊市', '新店市', '樹林市', '鶯歌鎮', '三峽鎮', '淡水鎮', '汐止市',
+    '瑞芳鎮', '土城市', '蘆洲市', '五股鄉', '泰山鄉', '林口鄉', '深坑鄉', '石碇鄉', '坪林鄉', '三芝鄉', '石門鄉',
+    '八里鄉', '平溪鄉', '雙溪鄉', '貢寮鄉', '金山鄉', '萬里鄉', '烏來鄉'
+  ]),
+  ('h', 'h', DATE '2014-12-25', ARRAY[
+    '桃園市', '中壢市', '平鎮市', '八德市', '楊梅市', '蘆竹鄉', '大溪鎮', '龍潭鄉', '龜山鄉', '大園鄉', '觀音鄉',
+    '新屋鄉', '復興鄉'
+  ])
+) AS m (city_code, current_city_code, changed_date, districts), unnest(m.districts) AS d
+ON CONFLICT (city_code, district_name_zh) DO NOTHING;
+
+INSERT INTO ref_plvr_land_district_history (city_code, district_name_zh, current_city_code, current_district_name_zh, changed_date)
+VALUES
+('s', '三民鄉', 'e', '那瑪夏區', DATE '2010-12-25'),
+('h', '楊梅鎮', 'h', '楊梅區', DATE '2014-12-25'),
+('k', '頭份鎮', 'k', '頭份市', DATE '2015-10-05'),
+('n', '員林鎮', 'n', '員林市', DATE '2015-08-08')
+ON CONFLICT (city_code, district_name_zh) DO NOTHING;
+
+-- the _current views resolve city and district to the current administrative units at query time,
+-- the codes of the files are kept in city_raw and district_raw, the analyses read them through the
+-- _clean views
+DO $$
+DECLARE
+  t TEXT;
+  cols TEXT;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['plvr_land_house_sale', 'plvr_land_new_house', 'plvr_land_rental'] LOOP
+    EXECUTE format('DROP VIEW IF EXISTS %I', t || '_clean');
+
+    SELECT string_agg('t.' || quote_ident(column_name), ', ' ORDER BY ordinal_position) INTO cols
+    FROM information_schema.columns
+    WHERE table_schema = current_schema() AND table_name = t AND column_name NOT IN ('city', 'district');
+    EXECUTE format($v$
+      CREATE VIEW %I AS
+      SELECT %s,
+        COALESCE(h.current_city_code, c.merged_into, t.city) AS city,
+        COALESCE(h.current_district_name_zh, replace(t.district, '台', '臺')) AS district,
+        t.city AS city_raw,
+        t.district AS district_raw
+      FROM %I t
+      LEFT JOIN ref_plvr_land_city c ON c.city_code = t.city
+      LEFT JOIN ref_plvr_land_district_history h ON h.city_code = t.city AND h.district_name_zh = replace(t.district, '台', '臺')
+    $v$, t || '_current', cols, t);
+    EXECUTE format('CREATE VIEW %I AS SELECT * FROM %I WHERE cardinality(flags) = 0', t || '_clean', t || '_current');
+  END LOOP;
+END $$;
+
+COMMENT ON VIEW plvr_land_house_sale_current IS '實價登錄 - 房屋買賣交易 (現行縣市及鄉鎮市區)';
+COMMENT ON VIEW plvr_land_new_house_current IS '實價登錄 - 新成屋交易 (現行縣市及鄉鎮市區)';
+COMMENT ON VIEW plvr_land_rental_current IS '實價登錄 - 租房交易 (現行縣市及鄉鎮市區)';
+COMMENT ON VIEW plvr_land_house_sale_clean IS '實價登錄 - 房屋買賣交易 (排除異常交易)';
+COMMENT ON VIEW plvr_land_new_house_clean IS '實價登錄 - 新成屋交易 (排除異常交易)';
+COMMENT ON VIEW plvr_land_rental_clean IS '實價登錄 - 租房交易 (排除異常交易)';
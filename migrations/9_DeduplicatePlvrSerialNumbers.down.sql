@@ -0,0 +1,14 @@
+-- fails when transactions of distinct serial numbers share the former key, they have to be
+-- removed by hand first
+DO $$
+DECLARE
+  t TEXT;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['plvr_land_house_sale', 'plvr_land_new_house', 'plvr_land_rental'] LOOP
+    EXECUTE format('DROP INDEX IF EXISTS %I', t || '_dedup_idx');
+    EXECUTE format(
+      'CREATE UNIQUE INDEX %I ON %I (city, address_normalized, transaction_date, total_price, building_area_sqm)',
+      t || '_dedup_idx', t
+    );
+  END LOOP;
+END $$;
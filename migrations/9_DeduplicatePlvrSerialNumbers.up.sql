@@ -0,0 +1,22 @@
+-- a transaction is identified by its serial number, the former key of the normalized address,
+-- date, price and area merged the distinct sales of a masked number range on the same day
+DO $$
+DECLARE
+  t TEXT;
+BEGIN
+  FOREACH t IN ARRAY ARRAY['plvr_land_house_sale', 'plvr_land_new_house', 'plvr_land_rental'] LOOP
+    EXECUTE format('DROP INDEX IF EXISTS %I', t || '_dedup_idx');
+    -- the rows imported more than once, i.e., of the same serial number and date, are kept once,
+    -- a ctid is only unique within its partition
+    EXECUTE format(
+      'DELETE FROM %I a USING %I b WHERE a.tableoid = b.tableoid AND a.ctid > b.ctid AND a.city = b.city '
+      || 'AND a.serial_number = b.serial_number AND a.transaction_date = b.transaction_date',
+      t, t
+    );
+    -- the unique index includes transaction_date, i.e., the partition key, as required
+    EXECUTE format(
+      'CREATE UNIQUE INDEX %I ON %I (city, serial_number, transaction_date)',
+      t || '_dedup_idx', t
+    );
+  END LOOP;
+END $$;
@@ -2,11 +2,18 @@ package migrations
 
 import (
 	"database/sql"
+	"embed"
 	"fmt"
+	"path/filepath"
 
 	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/database"
 	"github.com/golang-migrate/migrate/v4/database/pgx"
-	_ "github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/database/sqlite"
+	"github.com/golang-migrate/migrate/v4/source"
+	"github.com/golang-migrate/migrate/v4/source/file"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	pgxv5 "github.com/jackc/pgx/v5"
 	_ "github.com/jackc/pgx/v5/stdlib"
 	"go.uber.org/zap"
 
@@ -20,87 +27,208 @@ const (
 	NewDbDriverError        = "MR00002"
 	NewMigrateInstanceError = "MR00003"
 	MigrateError            = "MR00004"
-
-	migrationSrc = "migrations"
+	NewSourceError          = "MR00005"
+	InvalidTargetError      = "MR00006"
+	NewSchemaError          = "MR00007"
 )
 
+// sqlFiles are the migrations shipped with the binary
+//
+//go:embed *.sql
+var sqlFiles embed.FS
+
+// sqliteFiles are the counterparts of sqlFiles for the sqlite driver, a migration of sqlFiles
+// comes along with the one of the same version
+//
+//go:embed sqlite/*.sql
+var sqliteFiles embed.FS
+
 type SchemaManager struct {
-	rootDir string
 	config  *config.PgConfig
+	src     source.Driver
 	migrate *migrate.Migrate
 	logger  *zap.SugaredLogger
 }
 
-func New(rootDir string, config *config.PgConfig, logger *zap.SugaredLogger) (*SchemaManager, *e.ErrorData) {
-	db, err := sql.Open("pgx", config.ToConnString())
+// New reads the migrations embedded in the binary, or the ones of cfg.MigrationsDir when set,
+// the sqlite driver reads the ones of the sqlite directory
+func New(cfg *config.PgConfig, logger *zap.SugaredLogger) (*SchemaManager, *e.ErrorData) {
+	src, name, err := newSource(cfg.Driver, cfg.MigrationsDir)
+	if err != nil {
+		logger.Errorf("[migrate] unable to read the migrations: %s", err)
+		return nil, e.Wrap(
+			NewSourceError,
+			err,
+			fmt.Sprintf("%s.New", currentPackage),
+		)
+	}
+
+	newDriver := newPgDriver
+	if cfg.Driver == config.DriverSQLite {
+		newDriver = newSQLiteDriver
+	}
+	driver, errData := newDriver(cfg, logger)
+	if errData != nil {
+		return nil, errData
+	}
+	m, err := migrate.NewWithInstance(name, src, cfg.DbName, driver)
+	if err != nil {
+		logger.Errorf("[migrate] error occured on creating schema manager: %s", err)
+		return nil, e.Wrap(
+			NewMigrateInstanceError,
+			err,
+			fmt.Sprintf("%s.New", currentPackage),
+		)
+	}
+
+	logger.Debugf("[migrate] migrations read from %s", name)
+	return &SchemaManager{
+		config:  cfg,
+		src:     src,
+		migrate: m,
+		logger:  logger,
+	}, nil
+}
+
+func newPgDriver(cfg *config.PgConfig, logger *zap.SugaredLogger) (database.Driver, *e.ErrorData) {
+	db, err := sql.Open("pgx", cfg.MigrationConnString())
 	if err != nil {
 		logger.Errorf("[migrate] unable to connect to database: %v\n", err)
-		return nil, e.NewErrorData(
+		return nil, e.Wrap(
 			NewSqlDbError,
-			err.Error(),
+			err,
+			fmt.Sprintf("%s.New", currentPackage),
+		)
+	}
+	// the schema is created first, the migrations and their version table land in it
+	if _, err := db.Exec("CREATE SCHEMA IF NOT EXISTS " + pgxv5.Identifier{cfg.DbSchema}.Sanitize()); err != nil {
+		db.Close()
+		logger.Errorf("[migrate] unable to create schema %s: %s", cfg.DbSchema, err)
+		return nil, e.Wrap(
+			NewSchemaError,
+			err,
 			fmt.Sprintf("%s.New", currentPackage),
-			nil,
-			nil,
 		)
 	}
-	driver, err := pgx.WithInstance(db, &pgx.Config{})
+	// postgis is optional and only installed when asked for, a missing extension or privilege
+	// leaves the geom columns out
+	if cfg.Postgis {
+		if _, err := db.Exec("CREATE EXTENSION IF NOT EXISTS postgis SCHEMA public"); err != nil {
+			logger.Warnf("[migrate] unable to install postgis in public, the geom columns are left out: %s", err)
+		}
+	}
+	driver, err := pgx.WithInstance(db, &pgx.Config{SchemaName: cfg.DbSchema})
 	if err != nil {
-		logger.Errorf("[migrate] error occured on creating migrate.Migrate: %w", err)
-		return nil, e.NewErrorData(
+		db.Close()
+		logger.Errorf("[migrate] error occured on creating migrate.Migrate: %s", err)
+		return nil, e.Wrap(
 			NewDbDriverError,
-			err.Error(),
+			err,
 			fmt.Sprintf("%s.New", currentPackage),
-			nil,
-			nil,
 		)
 	}
-	m, err := migrate.NewWithDatabaseInstance(
-		fmt.Sprintf("file:///%s/%s", rootDir, migrationSrc),
-		config.DbName,
-		driver,
-	)
+	return driver, nil
+}
+
+func newSQLiteDriver(cfg *config.PgConfig, logger *zap.SugaredLogger) (database.Driver, *e.ErrorData) {
+	db, err := sql.Open("sqlite", cfg.SQLiteDSN())
 	if err != nil {
-		logger.Errorf("[migrate] error occured on creating schema manager: %w", err)
-		return nil, e.NewErrorData(
-			NewMigrateInstanceError,
-			err.Error(),
+		logger.Errorf("[migrate] unable to open the sqlite database %s: %s", cfg.SQLitePath, err)
+		return nil, e.Wrap(
+			NewSqlDbError,
+			err,
 			fmt.Sprintf("%s.New", currentPackage),
-			nil,
-			nil,
 		)
 	}
+	driver, err := sqlite.WithInstance(db, &sqlite.Config{DatabaseName: cfg.SQLitePath})
+	if err != nil {
+		db.Close()
+		logger.Errorf("[migrate] error occured on creating migrate.Migrate: %s", err)
+		return nil, e.Wrap(
+			NewDbDriverError,
+			err,
+			fmt.Sprintf("%s.New", currentPackage),
+		)
+	}
+	return driver, nil
+}
 
-	return &SchemaManager{
-		rootDir: rootDir,
-		config:  config,
-		migrate: m,
-		logger:  logger,
-	}, nil
+// newSource returns the embedded migrations of the driver, or the ones of dir, along with the
+// name of the source, the sqlite migrations are in the sqlite subdirectory of both
+func newSource(driver string, dir string) (source.Driver, string, error) {
+	if dir == "" && driver == config.DriverSQLite {
+		src, err := iofs.New(sqliteFiles, "sqlite")
+		return src, "embedded sqlite migrations", err
+	}
+	if dir == "" {
+		src, err := iofs.New(sqlFiles, ".")
+		return src, "embedded migrations", err
+	}
+	if driver == config.DriverSQLite {
+		dir = filepath.Join(dir, "sqlite")
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, "", err
+	}
+	url := "file://" + filepath.ToSlash(abs)
+	src, err := (&file.File{}).Open(url)
+	return src, url, err
 }
 
+// Migrate applies the pending migrations
 func (sm *SchemaManager) Migrate() *e.ErrorData {
-	err := sm.migrate.Up()
-	if err == nil {
-		v, _, _ := sm.migrate.Version()
-		sm.logger.Infof("[migrate] migrated to version %d", v)
-		return nil
+	return sm.run("Migrate", sm.migrate.Up)
+}
+
+// RollBack reverts every migration
+func (sm *SchemaManager) RollBack() *e.ErrorData {
+	return sm.run("RollBack", sm.migrate.Down)
+}
+
+// Goto migrates up or down to version, 0 reverts every migration
+func (sm *SchemaManager) Goto(version uint) *e.ErrorData {
+	if _, errData := sm.Plan(version); errData != nil {
+		return errData
+	}
+	if version == 0 {
+		return sm.run("Goto", sm.migrate.Down)
+	}
+	return sm.run("Goto", func() error { return sm.migrate.Migrate(version) })
+}
+
+// Steps applies the next n migrations, or reverts the last -n ones when n is negative
+func (sm *SchemaManager) Steps(n int) *e.ErrorData {
+	if _, errData := sm.StepsTarget(n); errData != nil {
+		return errData
 	}
+	return sm.run("Steps", func() error { return sm.migrate.Steps(n) })
+}
+
+// Force sets the version without running any migration and clears the dirty state, i.e., after
+// a failed migration has been fixed by hand, -1 removes the version
+func (sm *SchemaManager) Force(version int) *e.ErrorData {
+	return sm.run("Force", func() error { return sm.migrate.Force(version) })
+}
+
+// run applies fn and logs the resulting version, no change is not an error
+func (sm *SchemaManager) run(target string, fn func() error) *e.ErrorData {
+	err := fn()
 	if err == migrate.ErrNoChange {
-		v, _, _ := sm.migrate.Version()
+		v, _, _ := sm.Version()
 		sm.logger.Infof("[migrate] there is no schema changes, current version: %d", v)
 		return nil
 	}
-	return e.NewErrorData(
-		MigrateError,
-		err.Error(),
-		fmt.Sprintf("%s.Migrate", currentPackage),
-		nil,
-		nil,
-	)
-}
-
-func (sm *SchemaManager) RollBack() {
-	sm.migrate.Down()
+	if err != nil {
+		return e.Wrap(
+			MigrateError,
+			err,
+			fmt.Sprintf("%s.%s", currentPackage, target),
+		)
+	}
+	v, dirty, _ := sm.Version()
+	sm.logger.Infof("[migrate] migrated to version %d, dirty %t", v, dirty)
+	return nil
 }
 
 func (sm *SchemaManager) Stop() {
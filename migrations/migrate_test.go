@@ -0,0 +1,58 @@
+package migrations
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/Walker088/gorealestate/config"
+)
+
+// versions lists the migrations of the source of driver and dir
+func versions(t *testing.T, driver string, dir string) []Migration {
+	t.Helper()
+	src, _, err := newSource(driver, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer src.Close()
+	all, errData := (&SchemaManager{src: src}).migrations()
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	return all
+}
+
+func TestEmbeddedMigrations(t *testing.T) {
+	pg, sqlite := versions(t, config.DriverPostgres, ""), versions(t, config.DriverSQLite, "")
+	if len(pg) == 0 {
+		t.Fatal("expected the migrations to be embedded")
+	}
+	// a sqlite migration comes along with the postgres one of the same version
+	if !reflect.DeepEqual(pg, sqlite) {
+		t.Errorf("expected the same sqlite migrations as the postgres ones, got %v and %v", pg, sqlite)
+	}
+}
+
+func TestMigrationsDir(t *testing.T) {
+	dir := t.TempDir()
+	for name, sql := range map[string]string{
+		"1_CreateA.up.sql":        "CREATE TABLE a (id INTEGER);",
+		"sqlite/1_CreateA.up.sql": "CREATE TABLE a (id INTEGER);",
+		"sqlite/2_CreateB.up.sql": "CREATE TABLE b (id INTEGER);",
+	} {
+		file := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(file, []byte(sql), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for driver, want := range map[string]int{config.DriverPostgres: 1, config.DriverSQLite: 2} {
+		if all := versions(t, driver, dir); len(all) != want {
+			t.Errorf("expected %d %s migrations of the directory, got %v", want, driver, all)
+		}
+	}
+}
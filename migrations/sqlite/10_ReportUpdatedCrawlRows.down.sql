@@ -0,0 +1 @@
+ALTER TABLE crawl_run_file DROP COLUMN rows_updated;
@@ -0,0 +1 @@
+ALTER TABLE crawl_run_file ADD COLUMN rows_updated INT NOT NULL DEFAULT 0;
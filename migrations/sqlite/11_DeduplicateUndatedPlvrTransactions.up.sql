@@ -0,0 +1,25 @@
+-- the nulls are distinct in the dedup index, i.e., a row without a transaction date was inserted
+-- again by every import, a partial index keys the undated rows on the serial number alone
+-- the undated rows imported more than once are kept once
+DELETE FROM plvr_land_house_sale WHERE transaction_date IS NULL AND EXISTS (
+  SELECT 1 FROM plvr_land_house_sale b WHERE b.rowid < plvr_land_house_sale.rowid AND b.city = plvr_land_house_sale.city
+    AND b.serial_number = plvr_land_house_sale.serial_number AND b.transaction_date IS NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_house_sale_undated_dedup_idx
+  ON plvr_land_house_sale (city, serial_number) WHERE transaction_date IS NULL;
+
+-- the undated rows imported more than once are kept once
+DELETE FROM plvr_land_new_house WHERE transaction_date IS NULL AND EXISTS (
+  SELECT 1 FROM plvr_land_new_house b WHERE b.rowid < plvr_land_new_house.rowid AND b.city = plvr_land_new_house.city
+    AND b.serial_number = plvr_land_new_house.serial_number AND b.transaction_date IS NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_new_house_undated_dedup_idx
+  ON plvr_land_new_house (city, serial_number) WHERE transaction_date IS NULL;
+
+-- the undated rows imported more than once are kept once
+DELETE FROM plvr_land_rental WHERE transaction_date IS NULL AND EXISTS (
+  SELECT 1 FROM plvr_land_rental b WHERE b.rowid < plvr_land_rental.rowid AND b.city = plvr_land_rental.city
+    AND b.serial_number = plvr_land_rental.serial_number AND b.transaction_date IS NULL
+);
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_rental_undated_dedup_idx
+  ON plvr_land_rental (city, serial_number) WHERE transaction_date IS NULL;
@@ -0,0 +1,6 @@
+UPDATE plvr_land_house_sale SET geocode_level = NULL WHERE geocode_level = 'none';
+UPDATE plvr_land_new_house SET geocode_level = NULL WHERE geocode_level = 'none';
+UPDATE plvr_land_rental SET geocode_level = NULL WHERE geocode_level = 'none';
+ALTER TABLE plvr_land_house_sale DROP COLUMN geocode_version;
+ALTER TABLE plvr_land_new_house DROP COLUMN geocode_version;
+ALTER TABLE plvr_land_rental DROP COLUMN geocode_version;
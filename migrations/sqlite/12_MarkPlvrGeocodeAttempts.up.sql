@@ -0,0 +1,5 @@
+-- the version of the reference the rows were last matched against, they are only matched again
+-- once a reference is loaded since
+ALTER TABLE plvr_land_house_sale ADD COLUMN geocode_version TEXT;
+ALTER TABLE plvr_land_new_house ADD COLUMN geocode_version TEXT;
+ALTER TABLE plvr_land_rental ADD COLUMN geocode_version TEXT;
@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS plvr_land_house_sale;
+DROP TABLE IF EXISTS plvr_land_new_house;
+DROP TABLE IF EXISTS plvr_land_rental;
+DROP TABLE IF EXISTS plvr_land_parse_failed;
+
+DROP TABLE IF EXISTS plvr_download_history;
+DROP TABLE IF EXISTS ref_plvr_land_city;
@@ -0,0 +1,6 @@
+DROP VIEW IF EXISTS plvr_land_house_sale_clean;
+DROP VIEW IF EXISTS plvr_land_new_house_clean;
+DROP VIEW IF EXISTS plvr_land_rental_clean;
+ALTER TABLE plvr_land_house_sale DROP COLUMN flags;
+ALTER TABLE plvr_land_new_house DROP COLUMN flags;
+ALTER TABLE plvr_land_rental DROP COLUMN flags;
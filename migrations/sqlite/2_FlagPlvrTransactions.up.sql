@@ -0,0 +1,11 @@
+-- the flags are a json array of text, e.g., ["related_party"], the clean views compare it to the empty one
+ALTER TABLE plvr_land_house_sale ADD COLUMN flags TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE plvr_land_new_house ADD COLUMN flags TEXT NOT NULL DEFAULT '[]';
+ALTER TABLE plvr_land_rental ADD COLUMN flags TEXT NOT NULL DEFAULT '[]';
+
+CREATE VIEW IF NOT EXISTS plvr_land_house_sale_clean AS
+SELECT * FROM plvr_land_house_sale WHERE flags = '[]';
+CREATE VIEW IF NOT EXISTS plvr_land_new_house_clean AS
+SELECT * FROM plvr_land_new_house WHERE flags = '[]';
+CREATE VIEW IF NOT EXISTS plvr_land_rental_clean AS
+SELECT * FROM plvr_land_rental WHERE flags = '[]';
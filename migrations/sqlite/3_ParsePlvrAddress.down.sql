@@ -0,0 +1,41 @@
+DROP INDEX IF EXISTS plvr_land_house_sale_dedup_idx;
+DROP INDEX IF EXISTS plvr_land_house_sale_address_idx;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_normalized;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_city;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_district;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_village;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_road;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_section;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_lane;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_alley;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_number_from;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_number_to;
+ALTER TABLE plvr_land_house_sale DROP COLUMN address_floor;
+
+DROP INDEX IF EXISTS plvr_land_new_house_dedup_idx;
+DROP INDEX IF EXISTS plvr_land_new_house_address_idx;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_normalized;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_city;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_district;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_village;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_road;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_section;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_lane;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_alley;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_number_from;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_number_to;
+ALTER TABLE plvr_land_new_house DROP COLUMN address_floor;
+
+DROP INDEX IF EXISTS plvr_land_rental_dedup_idx;
+DROP INDEX IF EXISTS plvr_land_rental_address_idx;
+ALTER TABLE plvr_land_rental DROP COLUMN address_normalized;
+ALTER TABLE plvr_land_rental DROP COLUMN address_city;
+ALTER TABLE plvr_land_rental DROP COLUMN address_district;
+ALTER TABLE plvr_land_rental DROP COLUMN address_village;
+ALTER TABLE plvr_land_rental DROP COLUMN address_road;
+ALTER TABLE plvr_land_rental DROP COLUMN address_section;
+ALTER TABLE plvr_land_rental DROP COLUMN address_lane;
+ALTER TABLE plvr_land_rental DROP COLUMN address_alley;
+ALTER TABLE plvr_land_rental DROP COLUMN address_number_from;
+ALTER TABLE plvr_land_rental DROP COLUMN address_number_to;
+ALTER TABLE plvr_land_rental DROP COLUMN address_floor;
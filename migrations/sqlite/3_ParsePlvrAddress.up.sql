@@ -0,0 +1,45 @@
+-- sqlite adds a single column per statement, the clean views select * and pick the new columns up
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_normalized TEXT;
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_city TEXT;
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_district TEXT;
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_village TEXT;
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_road TEXT;
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_section INT2;
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_lane TEXT;
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_alley TEXT;
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_number_from INT4;
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_number_to INT4;
+ALTER TABLE plvr_land_house_sale ADD COLUMN address_floor INT2;
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_house_sale_dedup_idx
+  ON plvr_land_house_sale (city, address_normalized, transaction_date, total_price, building_area_sqm);
+CREATE INDEX IF NOT EXISTS plvr_land_house_sale_address_idx ON plvr_land_house_sale (address_normalized);
+
+ALTER TABLE plvr_land_new_house ADD COLUMN address_normalized TEXT;
+ALTER TABLE plvr_land_new_house ADD COLUMN address_city TEXT;
+ALTER TABLE plvr_land_new_house ADD COLUMN address_district TEXT;
+ALTER TABLE plvr_land_new_house ADD COLUMN address_village TEXT;
+ALTER TABLE plvr_land_new_house ADD COLUMN address_road TEXT;
+ALTER TABLE plvr_land_new_house ADD COLUMN address_section INT2;
+ALTER TABLE plvr_land_new_house ADD COLUMN address_lane TEXT;
+ALTER TABLE plvr_land_new_house ADD COLUMN address_alley TEXT;
+ALTER TABLE plvr_land_new_house ADD COLUMN address_number_from INT4;
+ALTER TABLE plvr_land_new_house ADD COLUMN address_number_to INT4;
+ALTER TABLE plvr_land_new_house ADD COLUMN address_floor INT2;
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_new_house_dedup_idx
+  ON plvr_land_new_house (city, address_normalized, transaction_date, total_price, building_area_sqm);
+CREATE INDEX IF NOT EXISTS plvr_land_new_house_address_idx ON plvr_land_new_house (address_normalized);
+
+ALTER TABLE plvr_land_rental ADD COLUMN address_normalized TEXT;
+ALTER TABLE plvr_land_rental ADD COLUMN address_city TEXT;
+ALTER TABLE plvr_land_rental ADD COLUMN address_district TEXT;
+ALTER TABLE plvr_land_rental ADD COLUMN address_village TEXT;
+ALTER TABLE plvr_land_rental ADD COLUMN address_road TEXT;
+ALTER TABLE plvr_land_rental ADD COLUMN address_section INT2;
+ALTER TABLE plvr_land_rental ADD COLUMN address_lane TEXT;
+ALTER TABLE plvr_land_rental ADD COLUMN address_alley TEXT;
+ALTER TABLE plvr_land_rental ADD COLUMN address_number_from INT4;
+ALTER TABLE plvr_land_rental ADD COLUMN address_number_to INT4;
+ALTER TABLE plvr_land_rental ADD COLUMN address_floor INT2;
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_rental_dedup_idx
+  ON plvr_land_rental (city, address_normalized, transaction_date, total_price, building_area_sqm);
+CREATE INDEX IF NOT EXISTS plvr_land_rental_address_idx ON plvr_land_rental (address_normalized);
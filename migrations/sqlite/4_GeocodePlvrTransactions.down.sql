@@ -0,0 +1,16 @@
+ALTER TABLE plvr_land_house_sale DROP COLUMN lat;
+ALTER TABLE plvr_land_house_sale DROP COLUMN lon;
+ALTER TABLE plvr_land_house_sale DROP COLUMN geocode_level;
+ALTER TABLE plvr_land_house_sale DROP COLUMN geocode_confidence;
+
+ALTER TABLE plvr_land_new_house DROP COLUMN lat;
+ALTER TABLE plvr_land_new_house DROP COLUMN lon;
+ALTER TABLE plvr_land_new_house DROP COLUMN geocode_level;
+ALTER TABLE plvr_land_new_house DROP COLUMN geocode_confidence;
+
+ALTER TABLE plvr_land_rental DROP COLUMN lat;
+ALTER TABLE plvr_land_rental DROP COLUMN lon;
+ALTER TABLE plvr_land_rental DROP COLUMN geocode_level;
+ALTER TABLE plvr_land_rental DROP COLUMN geocode_confidence;
+
+DROP TABLE IF EXISTS geo_reference;
@@ -0,0 +1,29 @@
+-- postgis is not available, i.e., no geom columns, the geojson exports need postgres
+CREATE TABLE IF NOT EXISTS geo_reference (
+  kind TEXT NOT NULL,
+  city TEXT NOT NULL,
+  district TEXT NOT NULL DEFAULT '',
+  village TEXT NOT NULL DEFAULT '',
+  road TEXT NOT NULL DEFAULT '',
+  section INT2 NOT NULL DEFAULT 0,
+  lat DOUBLE PRECISION NOT NULL,
+  lon DOUBLE PRECISION NOT NULL,
+  source TEXT,
+  loaded_time TEXT DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (kind, city, district, village, road, section)
+);
+
+ALTER TABLE plvr_land_house_sale ADD COLUMN lat DOUBLE PRECISION;
+ALTER TABLE plvr_land_house_sale ADD COLUMN lon DOUBLE PRECISION;
+ALTER TABLE plvr_land_house_sale ADD COLUMN geocode_level TEXT;
+ALTER TABLE plvr_land_house_sale ADD COLUMN geocode_confidence REAL;
+
+ALTER TABLE plvr_land_new_house ADD COLUMN lat DOUBLE PRECISION;
+ALTER TABLE plvr_land_new_house ADD COLUMN lon DOUBLE PRECISION;
+ALTER TABLE plvr_land_new_house ADD COLUMN geocode_level TEXT;
+ALTER TABLE plvr_land_new_house ADD COLUMN geocode_confidence REAL;
+
+ALTER TABLE plvr_land_rental ADD COLUMN lat DOUBLE PRECISION;
+ALTER TABLE plvr_land_rental ADD COLUMN lon DOUBLE PRECISION;
+ALTER TABLE plvr_land_rental ADD COLUMN geocode_level TEXT;
+ALTER TABLE plvr_land_rental ADD COLUMN geocode_confidence REAL;
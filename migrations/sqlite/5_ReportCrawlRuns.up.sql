@@ -0,0 +1,29 @@
+-- the times are rfc 3339 text in utc and the samples json text
+CREATE TABLE IF NOT EXISTS crawl_run (
+  run_id TEXT PRIMARY KEY,
+  status TEXT NOT NULL DEFAULT 'running',
+  started_time TEXT NOT NULL DEFAULT (strftime('%Y-%m-%dT%H:%M:%SZ', 'now')),
+  finished_time TEXT
+);
+
+CREATE TABLE IF NOT EXISTS crawl_run_file (
+  run_id TEXT NOT NULL REFERENCES crawl_run (run_id) ON DELETE CASCADE,
+  season TEXT NOT NULL,
+  file_name TEXT NOT NULL,
+  city TEXT NOT NULL,
+  rows_read INT NOT NULL DEFAULT 0,
+  rows_inserted INT NOT NULL DEFAULT 0,
+  rows_skipped INT NOT NULL DEFAULT 0,
+  rows_rejected INT NOT NULL DEFAULT 0,
+  PRIMARY KEY (run_id, season, file_name)
+);
+
+CREATE TABLE IF NOT EXISTS crawl_run_error (
+  run_id TEXT NOT NULL REFERENCES crawl_run (run_id) ON DELETE CASCADE,
+  season TEXT NOT NULL,
+  file_name TEXT NOT NULL DEFAULT '',
+  code TEXT NOT NULL,
+  count INT NOT NULL DEFAULT 0,
+  samples TEXT NOT NULL DEFAULT '[]',
+  PRIMARY KEY (run_id, season, file_name, code)
+);
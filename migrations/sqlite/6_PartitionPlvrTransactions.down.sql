@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS plvr_land_house_sale_city_district_date_idx;
+DROP INDEX IF EXISTS plvr_land_house_sale_date_idx;
+DROP INDEX IF EXISTS plvr_land_house_sale_building_type_idx;
+DROP INDEX IF EXISTS plvr_land_new_house_city_district_date_idx;
+DROP INDEX IF EXISTS plvr_land_new_house_date_idx;
+DROP INDEX IF EXISTS plvr_land_new_house_building_type_idx;
+DROP INDEX IF EXISTS plvr_land_rental_city_district_date_idx;
+DROP INDEX IF EXISTS plvr_land_rental_date_idx;
+DROP INDEX IF EXISTS plvr_land_rental_building_type_idx;
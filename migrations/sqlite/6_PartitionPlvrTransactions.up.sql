@@ -0,0 +1,10 @@
+-- sqlite has no partitions, only the indexes of the partitioned tables are created
+CREATE INDEX IF NOT EXISTS plvr_land_house_sale_city_district_date_idx ON plvr_land_house_sale (city, district, transaction_date);
+CREATE INDEX IF NOT EXISTS plvr_land_house_sale_date_idx ON plvr_land_house_sale (transaction_date);
+CREATE INDEX IF NOT EXISTS plvr_land_house_sale_building_type_idx ON plvr_land_house_sale (building_type);
+CREATE INDEX IF NOT EXISTS plvr_land_new_house_city_district_date_idx ON plvr_land_new_house (city, district, transaction_date);
+CREATE INDEX IF NOT EXISTS plvr_land_new_house_date_idx ON plvr_land_new_house (transaction_date);
+CREATE INDEX IF NOT EXISTS plvr_land_new_house_building_type_idx ON plvr_land_new_house (building_type);
+CREATE INDEX IF NOT EXISTS plvr_land_rental_city_district_date_idx ON plvr_land_rental (city, district, transaction_date);
+CREATE INDEX IF NOT EXISTS plvr_land_rental_date_idx ON plvr_land_rental (transaction_date);
+CREATE INDEX IF NOT EXISTS plvr_land_rental_building_type_idx ON plvr_land_rental (building_type);
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS ref_plvr_land_district;
+UPDATE ref_plvr_land_city SET city_name_zh = replace(city_name_zh, '臺', '台') WHERE city_code IN ('a', 'b', 'd', 'l', 'r', 'v');
+UPDATE ref_plvr_land_city SET city_name_en = replace(city_name_en, 'County', 'Country');
@@ -0,0 +1,15 @@
+DROP VIEW IF EXISTS plvr_land_house_sale_clean;
+DROP VIEW IF EXISTS plvr_land_house_sale_current;
+CREATE VIEW plvr_land_house_sale_clean AS SELECT * FROM plvr_land_house_sale WHERE flags = '[]';
+
+DROP VIEW IF EXISTS plvr_land_new_house_clean;
+DROP VIEW IF EXISTS plvr_land_new_house_current;
+CREATE VIEW plvr_land_new_house_clean AS SELECT * FROM plvr_land_new_house WHERE flags = '[]';
+
+DROP VIEW IF EXISTS plvr_land_rental_clean;
+DROP VIEW IF EXISTS plvr_land_rental_current;
+CREATE VIEW plvr_land_rental_clean AS SELECT * FROM plvr_land_rental WHERE flags = '[]';
+
+DROP TABLE IF EXISTS ref_plvr_land_district_history;
+ALTER TABLE ref_plvr_land_city DROP COLUMN merged_into;
+ALTER TABLE ref_plvr_land_city DROP COLUMN merged_date;
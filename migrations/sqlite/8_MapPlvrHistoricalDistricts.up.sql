@@ -0,0 +1,123 @@
+-- 臺中縣, 臺南縣 and 高雄縣 merged into the special municipalities on 2010-12-25, merged_into does not
+-- reference the cities as sqlite drops no column of a foreign key
+ALTER TABLE ref_plvr_land_city ADD COLUMN merged_into VARCHAR(1);
+ALTER TABLE ref_plvr_land_city ADD COLUMN merged_date DATE;
+UPDATE ref_plvr_land_city SET merged_into = 'b', merged_date = '2010-12-25' WHERE city_code = 'l';
+UPDATE ref_plvr_land_city SET merged_into = 'd', merged_date = '2010-12-25' WHERE city_code = 'r';
+UPDATE ref_plvr_land_city SET merged_into = 'e', merged_date = '2010-12-25' WHERE city_code = 's';
+
+-- the former names of the districts, the targets are checked against ref_plvr_land_district
+CREATE TABLE IF NOT EXISTS ref_plvr_land_district_history (
+  city_code VARCHAR(1) NOT NULL REFERENCES ref_plvr_land_city (city_code),
+  district_name_zh TEXT NOT NULL,
+  current_city_code VARCHAR(1) NOT NULL,
+  current_district_name_zh TEXT NOT NULL,
+  changed_date DATE NOT NULL,
+  PRIMARY KEY (city_code, district_name_zh),
+  FOREIGN KEY (current_city_code, current_district_name_zh) REFERENCES ref_plvr_land_district (city_code, district_name_zh)
+);
+
+-- the townships became districts of the same name, e.g., 豐原市 to 豐原區, the lists are json arrays
+INSERT INTO ref_plvr_land_district_history (city_code, district_name_zh, current_city_code, current_district_name_zh, changed_date)
+SELECT m.column1, d.value, m.column2, substr(d.value, 1, length(d.value) - 1) || '區', m.column3
+FROM (VALUES
+  ('l', 'b', '2010-12-25', '[
+    "豐原市", "大里市", "太平市", "東勢鎮", "大甲鎮", "清水鎮", "沙鹿鎮", "梧棲鎮", "后里鄉", "神岡鄉", "潭子鄉",
+    "大雅鄉", "新社鄉", "石岡鄉", "外埔鄉", "大安鄉", "烏日鄉", "大肚鄉", "龍井鄉", "霧峰鄉", "和平鄉"
+  ]'),
+  ('r', 'd', '2010-12-25', '[
+    "新營市", "永康市", "鹽水鎮", "白河鎮", "麻豆鎮", "佳里鎮", "新化鎮", "善化鎮", "學甲鎮", "柳營鄉", "後壁鄉",
+    "東山鄉", "下營鄉", "六甲鄉", "官田鄉", "大內鄉", "西港鄉", "七股鄉", "將軍鄉", "北門鄉", "新市鄉", "安定鄉",
+    "山上鄉", "玉井鄉", "楠西鄉", "南化鄉", "左鎮鄉", "仁德鄉", "歸仁鄉", "關廟鄉", "龍崎鄉"
+  ]'),
+  ('s', 'e', '2010-12-25', '[
+    "鳳山市", "岡山鎮", "旗山鎮", "美濃鎮", "林園鄉", "大寮鄉", "大樹鄉", "仁武鄉", "大社鄉", "鳥松鄉", "橋頭鄉",
+    "燕巢鄉", "田寮鄉", "阿蓮鄉", "路竹鄉", "湖內鄉", "茄萣鄉", "永安鄉", "彌陀鄉", "梓官鄉", "六龜鄉", "甲仙鄉",
+    "杉林鄉", "內門鄉", "茂林鄉", "桃源鄉", "那瑪夏鄉"
+  ]'),
+  -- 臺北縣 became 新北市 on the same day and 桃園縣 became 桃園市 on 2014-12-25, their codes stayed
+  ('f', 'f', '2010-12-25', '[
+    "板橋市", "三重市", "中和市", "永和市", "新莊市", "新店市", "樹林市", "鶯歌鎮", "三峽鎮", "淡水鎮", "汐止市",
+    "瑞芳鎮", "土城市", "蘆洲市", "五股鄉", "泰山鄉", "林口鄉", "深坑鄉", "石碇鄉", "坪林鄉", "三芝鄉", "石門鄉",
+    "八里鄉", "平溪鄉", "雙溪鄉", "貢寮鄉", "金山鄉", "萬里鄉", "烏來鄉"
+  ]'),
+  ('h', 'h', '2014-12-25', '[
+    "桃園市", "中壢市", "平鎮市", "八德市", "楊梅市", "蘆竹鄉", "大溪鎮", "龍潭鄉", "龜山鄉", "大園鄉", "觀音鄉",
+    "新屋鄉", "復興鄉"
+  ]')
+) AS m, json_each(m.column4) AS d
+-- the where clause tells the upsert from a join constraint
+WHERE true
+ON CONFLICT (city_code, district_name_zh) DO NOTHING;
+
+INSERT INTO ref_plvr_land_district_history (city_code, district_name_zh, current_city_code, current_district_name_zh, changed_date)
+VALUES
+('s', '三民鄉', 'e', '那瑪夏區', '2010-12-25'),
+('h', '楊梅鎮', 'h', '楊梅區', '2014-12-25'),
+('k', '頭份鎮', 'k', '頭份市', '2015-10-05'),
+('n', '員林鎮', 'n', '員林市', '2015-08-08')
+ON CONFLICT (city_code, district_name_zh) DO NOTHING;
+
+-- the _current views resolve city and district to the current administrative units at query time,
+-- the codes of the files are kept in city_raw and district_raw, the analyses read them through the
+-- _clean views
+DROP VIEW IF EXISTS plvr_land_house_sale_clean;
+CREATE VIEW plvr_land_house_sale_current AS
+SELECT
+  t.serial_number, t.transaction_type, t.address, t.land_shifting_area_sqm, t.urban_land_use, t.non_urban_land_use,
+  t.non_urban_land_designation, t.transaction_date_raw, t.transaction_date, t.transaction_pen_number, t.floor, t.total_floor,
+  t.building_type, t.primary_use, t.primary_material, t.construction_complete_date_raw, t.construction_complete_date, t.building_area_sqm,
+  t.number_of_rooms, t.number_of_living_rooms, t.number_of_bathrooms, t.partitioned, t.has_management_organization, t.total_price,
+  t.unit_price_per_sqm, t.parking_type, t.parking_area_sqm, t.parking_price, t.notes, t.main_building_area_sqm,
+  t.subsidiary_building_area_sqm, t.balcony_area_sqm, t.elevator, t.transaction_identifier, t.flags, t.address_normalized,
+  t.address_city, t.address_district, t.address_village, t.address_road, t.address_section, t.address_lane,
+  t.address_alley, t.address_number_from, t.address_number_to, t.address_floor, t.lat, t.lon,
+  t.geocode_level, t.geocode_confidence,
+  COALESCE(h.current_city_code, c.merged_into, t.city) AS city,
+  COALESCE(h.current_district_name_zh, replace(t.district, '台', '臺')) AS district,
+  t.city AS city_raw,
+  t.district AS district_raw
+FROM plvr_land_house_sale t
+LEFT JOIN ref_plvr_land_city c ON c.city_code = t.city
+LEFT JOIN ref_plvr_land_district_history h ON h.city_code = t.city AND h.district_name_zh = replace(t.district, '台', '臺');
+CREATE VIEW plvr_land_house_sale_clean AS SELECT * FROM plvr_land_house_sale_current WHERE flags = '[]';
+
+DROP VIEW IF EXISTS plvr_land_new_house_clean;
+CREATE VIEW plvr_land_new_house_current AS
+SELECT
+  t.serial_number, t.transaction_type, t.address, t.land_shifting_area_sqm, t.urban_land_use, t.non_urban_land_use,
+  t.non_urban_land_designation, t.transaction_date_raw, t.transaction_date, t.transaction_pen_number, t.floor, t.total_floor,
+  t.building_type, t.primary_use, t.primary_material, t.construction_complete_date_raw, t.construction_complete_date, t.building_area_sqm,
+  t.number_of_rooms, t.number_of_living_rooms, t.number_of_bathrooms, t.partitioned, t.has_management_organization, t.total_price,
+  t.unit_price_per_sqm, t.parking_type, t.parking_area_sqm, t.parking_price, t.notes, t.flags,
+  t.address_normalized, t.address_city, t.address_district, t.address_village, t.address_road, t.address_section,
+  t.address_lane, t.address_alley, t.address_number_from, t.address_number_to, t.address_floor, t.lat,
+  t.lon, t.geocode_level, t.geocode_confidence,
+  COALESCE(h.current_city_code, c.merged_into, t.city) AS city,
+  COALESCE(h.current_district_name_zh, replace(t.district, '台', '臺')) AS district,
+  t.city AS city_raw,
+  t.district AS district_raw
+FROM plvr_land_new_house t
+LEFT JOIN ref_plvr_land_city c ON c.city_code = t.city
+LEFT JOIN ref_plvr_land_district_history h ON h.city_code = t.city AND h.district_name_zh = replace(t.district, '台', '臺');
+CREATE VIEW plvr_land_new_house_clean AS SELECT * FROM plvr_land_new_house_current WHERE flags = '[]';
+
+DROP VIEW IF EXISTS plvr_land_rental_clean;
+CREATE VIEW plvr_land_rental_current AS
+SELECT
+  t.serial_number, t.transaction_type, t.address, t.land_shifting_area_sqm, t.urban_land_use, t.non_urban_land_use,
+  t.non_urban_land_designation, t.transaction_date_raw, t.transaction_date, t.transaction_pen_number, t.floor, t.total_floor,
+  t.building_type, t.primary_use, t.primary_material, t.construction_complete_date_raw, t.construction_complete_date, t.building_area_sqm,
+  t.number_of_rooms, t.number_of_living_rooms, t.number_of_bathrooms, t.partitioned, t.has_management_organization, t.total_price,
+  t.unit_price_per_sqm, t.parking_type, t.parking_area_sqm, t.parking_price, t.notes, t.flags,
+  t.address_normalized, t.address_city, t.address_district, t.address_village, t.address_road, t.address_section,
+  t.address_lane, t.address_alley, t.address_number_from, t.address_number_to, t.address_floor, t.lat,
+  t.lon, t.geocode_level, t.geocode_confidence,
+  COALESCE(h.current_city_code, c.merged_into, t.city) AS city,
+  COALESCE(h.current_district_name_zh, replace(t.district, '台', '臺')) AS district,
+  t.city AS city_raw,
+  t.district AS district_raw
+FROM plvr_land_rental t
+LEFT JOIN ref_plvr_land_city c ON c.city_code = t.city
+LEFT JOIN ref_plvr_land_district_history h ON h.city_code = t.city AND h.district_name_zh = replace(t.district, '台', '臺');
+CREATE VIEW plvr_land_rental_clean AS SELECT * FROM plvr_land_rental_current WHERE flags = '[]';
@@ -0,0 +1,11 @@
+-- fails when transactions of distinct serial numbers share the former key, they have to be
+-- removed by hand first
+DROP INDEX IF EXISTS plvr_land_house_sale_dedup_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_house_sale_dedup_idx
+  ON plvr_land_house_sale (city, address_normalized, transaction_date, total_price, building_area_sqm);
+DROP INDEX IF EXISTS plvr_land_new_house_dedup_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_new_house_dedup_idx
+  ON plvr_land_new_house (city, address_normalized, transaction_date, total_price, building_area_sqm);
+DROP INDEX IF EXISTS plvr_land_rental_dedup_idx;
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_rental_dedup_idx
+  ON plvr_land_rental (city, address_normalized, transaction_date, total_price, building_area_sqm);
@@ -0,0 +1,25 @@
+-- a transaction is identified by its serial number, the former key of the normalized address,
+-- date, price and area merged the distinct sales of a masked number range on the same day
+DROP INDEX IF EXISTS plvr_land_house_sale_dedup_idx;
+-- the rows imported more than once, i.e., of the same serial number and date, are kept once
+DELETE FROM plvr_land_house_sale WHERE EXISTS (
+  SELECT 1 FROM plvr_land_house_sale b WHERE b.rowid < plvr_land_house_sale.rowid AND b.city = plvr_land_house_sale.city
+    AND b.serial_number = plvr_land_house_sale.serial_number AND b.transaction_date = plvr_land_house_sale.transaction_date
+);
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_house_sale_dedup_idx ON plvr_land_house_sale (city, serial_number, transaction_date);
+
+DROP INDEX IF EXISTS plvr_land_new_house_dedup_idx;
+-- the rows imported more than once, i.e., of the same serial number and date, are kept once
+DELETE FROM plvr_land_new_house WHERE EXISTS (
+  SELECT 1 FROM plvr_land_new_house b WHERE b.rowid < plvr_land_new_house.rowid AND b.city = plvr_land_new_house.city
+    AND b.serial_number = plvr_land_new_house.serial_number AND b.transaction_date = plvr_land_new_house.transaction_date
+);
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_new_house_dedup_idx ON plvr_land_new_house (city, serial_number, transaction_date);
+
+DROP INDEX IF EXISTS plvr_land_rental_dedup_idx;
+-- the rows imported more than once, i.e., of the same serial number and date, are kept once
+DELETE FROM plvr_land_rental WHERE EXISTS (
+  SELECT 1 FROM plvr_land_rental b WHERE b.rowid < plvr_land_rental.rowid AND b.city = plvr_land_rental.city
+    AND b.serial_number = plvr_land_rental.serial_number AND b.transaction_date = plvr_land_rental.transaction_date
+);
+CREATE UNIQUE INDEX IF NOT EXISTS plvr_land_rental_dedup_idx ON plvr_land_rental (city, serial_number, transaction_date);
@@ -0,0 +1,219 @@
+package migrations
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"github.com/golang-migrate/migrate/v4"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	Up   = "up"
+	Down = "down"
+)
+
+// Migration is a migration of the source, the sql is only read for the plans
+type Migration struct {
+	Version   uint   `json:"version"`
+	Name      string `json:"name"`
+	Direction string `json:"direction,omitempty"`
+	SQL       string `json:"sql,omitempty"`
+}
+
+// Status is the version of the database along with the applied and the pending migrations,
+// a dirty version is a migration that failed halfway, it has to be fixed by hand then forced
+type Status struct {
+	Version uint        `json:"version"`
+	Dirty   bool        `json:"dirty"`
+	Latest  uint        `json:"latest"`
+	Applied []Migration `json:"applied"`
+	Pending []Migration `json:"pending"`
+}
+
+// Version returns the current version of the database, 0 when no migration is applied
+func (sm *SchemaManager) Version() (uint, bool, *e.ErrorData) {
+	v, dirty, err := sm.migrate.Version()
+	if err == migrate.ErrNilVersion {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, e.Wrap(
+			MigrateError,
+			err,
+			fmt.Sprintf("%s.Version", currentPackage),
+		)
+	}
+	return v, dirty, nil
+}
+
+func (sm *SchemaManager) Status() (*Status, *e.ErrorData) {
+	v, dirty, errData := sm.Version()
+	if errData != nil {
+		return nil, errData
+	}
+	all, errData := sm.migrations()
+	if errData != nil {
+		return nil, errData
+	}
+	status := &Status{Version: v, Dirty: dirty, Applied: []Migration{}, Pending: []Migration{}}
+	for _, m := range all {
+		status.Latest = m.Version
+		if m.Version <= v {
+			status.Applied = append(status.Applied, m)
+		} else {
+			status.Pending = append(status.Pending, m)
+		}
+	}
+	return status, nil
+}
+
+// StepsTarget returns the version reached by n steps from the current one
+func (sm *SchemaManager) StepsTarget(n int) (uint, *e.ErrorData) {
+	v, _, errData := sm.Version()
+	if errData != nil {
+		return 0, errData
+	}
+	all, errData := sm.migrations()
+	if errData != nil {
+		return 0, errData
+	}
+	// index -1 is the empty database
+	current := -1
+	for i, m := range all {
+		if m.Version == v {
+			current = i
+		}
+	}
+	target := current + n
+	if target < -1 || target >= len(all) {
+		return 0, e.NewErrorData(
+			InvalidTargetError,
+			fmt.Sprintf("%d steps from version %d are out of the %d migrations", n, v, len(all)),
+			fmt.Sprintf("%s.StepsTarget", currentPackage),
+			nil,
+			nil,
+		)
+	}
+	if target == -1 {
+		return 0, nil
+	}
+	return all[target].Version, nil
+}
+
+// Plan lists the migrations run to reach version along with their sql, i.e., a dry run,
+// the dirty versions are refused as by the migrations themselves
+func (sm *SchemaManager) Plan(version uint) ([]Migration, *e.ErrorData) {
+	v, dirty, errData := sm.Version()
+	if errData != nil {
+		return nil, errData
+	}
+	if dirty {
+		return nil, e.NewErrorData(
+			MigrateError,
+			fmt.Sprintf("version %d is dirty, fix it then force a version", v),
+			fmt.Sprintf("%s.Plan", currentPackage),
+			nil,
+			nil,
+		)
+	}
+	all, errData := sm.migrations()
+	if errData != nil {
+		return nil, errData
+	}
+	found := version == 0
+	for _, m := range all {
+		found = found || m.Version == version
+	}
+	if !found {
+		return nil, e.NewErrorData(
+			InvalidTargetError,
+			fmt.Sprintf("version %d is not a migration", version),
+			fmt.Sprintf("%s.Plan", currentPackage),
+			nil,
+			nil,
+		)
+	}
+
+	plan := []Migration{}
+	if version >= v {
+		for _, m := range all {
+			if m.Version > v && m.Version <= version {
+				m.Direction = Up
+				plan = append(plan, m)
+			}
+		}
+	} else {
+		for i := len(all) - 1; i >= 0; i-- {
+			if m := all[i]; m.Version <= v && m.Version > version {
+				m.Direction = Down
+				plan = append(plan, m)
+			}
+		}
+	}
+	for i := range plan {
+		read := sm.src.ReadUp
+		if plan[i].Direction == Down {
+			read = sm.src.ReadDown
+		}
+		r, _, err := read(plan[i].Version)
+		if errors.Is(err, fs.ErrNotExist) {
+			// golang-migrate skips the missing down files
+			continue
+		}
+		if err != nil {
+			return nil, e.Wrap(
+				NewSourceError,
+				err,
+				fmt.Sprintf("%s.Plan", currentPackage),
+			)
+		}
+		b, err := io.ReadAll(r)
+		r.Close()
+		if err != nil {
+			return nil, e.Wrap(
+				NewSourceError,
+				err,
+				fmt.Sprintf("%s.Plan", currentPackage),
+			)
+		}
+		plan[i].SQL = string(b)
+	}
+	return plan, nil
+}
+
+// Latest returns the version of the last migration of the source
+func (sm *SchemaManager) Latest() (uint, *e.ErrorData) {
+	all, errData := sm.migrations()
+	if errData != nil || len(all) == 0 {
+		return 0, errData
+	}
+	return all[len(all)-1].Version, nil
+}
+
+// migrations lists the migrations of the source in order
+func (sm *SchemaManager) migrations() ([]Migration, *e.ErrorData) {
+	all := []Migration{}
+	v, err := sm.src.First()
+	for err == nil {
+		r, name, readErr := sm.src.ReadUp(v)
+		if readErr != nil {
+			err = readErr
+			break
+		}
+		r.Close()
+		all = append(all, Migration{Version: v, Name: name})
+		v, err = sm.src.Next(v)
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, e.Wrap(
+			NewSourceError,
+			err,
+			fmt.Sprintf("%s.migrations", currentPackage),
+		)
+	}
+	return all, nil
+}
@@ -0,0 +1,117 @@
+package migrations
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+	"testing/fstest"
+
+	"github.com/golang-migrate/migrate/v4"
+	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/config"
+)
+
+// newTestManager runs the migrations of fsys on a temporary sqlite database
+func newTestManager(t *testing.T, fsys fstest.MapFS) *SchemaManager {
+	t.Helper()
+	src, err := iofs.New(fsys, ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.PgConfig{
+		Driver:     config.DriverSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "test.db"),
+		DbName:     "test",
+	}
+	logger := zap.NewNop().Sugar()
+	driver, errData := newSQLiteDriver(cfg, logger)
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	m, err := migrate.NewWithInstance("test migrations", src, cfg.DbName, driver)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sm := &SchemaManager{config: cfg, src: src, migrate: m, logger: logger}
+	t.Cleanup(sm.Stop)
+	return sm
+}
+
+// testMigrations skip the version 3 and have no down file of the version 5
+var testMigrations = fstest.MapFS{
+	"1_CreateA.up.sql":   {Data: []byte("CREATE TABLE a (id INTEGER);")},
+	"1_CreateA.down.sql": {Data: []byte("DROP TABLE a;")},
+	"2_CreateB.up.sql":   {Data: []byte("CREATE TABLE b (id INTEGER);")},
+	"2_CreateB.down.sql": {Data: []byte("DROP TABLE b;")},
+	"5_IndexB.up.sql":    {Data: []byte("CREATE INDEX b_id ON b (id);")},
+}
+
+func TestStepsTarget(t *testing.T) {
+	sm := newTestManager(t, testMigrations)
+
+	for n, want := range map[int]uint{0: 0, 1: 1, 2: 2, 3: 5} {
+		if v, errData := sm.StepsTarget(n); errData != nil || v != want {
+			t.Errorf("expected %d steps from the empty database to reach %d, got %d %v", n, want, v, errData)
+		}
+	}
+	for _, n := range []int{-1, 4} {
+		if _, errData := sm.StepsTarget(n); errData == nil || errData.Code != InvalidTargetError {
+			t.Errorf("expected %d steps to be a %s, got %v", n, InvalidTargetError, errData)
+		}
+	}
+
+	if errData := sm.Steps(2); errData != nil {
+		t.Fatal(errData)
+	}
+	for n, want := range map[int]uint{-2: 0, -1: 1, 1: 5} {
+		if v, errData := sm.StepsTarget(n); errData != nil || v != want {
+			t.Errorf("expected %d steps from the version 2 to reach %d, got %d %v", n, want, v, errData)
+		}
+	}
+}
+
+func TestPlan(t *testing.T) {
+	sm := newTestManager(t, testMigrations)
+
+	plan, errData := sm.Plan(5)
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	want := []Migration{
+		{Version: 1, Name: "CreateA", Direction: Up, SQL: "CREATE TABLE a (id INTEGER);"},
+		{Version: 2, Name: "CreateB", Direction: Up, SQL: "CREATE TABLE b (id INTEGER);"},
+		{Version: 5, Name: "IndexB", Direction: Up, SQL: "CREATE INDEX b_id ON b (id);"},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("expected the plan %+v, got %+v", want, plan)
+	}
+	if _, errData := sm.Plan(3); errData == nil || errData.Code != InvalidTargetError {
+		t.Errorf("expected the version 3 to be a %s, got %v", InvalidTargetError, errData)
+	}
+
+	if errData := sm.Migrate(); errData != nil {
+		t.Fatal(errData)
+	}
+	// the missing down file of the version 5 has no sql
+	plan, errData = sm.Plan(1)
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	want = []Migration{
+		{Version: 5, Name: "IndexB", Direction: Down},
+		{Version: 2, Name: "CreateB", Direction: Down, SQL: "DROP TABLE b;"},
+	}
+	if !reflect.DeepEqual(plan, want) {
+		t.Errorf("expected the plan %+v, got %+v", want, plan)
+	}
+
+	status, errData := sm.Status()
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	if status.Version != 5 || status.Latest != 5 || status.Dirty || len(status.Applied) != 3 || len(status.Pending) != 0 {
+		t.Errorf("expected the 3 migrations to be applied, got %+v", status)
+	}
+}
@@ -0,0 +1,203 @@
+package reference
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/store"
+)
+
+const (
+	currentPackage = "github.com/Walker088/gorealestate/reference"
+
+	LoadReferenceError = "RF00001"
+	CityNotFoundError  = "RF00002"
+)
+
+// City is a city code of the plvr file names, e.g., a for a_lvr_land_a.csv, with its districts,
+// MergedInto is the current city of the counties merged in 2010, e.g., b for l (臺中縣)
+type City struct {
+	Code       string     `json:"code"`
+	NameEn     string     `json:"name_en"`
+	NameZh     string     `json:"name_zh"`
+	MergedInto string     `json:"merged_into,omitempty"`
+	Districts  []District `json:"districts"`
+}
+
+type District struct {
+	NameZh string `json:"name_zh"`
+	NameEn string `json:"name_en"`
+}
+
+// FormerDistrict is a name of ref_plvr_land_district_history, valid until Changed, e.g., 中壢市
+// of h became 中壢區 on 2014-12-25
+type FormerDistrict struct {
+	City            string    `json:"city"`
+	NameZh          string    `json:"name_zh"`
+	CurrentCity     string    `json:"current_city"`
+	CurrentDistrict string    `json:"current_district"`
+	Changed         time.Time `json:"changed_date"`
+}
+
+// Reference holds ref_plvr_land_city, ref_plvr_land_district and ref_plvr_land_district_history,
+// it is read only once loaded
+type Reference struct {
+	cities    []City
+	byCode    map[string]int
+	districts map[string]map[string]District
+	former    map[string]map[string]FormerDistrict
+}
+
+// New builds the reference of cities, with their districts, and of the former names of the
+// districts, it needs no database, e.g., in the tests of the crawler
+func New(cities []City, former []FormerDistrict) *Reference {
+	r := &Reference{
+		cities:    cities,
+		byCode:    map[string]int{},
+		districts: map[string]map[string]District{},
+		former:    map[string]map[string]FormerDistrict{},
+	}
+	for i, c := range cities {
+		r.byCode[c.Code] = i
+		r.districts[c.Code] = map[string]District{}
+		for _, d := range c.Districts {
+			r.districts[c.Code][d.NameZh] = d
+		}
+	}
+	for _, f := range former {
+		if r.former[f.City] == nil {
+			r.former[f.City] = map[string]FormerDistrict{}
+		}
+		r.former[f.City][f.NameZh] = f
+	}
+	return r
+}
+
+// Load reads the cities, their districts and the former names of the districts
+func Load(ctx context.Context, db store.Querier) (*Reference, *e.ErrorData) {
+	cities, byCode := []City{}, map[string]int{}
+	rows, err := db.Query(ctx, `
+	SELECT city_code, city_name_en, city_name_zh, COALESCE(merged_into, '') FROM ref_plvr_land_city ORDER BY city_code
+	`)
+	if err != nil {
+		return nil, e.Wrap(LoadReferenceError, err, fmt.Sprintf("%s.Load", currentPackage))
+	}
+	for rows.Next() {
+		c := City{Districts: []District{}}
+		if err := rows.Scan(&c.Code, &c.NameEn, &c.NameZh, &c.MergedInto); err != nil {
+			rows.Close()
+			return nil, e.Wrap(LoadReferenceError, err, fmt.Sprintf("%s.Load", currentPackage))
+		}
+		byCode[c.Code] = len(cities)
+		cities = append(cities, c)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, e.Wrap(LoadReferenceError, err, fmt.Sprintf("%s.Load", currentPackage))
+	}
+
+	rows, err = db.Query(ctx, `
+	SELECT city_code, district_name_zh, district_name_en FROM ref_plvr_land_district ORDER BY city_code, district_name_zh
+	`)
+	if err != nil {
+		return nil, e.Wrap(LoadReferenceError, err, fmt.Sprintf("%s.Load", currentPackage))
+	}
+	for rows.Next() {
+		var code string
+		var d District
+		if err := rows.Scan(&code, &d.NameZh, &d.NameEn); err != nil {
+			rows.Close()
+			return nil, e.Wrap(LoadReferenceError, err, fmt.Sprintf("%s.Load", currentPackage))
+		}
+		if i, ok := byCode[code]; ok {
+			cities[i].Districts = append(cities[i].Districts, d)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, e.Wrap(LoadReferenceError, err, fmt.Sprintf("%s.Load", currentPackage))
+	}
+
+	rows, err = db.Query(ctx, `
+	SELECT city_code, district_name_zh, current_city_code, current_district_name_zh, changed_date
+	FROM ref_plvr_land_district_history
+	`)
+	if err != nil {
+		return nil, e.Wrap(LoadReferenceError, err, fmt.Sprintf("%s.Load", currentPackage))
+	}
+	defer rows.Close()
+	former := []FormerDistrict{}
+	for rows.Next() {
+		var f FormerDistrict
+		if err := rows.Scan(&f.City, &f.NameZh, &f.CurrentCity, &f.CurrentDistrict, &f.Changed); err != nil {
+			return nil, e.Wrap(LoadReferenceError, err, fmt.Sprintf("%s.Load", currentPackage))
+		}
+		former = append(former, f)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, e.Wrap(LoadReferenceError, err, fmt.Sprintf("%s.Load", currentPackage))
+	}
+	return New(cities, former), nil
+}
+
+// Cities returns every city ordered by code
+func (r *Reference) Cities() []City {
+	return r.cities
+}
+
+// City returns the city of code
+func (r *Reference) City(code string) (City, *e.ErrorData) {
+	i, ok := r.byCode[code]
+	if !ok {
+		codes := make([]string, 0, len(r.byCode))
+		for c := range r.byCode {
+			codes = append(codes, c)
+		}
+		sort.Strings(codes)
+		return City{}, e.NewErrorData(
+			CityNotFoundError,
+			fmt.Sprintf("unknown city code %q, expected one of %s", code, strings.Join(codes, ",")),
+			fmt.Sprintf("%s.City", currentPackage),
+			nil,
+			nil,
+		)
+	}
+	return r.cities[i], nil
+}
+
+// CityName returns the english name of code, or code itself when unknown
+func (r *Reference) CityName(code string) string {
+	if i, ok := r.byCode[code]; ok {
+		return r.cities[i].NameEn
+	}
+	return code
+}
+
+// District returns the district of a city by its chinese name, the plvr files spell 臺 as
+// 台 at times, false is returned when the city has no such district
+func (r *Reference) District(cityCode string, name string) (District, bool) {
+	d, ok := r.districts[cityCode][NormalizeName(name)]
+	return d, ok
+}
+
+// KnownDistrict tells whether name is a district of the city on date, i.e., a current district
+// or a former name before its change, e.g., 中壢市 of h before 2014-12-25, a nil date accepts
+// the former names at any date, the cities without districts, e.g., the counties merged in
+// 2010, accept any, their districts are resolved by the _current views
+func (r *Reference) KnownDistrict(cityCode string, name string, date *time.Time) bool {
+	if len(r.districts[cityCode]) == 0 {
+		return true
+	}
+	if _, ok := r.District(cityCode, name); ok {
+		return true
+	}
+	f, ok := r.former[cityCode][NormalizeName(name)]
+	return ok && (date == nil || date.Format(time.DateOnly) < f.Changed.Format(time.DateOnly))
+}
+
+// NormalizeName trims name and replaces 台 by 臺, the spelling of the reference tables
+func NormalizeName(name string) string {
+	return strings.ReplaceAll(strings.TrimSpace(name), "台", "臺")
+}
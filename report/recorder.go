@@ -0,0 +1,171 @@
+package report
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	StatusRunning     = "running"
+	StatusFinished    = "finished"
+	StatusInterrupted = "interrupted"
+
+	// maxSamples is the number of rows and messages kept per error code
+	maxSamples = 5
+)
+
+// Rows counts the rows of a file, the updated ones are already imported with other values, e.g.,
+// corrected since, and the skipped ones already imported with the same values
+type Rows struct {
+	Read     int `json:"read"`
+	Inserted int `json:"inserted"`
+	Updated  int `json:"updated"`
+	Skipped  int `json:"skipped"`
+	Rejected int `json:"rejected"`
+}
+
+func (r *Rows) add(o Rows) {
+	r.Read += o.Read
+	r.Inserted += o.Inserted
+	r.Updated += o.Updated
+	r.Skipped += o.Skipped
+	r.Rejected += o.Rejected
+}
+
+type FileReport struct {
+	Season string `json:"season"`
+	File   string `json:"file"`
+	City   string `json:"city"`
+	Rows   Rows   `json:"rows"`
+}
+
+// ErrorReport counts the errors of a code per season and file, the file is empty for the
+// errors of the whole season, e.g., the download
+type ErrorReport struct {
+	Season  string        `json:"season"`
+	File    string        `json:"file,omitempty"`
+	Code    string        `json:"code"`
+	Count   int           `json:"count"`
+	Samples []ErrorSample `json:"samples"`
+}
+
+type ErrorSample struct {
+	Row     int    `json:"row,omitempty"`
+	Message string `json:"message"`
+}
+
+// Run is the report of a crawl run, the files and the errors are only listed for a single run
+type Run struct {
+	RunID        string        `json:"run_id"`
+	Status       string        `json:"status"`
+	StartedTime  time.Time     `json:"started_time"`
+	FinishedTime *time.Time    `json:"finished_time,omitempty"`
+	Rows         Rows          `json:"rows"`
+	ErrorCount   int           `json:"error_count"`
+	Files        []FileReport  `json:"files,omitempty"`
+	Errors       []ErrorReport `json:"errors,omitempty"`
+}
+
+type fileKey struct {
+	season, file string
+}
+
+type errorKey struct {
+	season, file, code string
+}
+
+// Recorder collects the report of a run while the seasons are crawled concurrently
+type Recorder struct {
+	mu      sync.Mutex
+	runID   string
+	started time.Time
+	files   map[fileKey]*FileReport
+	errors  map[errorKey]*ErrorReport
+}
+
+func NewRecorder(runID string) *Recorder {
+	return &Recorder{
+		runID:   runID,
+		started: time.Now(),
+		files:   map[fileKey]*FileReport{},
+		errors:  map[errorKey]*ErrorReport{},
+	}
+}
+
+func (r *Recorder) RunID() string {
+	return r.runID
+}
+
+func (r *Recorder) Started() time.Time {
+	return r.started
+}
+
+// File adds the rows of a file of a season
+func (r *Recorder) File(season, file, city string, rows Rows) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := fileKey{season, file}
+	if _, ok := r.files[k]; !ok {
+		r.files[k] = &FileReport{Season: season, File: file, City: city}
+	}
+	r.files[k].Rows.add(rows)
+}
+
+// Error counts errData under its code, row is 0 when the error is not of a row
+func (r *Recorder) Error(season, file string, row int, errData *e.ErrorData) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	k := errorKey{season, file, errData.Code}
+	report, ok := r.errors[k]
+	if !ok {
+		report = &ErrorReport{Season: season, File: file, Code: errData.Code, Samples: []ErrorSample{}}
+		r.errors[k] = report
+	}
+	report.Count++
+	if len(report.Samples) < maxSamples {
+		report.Samples = append(report.Samples, ErrorSample{Row: row, Message: errData.Message})
+	}
+}
+
+// Run returns the report collected so far as finished with status now
+func (r *Recorder) Run(status string) *Run {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	finished := time.Now()
+	run := &Run{
+		RunID:        r.runID,
+		Status:       status,
+		StartedTime:  r.started,
+		FinishedTime: &finished,
+		Files:        make([]FileReport, 0, len(r.files)),
+		Errors:       make([]ErrorReport, 0, len(r.errors)),
+	}
+	for _, f := range r.files {
+		run.Rows.add(f.Rows)
+		run.Files = append(run.Files, *f)
+	}
+	for _, err := range r.errors {
+		run.ErrorCount += err.Count
+		report := *err
+		report.Samples = append([]ErrorSample{}, err.Samples...)
+		run.Errors = append(run.Errors, report)
+	}
+	sort.Slice(run.Files, func(i, j int) bool {
+		a, b := run.Files[i], run.Files[j]
+		return a.Season < b.Season || a.Season == b.Season && a.File < b.File
+	})
+	sort.Slice(run.Errors, func(i, j int) bool {
+		a, b := run.Errors[i], run.Errors[j]
+		if a.Season != b.Season {
+			return a.Season < b.Season
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		return a.Code < b.Code
+	})
+	return run
+}
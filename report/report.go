@@ -0,0 +1,9 @@
+package report
+
+const (
+	SaveReportError  = "RP00001"
+	QueryReportError = "RP00002"
+	RunNotFoundError = "RP00003"
+
+	DefaultRunsLimit = 20
+)
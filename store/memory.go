@@ -0,0 +1,183 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/report"
+)
+
+// Memory keeps everything in memory, it stands in for a database in the tests of the crawler
+type Memory struct {
+	mu           sync.Mutex
+	transactions map[string][]Transaction
+	keys         map[string]int // index of the transaction of a dedup key in its table
+	years        map[string]map[int]bool
+	imported     map[string]bool
+	runs         map[string]*report.Run
+}
+
+func NewMemory() *Memory {
+	return &Memory{
+		transactions: map[string][]Transaction{},
+		keys:         map[string]int{},
+		years:        map[string]map[int]bool{},
+		imported:     map[string]bool{},
+		runs:         map[string]*report.Run{},
+	}
+}
+
+func (m *Memory) Prepare(ctx context.Context, table string, years []int) *e.ErrorData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.years[table] == nil {
+		m.years[table] = map[int]bool{}
+	}
+	for _, year := range years {
+		m.years[table][year] = true
+	}
+	return nil
+}
+
+// Save replaces the transaction with the same values of the dedup columns when the other values
+// differ, a null value equals another one, as in the unique indexes, e.g., an unparsable date
+func (m *Memory) Save(ctx context.Context, t Transaction) (SaveResult, *e.ErrorData) {
+	if len(t.Columns) != len(t.Values) {
+		return Unchanged, e.NewErrorData(
+			InsertTransactionError,
+			fmt.Sprintf("%d columns but %d values", len(t.Columns), len(t.Values)),
+			fmt.Sprintf("%s.Memory.Save", currentPackage),
+			nil,
+			nil,
+		)
+	}
+	key := t.Table
+	for _, col := range dedupColumns {
+		for i := range t.Columns {
+			if t.Columns[i] == col {
+				key += fmt.Sprintf("|%v", deref(t.Values[i]))
+			}
+		}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if i, ok := m.keys[key]; ok {
+		stored := m.transactions[t.Table][i]
+		if sameValues(stored.Values, t.Values) {
+			return Unchanged, nil
+		}
+		m.transactions[t.Table][i] = t
+		return Updated, nil
+	}
+	m.keys[key] = len(m.transactions[t.Table])
+	m.transactions[t.Table] = append(m.transactions[t.Table], t)
+	return Inserted, nil
+}
+
+func sameValues(a []any, b []any) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if !reflect.DeepEqual(deref(a[i]), deref(b[i])) {
+			return false
+		}
+	}
+	return true
+}
+
+// Transactions returns the transactions inserted into table
+func (m *Memory) Transactions(table string) []Transaction {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]Transaction{}, m.transactions[table]...)
+}
+
+// Years returns the years table was prepared for
+func (m *Memory) Years(table string) []int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	years := []int{}
+	for year := range m.years[table] {
+		years = append(years, year)
+	}
+	sort.Ints(years)
+	return years
+}
+
+func (m *Memory) Imported(ctx context.Context, remoteAddr string) (bool, *e.ErrorData) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.imported[remoteAddr], nil
+}
+
+func (m *Memory) MarkImported(ctx context.Context, remoteAddr string) *e.ErrorData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.imported[remoteAddr] = true
+	return nil
+}
+
+func (m *Memory) StartRun(ctx context.Context, rec *report.Recorder) *e.ErrorData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.runs[rec.RunID()] = &report.Run{RunID: rec.RunID(), Status: report.StatusRunning, StartedTime: rec.Started()}
+	return nil
+}
+
+func (m *Memory) SaveRun(ctx context.Context, run *report.Run) *e.ErrorData {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	saved := *run
+	if started, ok := m.runs[run.RunID]; ok {
+		saved.StartedTime = started.StartedTime
+	}
+	m.runs[run.RunID] = &saved
+	return nil
+}
+
+func (m *Memory) Runs(ctx context.Context, limit int) ([]report.Run, *e.ErrorData) {
+	if limit <= 0 {
+		limit = report.DefaultRunsLimit
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	runs := make([]report.Run, 0, len(m.runs))
+	for _, run := range m.runs {
+		r := *run
+		r.Files, r.Errors = nil, nil
+		runs = append(runs, r)
+	}
+	sort.Slice(runs, func(i, j int) bool { return runs[i].StartedTime.After(runs[j].StartedTime) })
+	if len(runs) > limit {
+		runs = runs[:limit]
+	}
+	return runs, nil
+}
+
+func (m *Memory) Run(ctx context.Context, runID string) (*report.Run, *e.ErrorData) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	run, ok := m.runs[runID]
+	if !ok {
+		return nil, runNotFound(runID, "Memory.Run")
+	}
+	r := *run
+	return &r, nil
+}
+
+// deref compares the nullable values, e.g., the dates, by the value they point to, nil is
+// returned for the null ones
+func deref(v any) any {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return nil
+		}
+		return rv.Elem().Interface()
+	}
+	return v
+}
@@ -0,0 +1,343 @@
+package store
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"go.uber.org/zap"
+
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/report"
+)
+
+const (
+	runsQuery = `
+	SELECT r.run_id, r.status, r.started_time, r.finished_time,
+		COALESCE(f.rows_read, 0), COALESCE(f.rows_inserted, 0), COALESCE(f.rows_updated, 0), COALESCE(f.rows_skipped, 0), COALESCE(f.rows_rejected, 0),
+		COALESCE(er.count, 0)
+	FROM crawl_run r
+	LEFT JOIN (
+		SELECT run_id, SUM(rows_read) AS rows_read, SUM(rows_inserted) AS rows_inserted, SUM(rows_updated) AS rows_updated,
+			SUM(rows_skipped) AS rows_skipped, SUM(rows_rejected) AS rows_rejected
+		FROM crawl_run_file GROUP BY run_id
+	) f USING (run_id)
+	LEFT JOIN (
+		SELECT run_id, SUM(count) AS count FROM crawl_run_error GROUP BY run_id
+	) er USING (run_id)
+	`
+)
+
+// Postgres stores the transactions in the yearly partitions of the plvr tables, the created
+// partitions are remembered for the life of the store
+type Postgres struct {
+	pool   *pgxpool.Pool
+	logger *zap.SugaredLogger
+
+	mu         sync.Mutex
+	partitions map[string]bool
+}
+
+func NewPostgres(pool *pgxpool.Pool, logger *zap.SugaredLogger) *Postgres {
+	return &Postgres{
+		pool:       pool,
+		logger:     logger,
+		partitions: map[string]bool{},
+	}
+}
+
+func (s *Postgres) Dialect() Dialect {
+	return DialectPostgres
+}
+
+func (s *Postgres) Query(ctx context.Context, query string, args ...any) (Rows, error) {
+	return s.pool.Query(ctx, query, args...)
+}
+
+// Prepare creates the partitions of the years, the rows without a valid date land in the
+// default partition
+func (s *Postgres) Prepare(ctx context.Context, table string, years []int) *e.ErrorData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, year := range years {
+		key := fmt.Sprintf("%s_y%d", table, year)
+		if s.partitions[key] {
+			continue
+		}
+		if _, err := s.pool.Exec(ctx, "SELECT plvr_create_year_partition($1, $2)", table, year); err != nil {
+			return e.Wrap(
+				PrepareTableError,
+				err,
+				fmt.Sprintf("%s.Postgres.Prepare", currentPackage),
+			)
+		}
+		s.partitions[key] = true
+	}
+	return nil
+}
+
+func (s *Postgres) Save(ctx context.Context, t Transaction) (SaveResult, *e.ErrorData) {
+	result, err := saveTransaction(t, func(query string) (int64, error) {
+		tag, err := s.pool.Exec(ctx, query, t.Values...)
+		return tag.RowsAffected(), err
+	})
+	if err != nil {
+		return Unchanged, e.Wrap(
+			InsertTransactionError,
+			err,
+			fmt.Sprintf("%s.Postgres.Save", currentPackage),
+		)
+	}
+	return result, nil
+}
+
+func (s *Postgres) Imported(ctx context.Context, remoteAddr string) (bool, *e.ErrorData) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM plvr_download_history WHERE remote_addr = $1)`
+	if err := s.pool.QueryRow(ctx, query, remoteAddr).Scan(&exists); err != nil {
+		return false, e.Wrap(
+			QueryHistoryError,
+			err,
+			fmt.Sprintf("%s.Postgres.Imported", currentPackage),
+		)
+	}
+	return exists, nil
+}
+
+func (s *Postgres) MarkImported(ctx context.Context, remoteAddr string) *e.ErrorData {
+	query := `
+	INSERT INTO plvr_download_history (remote_addr, downloaded_time) VALUES ($1, now())
+	ON CONFLICT (remote_addr) DO UPDATE SET downloaded_time = EXCLUDED.downloaded_time
+	`
+	if _, err := s.pool.Exec(ctx, query, remoteAddr); err != nil {
+		return e.Wrap(
+			SaveHistoryError,
+			err,
+			fmt.Sprintf("%s.Postgres.MarkImported", currentPackage),
+		)
+	}
+	return nil
+}
+
+func (s *Postgres) StartRun(ctx context.Context, rec *report.Recorder) *e.ErrorData {
+	query := `INSERT INTO crawl_run (run_id, status, started_time) VALUES ($1, $2, $3)`
+	if _, err := s.pool.Exec(ctx, query, rec.RunID(), report.StatusRunning, rec.Started()); err != nil {
+		return e.Wrap(
+			report.SaveReportError,
+			err,
+			fmt.Sprintf("%s.Postgres.StartRun", currentPackage),
+		)
+	}
+	return nil
+}
+
+func (s *Postgres) SaveRun(ctx context.Context, run *report.Run) *e.ErrorData {
+	err := pgx.BeginFunc(ctx, s.pool, func(tx pgx.Tx) error {
+		if _, err := tx.Exec(ctx, `
+		INSERT INTO crawl_run (run_id, status, started_time, finished_time) VALUES ($1, $2, $3, $4)
+		ON CONFLICT (run_id) DO UPDATE SET status = EXCLUDED.status, finished_time = EXCLUDED.finished_time
+		`, run.RunID, run.Status, run.StartedTime, run.FinishedTime); err != nil {
+			return err
+		}
+		batch := &pgx.Batch{}
+		for _, f := range run.Files {
+			batch.Queue(`
+			INSERT INTO crawl_run_file (run_id, season, file_name, city, rows_read, rows_inserted, rows_updated, rows_skipped, rows_rejected)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			ON CONFLICT (run_id, season, file_name) DO UPDATE SET
+				rows_read = EXCLUDED.rows_read, rows_inserted = EXCLUDED.rows_inserted, rows_updated = EXCLUDED.rows_updated,
+				rows_skipped = EXCLUDED.rows_skipped, rows_rejected = EXCLUDED.rows_rejected
+			`, run.RunID, f.Season, f.File, f.City, f.Rows.Read, f.Rows.Inserted, f.Rows.Updated, f.Rows.Skipped, f.Rows.Rejected)
+		}
+		for _, er := range run.Errors {
+			samples, err := json.Marshal(er.Samples)
+			if err != nil {
+				return err
+			}
+			batch.Queue(`
+			INSERT INTO crawl_run_error (run_id, season, file_name, code, count, samples)
+			VALUES ($1, $2, $3, $4, $5, $6::jsonb)
+			ON CONFLICT (run_id, season, file_name, code) DO UPDATE SET
+				count = EXCLUDED.count, samples = EXCLUDED.samples
+			`, run.RunID, er.Season, er.File, er.Code, er.Count, string(samples))
+		}
+		return tx.SendBatch(ctx, batch).Close()
+	})
+	if err != nil {
+		return e.Wrap(
+			report.SaveReportError,
+			err,
+			fmt.Sprintf("%s.Postgres.SaveRun", currentPackage),
+		)
+	}
+	s.logger.Debugf("[store] run %s saved", run.RunID)
+	return nil
+}
+
+func (s *Postgres) Runs(ctx context.Context, limit int) ([]report.Run, *e.ErrorData) {
+	if limit <= 0 {
+		limit = report.DefaultRunsLimit
+	}
+	rows, err := s.pool.Query(ctx, runsQuery+"ORDER BY r.started_time DESC LIMIT $1", limit)
+	if err != nil {
+		return nil, e.Wrap(
+			report.QueryReportError,
+			err,
+			fmt.Sprintf("%s.Postgres.Runs", currentPackage),
+		)
+	}
+	runs, err := pgx.CollectRows(rows, scanRun)
+	if err != nil {
+		return nil, e.Wrap(
+			report.QueryReportError,
+			err,
+			fmt.Sprintf("%s.Postgres.Runs", currentPackage),
+		)
+	}
+	return runs, nil
+}
+
+func (s *Postgres) Run(ctx context.Context, runID string) (*report.Run, *e.ErrorData) {
+	rows, err := s.pool.Query(ctx, runsQuery+"WHERE r.run_id = $1", runID)
+	if err != nil {
+		return nil, e.Wrap(
+			report.QueryReportError,
+			err,
+			fmt.Sprintf("%s.Postgres.Run", currentPackage),
+		)
+	}
+	run, err := pgx.CollectOneRow(rows, scanRun)
+	if err == pgx.ErrNoRows {
+		return nil, runNotFound(runID, "Postgres.Run")
+	}
+	if err != nil {
+		return nil, e.Wrap(
+			report.QueryReportError,
+			err,
+			fmt.Sprintf("%s.Postgres.Run", currentPackage),
+		)
+	}
+
+	rows, err = s.pool.Query(ctx, `
+	SELECT season, file_name, city, rows_read, rows_inserted, rows_updated, rows_skipped, rows_rejected
+	FROM crawl_run_file WHERE run_id = $1 ORDER BY season, file_name
+	`, runID)
+	if err == nil {
+		run.Files, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (report.FileReport, error) {
+			var f report.FileReport
+			err := row.Scan(&f.Season, &f.File, &f.City, &f.Rows.Read, &f.Rows.Inserted, &f.Rows.Updated, &f.Rows.Skipped, &f.Rows.Rejected)
+			return f, err
+		})
+	}
+	if err != nil {
+		return nil, e.Wrap(
+			report.QueryReportError,
+			err,
+			fmt.Sprintf("%s.Postgres.Run", currentPackage),
+		)
+	}
+
+	rows, err = s.pool.Query(ctx, `
+	SELECT season, file_name, code, count, samples
+	FROM crawl_run_error WHERE run_id = $1 ORDER BY season, file_name, code
+	`, runID)
+	if err == nil {
+		run.Errors, err = pgx.CollectRows(rows, func(row pgx.CollectableRow) (report.ErrorReport, error) {
+			var er report.ErrorReport
+			err := row.Scan(&er.Season, &er.File, &er.Code, &er.Count, &er.Samples)
+			return er, err
+		})
+	}
+	if err != nil {
+		return nil, e.Wrap(
+			report.QueryReportError,
+			err,
+			fmt.Sprintf("%s.Postgres.Run", currentPackage),
+		)
+	}
+	return &run, nil
+}
+
+// saveTransaction inserts t, or updates the stored transaction when the insert conflicts with
+// the dedup index, exec runs a query with the values of t and returns the affected rows
+func saveTransaction(t Transaction, exec func(query string) (int64, error)) (SaveResult, error) {
+	n, err := exec(insertQuery(t))
+	if err != nil {
+		return Unchanged, err
+	}
+	if n == 1 {
+		return Inserted, nil
+	}
+	if n, err = exec(updateQuery(t)); err != nil {
+		return Unchanged, err
+	}
+	if n == 1 {
+		return Updated, nil
+	}
+	return Unchanged, nil
+}
+
+// insertQuery skips the transactions conflicting with the dedup index, on both dialects
+func insertQuery(t Transaction) string {
+	params := make([]string, len(t.Columns))
+	for i := range params {
+		params[i] = fmt.Sprintf("$%d", i+1)
+	}
+	return fmt.Sprintf(
+		"INSERT INTO %s (%s) VALUES (%s) ON CONFLICT DO NOTHING",
+		pgx.Identifier{t.Table}.Sanitize(), strings.Join(t.Columns, ", "), strings.Join(params, ", "),
+	)
+}
+
+// updateQuery replaces the values of the transaction stored with the dedup columns of t when
+// any of them differs, a null dedup column matches a null one as in the unique indexes, e.g.,
+// an undated transaction, it takes the parameters of insertQuery, on both dialects
+func updateQuery(t Transaction) string {
+	sets, changed, keys := []string{}, []string{}, []string{}
+	for i, col := range t.Columns {
+		param := fmt.Sprintf("$%d", i+1)
+		if isDedupColumn(col) {
+			keys = append(keys, fmt.Sprintf("%s IS NOT DISTINCT FROM %s", col, param))
+			continue
+		}
+		sets = append(sets, fmt.Sprintf("%s = %s", col, param))
+		changed = append(changed, fmt.Sprintf("%s IS DISTINCT FROM %s", col, param))
+	}
+	return fmt.Sprintf(
+		"UPDATE %s SET %s WHERE %s AND (%s)",
+		pgx.Identifier{t.Table}.Sanitize(), strings.Join(sets, ", "), strings.Join(keys, " AND "), strings.Join(changed, " OR "),
+	)
+}
+
+func isDedupColumn(col string) bool {
+	for _, c := range dedupColumns {
+		if c == col {
+			return true
+		}
+	}
+	return false
+}
+
+func scanRun(row pgx.CollectableRow) (report.Run, error) {
+	var run report.Run
+	err := row.Scan(
+		&run.RunID, &run.Status, &run.StartedTime, &run.FinishedTime,
+		&run.Rows.Read, &run.Rows.Inserted, &run.Rows.Updated, &run.Rows.Skipped, &run.Rows.Rejected,
+		&run.ErrorCount,
+	)
+	return run, err
+}
+
+func runNotFound(runID string, target string) *e.ErrorData {
+	return e.NewErrorData(
+		report.RunNotFoundError,
+		fmt.Sprintf("run %s not found", runID),
+		fmt.Sprintf("%s.%s", currentPackage, target),
+		nil,
+		nil,
+	)
+}
@@ -0,0 +1,119 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	e "github.com/Walker088/gorealestate/error"
+)
+
+const (
+	DialectPostgres Dialect = "postgres"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// Dialect is the sql flavour of a Querier, the queries shared by the backends render the
+// functions the flavours disagree on through it, the rest is standard sql, e.g., CAST
+type Dialect string
+
+// Rows is the result of a query, pgx.Rows implements it
+type Rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close()
+}
+
+// Querier runs the read only queries of the analyses and the exports, the arguments are
+// positional starting from $1 on both dialects
+type Querier interface {
+	Dialect() Dialect
+	Query(ctx context.Context, query string, args ...any) (Rows, error)
+}
+
+// Database is a backend of both the crawler and the queries
+type Database interface {
+	Store
+	Querier
+}
+
+// Date renders expr as YYYY-MM-DD text
+func (d Dialect) Date(expr string) string {
+	if d == DialectSQLite {
+		return fmt.Sprintf("date(%s)", expr)
+	}
+	return fmt.Sprintf("to_char(%s, 'YYYY-MM-DD')", expr)
+}
+
+func (d Dialect) Year(expr string) string {
+	if d == DialectSQLite {
+		return fmt.Sprintf("CAST(strftime('%%Y', %s) AS INTEGER)", expr)
+	}
+	return fmt.Sprintf("CAST(EXTRACT(YEAR FROM %s) AS INTEGER)", expr)
+}
+
+func (d Dialect) Quarter(expr string) string {
+	if d == DialectSQLite {
+		return fmt.Sprintf("((CAST(strftime('%%m', %s) AS INTEGER) + 2) / 3)", expr)
+	}
+	return fmt.Sprintf("CAST(EXTRACT(QUARTER FROM %s) AS INTEGER)", expr)
+}
+
+// Median is the aggregate of the continuous median of expr, sqlite has the median function
+// of the database package
+func (d Dialect) Median(expr string) string {
+	if d == DialectSQLite {
+		return fmt.Sprintf("median(%s)", expr)
+	}
+	return fmt.Sprintf("percentile_cont(0.5) WITHIN GROUP (ORDER BY %s)", expr)
+}
+
+// Contains tells whether substr is found in s
+func (d Dialect) Contains(s string, substr string) string {
+	if d == DialectSQLite {
+		return fmt.Sprintf("instr(%s, %s) > 0", s, substr)
+	}
+	return fmt.Sprintf("strpos(%s, %s) > 0", s, substr)
+}
+
+// Matches tells whether s matches the regular expression pattern, sqlite has the regexp
+// function of the database package
+func (d Dialect) Matches(s string, pattern string) string {
+	if d == DialectSQLite {
+		return fmt.Sprintf("%s REGEXP %s", s, pattern)
+	}
+	return fmt.Sprintf("%s ~ %s", s, pattern)
+}
+
+// PgPool returns the connection pool of q for the features relying on postgres, e.g., the
+// flags or postgis, an UnsupportedError is returned for the other backends
+func PgPool(q Querier, feature string, target string) (*pgxpool.Pool, *e.ErrorData) {
+	if pg, ok := q.(*Postgres); ok {
+		return pg.pool, nil
+	}
+	return nil, e.NewErrorData(
+		UnsupportedError,
+		fmt.Sprintf("%s requires postgres, the %s database does not support it", feature, q.Dialect()),
+		target,
+		nil,
+		nil,
+	)
+}
+
+// SQLiteDB returns the database of q for the features writing to sqlite, e.g., the flags, an
+// UnsupportedError is returned for the other backends
+func SQLiteDB(q Querier, feature string, target string) (*sql.DB, *e.ErrorData) {
+	if s, ok := q.(*SQLite); ok {
+		return s.db, nil
+	}
+	return nil, e.NewErrorData(
+		UnsupportedError,
+		fmt.Sprintf("%s requires sqlite, the %s database does not support it", feature, q.Dialect()),
+		target,
+		nil,
+		nil,
+	)
+}
@@ -0,0 +1,383 @@
+package store
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/report"
+)
+
+const (
+	// sqliteTime is the layout of the timestamps, of a fixed width in utc, i.e., sorted as text
+	sqliteTime = "2006-01-02T15:04:05.000Z07:00"
+)
+
+// SQLite keeps the transactions in a local database file, the tables are not partitioned,
+// the dates are stored as YYYY-MM-DD text and the flags and the error samples as json text
+type SQLite struct {
+	db     *sql.DB
+	logger *zap.SugaredLogger
+}
+
+func NewSQLite(db *sql.DB, logger *zap.SugaredLogger) *SQLite {
+	return &SQLite{
+		db:     db,
+		logger: logger,
+	}
+}
+
+func (s *SQLite) Dialect() Dialect {
+	return DialectSQLite
+}
+
+func (s *SQLite) Query(ctx context.Context, query string, args ...any) (Rows, error) {
+	rows, err := s.db.QueryContext(ctx, query, sqliteArgs(args)...)
+	if err != nil {
+		return nil, err
+	}
+	return &sqliteRows{rows: rows}, nil
+}
+
+// Prepare has nothing to create, sqlite has no partitions
+func (s *SQLite) Prepare(ctx context.Context, table string, years []int) *e.ErrorData {
+	return nil
+}
+
+func (s *SQLite) Save(ctx context.Context, t Transaction) (SaveResult, *e.ErrorData) {
+	args := sqliteArgs(t.Values)
+	result, err := saveTransaction(t, func(query string) (int64, error) {
+		res, err := s.db.ExecContext(ctx, query, args...)
+		if err != nil {
+			return 0, err
+		}
+		return res.RowsAffected()
+	})
+	if err != nil {
+		return Unchanged, e.Wrap(
+			InsertTransactionError,
+			err,
+			fmt.Sprintf("%s.SQLite.Save", currentPackage),
+		)
+	}
+	return result, nil
+}
+
+func (s *SQLite) Imported(ctx context.Context, remoteAddr string) (bool, *e.ErrorData) {
+	var exists bool
+	query := `SELECT EXISTS(SELECT 1 FROM plvr_download_history WHERE remote_addr = $1)`
+	if err := s.db.QueryRowContext(ctx, query, remoteAddr).Scan(&exists); err != nil {
+		return false, e.Wrap(
+			QueryHistoryError,
+			err,
+			fmt.Sprintf("%s.SQLite.Imported", currentPackage),
+		)
+	}
+	return exists, nil
+}
+
+func (s *SQLite) MarkImported(ctx context.Context, remoteAddr string) *e.ErrorData {
+	query := `
+	INSERT INTO plvr_download_history (remote_addr, downloaded_time) VALUES ($1, $2)
+	ON CONFLICT (remote_addr) DO UPDATE SET downloaded_time = excluded.downloaded_time
+	`
+	if _, err := s.db.ExecContext(ctx, query, remoteAddr, time.Now().UTC().Format(sqliteTime)); err != nil {
+		return e.Wrap(
+			SaveHistoryError,
+			err,
+			fmt.Sprintf("%s.SQLite.MarkImported", currentPackage),
+		)
+	}
+	return nil
+}
+
+func (s *SQLite) StartRun(ctx context.Context, rec *report.Recorder) *e.ErrorData {
+	query := `INSERT INTO crawl_run (run_id, status, started_time) VALUES ($1, $2, $3)`
+	if _, err := s.db.ExecContext(ctx, query, rec.RunID(), report.StatusRunning, rec.Started().UTC().Format(sqliteTime)); err != nil {
+		return e.Wrap(
+			report.SaveReportError,
+			err,
+			fmt.Sprintf("%s.SQLite.StartRun", currentPackage),
+		)
+	}
+	return nil
+}
+
+func (s *SQLite) SaveRun(ctx context.Context, run *report.Run) *e.ErrorData {
+	err := s.saveRun(ctx, run)
+	if err != nil {
+		return e.Wrap(
+			report.SaveReportError,
+			err,
+			fmt.Sprintf("%s.SQLite.SaveRun", currentPackage),
+		)
+	}
+	s.logger.Debugf("[store] run %s saved", run.RunID)
+	return nil
+}
+
+func (s *SQLite) saveRun(ctx context.Context, run *report.Run) error {
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+	var finished any
+	if run.FinishedTime != nil {
+		finished = run.FinishedTime.UTC().Format(sqliteTime)
+	}
+	if _, err := tx.ExecContext(ctx, `
+	INSERT INTO crawl_run (run_id, status, started_time, finished_time) VALUES ($1, $2, $3, $4)
+	ON CONFLICT (run_id) DO UPDATE SET status = excluded.status, finished_time = excluded.finished_time
+	`, run.RunID, run.Status, run.StartedTime.UTC().Format(sqliteTime), finished); err != nil {
+		return err
+	}
+	for _, f := range run.Files {
+		if _, err := tx.ExecContext(ctx, `
+		INSERT INTO crawl_run_file (run_id, season, file_name, city, rows_read, rows_inserted, rows_updated, rows_skipped, rows_rejected)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (run_id, season, file_name) DO UPDATE SET
+			rows_read = excluded.rows_read, rows_inserted = excluded.rows_inserted, rows_updated = excluded.rows_updated,
+			rows_skipped = excluded.rows_skipped, rows_rejected = excluded.rows_rejected
+		`, run.RunID, f.Season, f.File, f.City, f.Rows.Read, f.Rows.Inserted, f.Rows.Updated, f.Rows.Skipped, f.Rows.Rejected); err != nil {
+			return err
+		}
+	}
+	for _, er := range run.Errors {
+		samples, err := json.Marshal(er.Samples)
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, `
+		INSERT INTO crawl_run_error (run_id, season, file_name, code, count, samples)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (run_id, season, file_name, code) DO UPDATE SET
+			count = excluded.count, samples = excluded.samples
+		`, run.RunID, er.Season, er.File, er.Code, er.Count, string(samples)); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (s *SQLite) Runs(ctx context.Context, limit int) ([]report.Run, *e.ErrorData) {
+	if limit <= 0 {
+		limit = report.DefaultRunsLimit
+	}
+	runs, err := s.runs(ctx, runsQuery+"ORDER BY r.started_time DESC LIMIT $1", limit)
+	if err != nil {
+		return nil, e.Wrap(
+			report.QueryReportError,
+			err,
+			fmt.Sprintf("%s.SQLite.Runs", currentPackage),
+		)
+	}
+	return runs, nil
+}
+
+func (s *SQLite) Run(ctx context.Context, runID string) (*report.Run, *e.ErrorData) {
+	runs, err := s.runs(ctx, runsQuery+"WHERE r.run_id = $1", runID)
+	if err != nil {
+		return nil, e.Wrap(
+			report.QueryReportError,
+			err,
+			fmt.Sprintf("%s.SQLite.Run", currentPackage),
+		)
+	}
+	if len(runs) == 0 {
+		return nil, runNotFound(runID, "SQLite.Run")
+	}
+	run := runs[0]
+	if err := s.runDetails(ctx, &run); err != nil {
+		return nil, e.Wrap(
+			report.QueryReportError,
+			err,
+			fmt.Sprintf("%s.SQLite.Run", currentPackage),
+		)
+	}
+	return &run, nil
+}
+
+func (s *SQLite) runs(ctx context.Context, query string, args ...any) ([]report.Run, error) {
+	rows, err := s.Query(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	runs := []report.Run{}
+	for rows.Next() {
+		var run report.Run
+		if err := rows.Scan(
+			&run.RunID, &run.Status, &run.StartedTime, &run.FinishedTime,
+			&run.Rows.Read, &run.Rows.Inserted, &run.Rows.Updated, &run.Rows.Skipped, &run.Rows.Rejected,
+			&run.ErrorCount,
+		); err != nil {
+			return nil, err
+		}
+		runs = append(runs, run)
+	}
+	return runs, rows.Err()
+}
+
+// runDetails reads the files and the errors of run, the rows are read one query at a time as
+// the database has a single connection
+func (s *SQLite) runDetails(ctx context.Context, run *report.Run) error {
+	rows, err := s.Query(ctx, `
+	SELECT season, file_name, city, rows_read, rows_inserted, rows_updated, rows_skipped, rows_rejected
+	FROM crawl_run_file WHERE run_id = $1 ORDER BY season, file_name
+	`, run.RunID)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var f report.FileReport
+		if err := rows.Scan(&f.Season, &f.File, &f.City, &f.Rows.Read, &f.Rows.Inserted, &f.Rows.Updated, &f.Rows.Skipped, &f.Rows.Rejected); err != nil {
+			rows.Close()
+			return err
+		}
+		run.Files = append(run.Files, f)
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = s.Query(ctx, `
+	SELECT season, file_name, code, count, samples
+	FROM crawl_run_error WHERE run_id = $1 ORDER BY season, file_name, code
+	`, run.RunID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var er report.ErrorReport
+		var samples string
+		if err := rows.Scan(&er.Season, &er.File, &er.Code, &er.Count, &samples); err != nil {
+			return err
+		}
+		if err := json.Unmarshal([]byte(samples), &er.Samples); err != nil {
+			return err
+		}
+		run.Errors = append(run.Errors, er)
+	}
+	return rows.Err()
+}
+
+// sqliteRows converts the text columns into the destinations of the pgx scans, i.e., the
+// dates and timestamps into time.Time and the json arrays into []string
+type sqliteRows struct {
+	rows *sql.Rows
+}
+
+func (r *sqliteRows) Next() bool {
+	return r.rows.Next()
+}
+
+func (r *sqliteRows) Err() error {
+	return r.rows.Err()
+}
+
+func (r *sqliteRows) Close() {
+	r.rows.Close()
+}
+
+func (r *sqliteRows) Scan(dest ...any) error {
+	raw := make([]any, len(dest))
+	for i, d := range dest {
+		switch d.(type) {
+		case *time.Time, **time.Time, *[]string:
+			raw[i] = new(any)
+		default:
+			raw[i] = d
+		}
+	}
+	if err := r.rows.Scan(raw...); err != nil {
+		return err
+	}
+	for i, d := range dest {
+		v, ok := raw[i].(*any)
+		if !ok {
+			continue
+		}
+		if err := convertColumn(*v, d); err != nil {
+			return fmt.Errorf("column %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func convertColumn(v any, dest any) error {
+	if b, ok := v.([]byte); ok {
+		v = string(b)
+	}
+	switch d := dest.(type) {
+	case *[]string:
+		*d = nil
+		if s, ok := v.(string); ok {
+			return json.Unmarshal([]byte(s), d)
+		}
+	case **time.Time:
+		*d = nil
+		if v != nil {
+			t, err := parseTime(v)
+			if err != nil {
+				return err
+			}
+			*d = &t
+		}
+	case *time.Time:
+		t, err := parseTime(v)
+		if err != nil {
+			return err
+		}
+		*d = t
+	}
+	return nil
+}
+
+func parseTime(v any) (time.Time, error) {
+	switch v := v.(type) {
+	case time.Time:
+		return v, nil
+	case string:
+		for _, layout := range []string{time.DateOnly, time.RFC3339Nano, time.DateTime} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t, nil
+			}
+		}
+	}
+	return time.Time{}, fmt.Errorf("unable to convert %v of %T into a time", v, v)
+}
+
+// sqliteArgs formats the times as the text stored by sqlite
+func sqliteArgs(args []any) []any {
+	converted := make([]any, len(args))
+	for i, arg := range args {
+		converted[i] = sqliteArg(arg)
+	}
+	return converted
+}
+
+// sqliteArg formats a time at midnight as a date, e.g., a transaction date, and any other as a
+// timestamp, nil times stay null
+func sqliteArg(arg any) any {
+	if p, ok := arg.(*time.Time); ok {
+		if p == nil {
+			return nil
+		}
+		arg = *p
+	}
+	t, ok := arg.(time.Time)
+	if !ok {
+		return arg
+	}
+	if t.Hour() == 0 && t.Minute() == 0 && t.Second() == 0 && t.Nanosecond() == 0 {
+		return t.Format(time.DateOnly)
+	}
+	return t.UTC().Format(sqliteTime)
+}
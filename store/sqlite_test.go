@@ -0,0 +1,167 @@
+package store
+
+import (
+	"context"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/Walker088/gorealestate/config"
+	"github.com/Walker088/gorealestate/database"
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/migrations"
+	"github.com/Walker088/gorealestate/report"
+)
+
+// newTestSQLite migrates a temporary database file
+func newTestSQLite(t *testing.T) *SQLite {
+	t.Helper()
+	cfg := &config.PgConfig{
+		Driver:     config.DriverSQLite,
+		SQLitePath: filepath.Join(t.TempDir(), "test.db"),
+		DbName:     "test",
+	}
+	logger := zap.NewNop().Sugar()
+	sm, errData := migrations.New(cfg, logger)
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	defer sm.Stop()
+	if errData := sm.Migrate(); errData != nil {
+		t.Fatal(errData)
+	}
+	db, errData := database.NewSQLite(cfg, logger)
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	t.Cleanup(func() { db.Close() })
+	return NewSQLite(db, logger)
+}
+
+func sale(serial string, date *time.Time, price int64) Transaction {
+	return Transaction{
+		Table:   HouseSaleTable,
+		Columns: []string{"serial_number", "city", "district", "transaction_date", "total_price"},
+		Values:  []any{serial, "h", "中壢區", date, price},
+	}
+}
+
+func TestSQLiteSave(t *testing.T) {
+	s := newTestSQLite(t)
+	ctx := context.Background()
+	date := time.Date(2015, 1, 5, 0, 0, 0, 0, time.UTC)
+
+	for i, c := range []struct {
+		t    Transaction
+		want SaveResult
+	}{
+		{sale("A1", &date, 5000000), Inserted},
+		{sale("A1", &date, 5000000), Unchanged},
+		{sale("A1", &date, 5100000), Updated},
+		{sale("A2", &date, 5000000), Inserted},
+		// an undated transaction is imported once as well
+		{sale("A3", nil, 5000000), Inserted},
+		{sale("A3", nil, 5000000), Unchanged},
+		{sale("A3", nil, 5200000), Updated},
+	} {
+		got, errData := s.Save(ctx, c.t)
+		if errData != nil {
+			t.Fatalf("save %d: %v", i, errData)
+		}
+		if got != c.want {
+			t.Errorf("save %d: expected the result %d, got %d", i, c.want, got)
+		}
+	}
+
+	rows, err := s.Query(ctx, `SELECT serial_number, transaction_date, total_price FROM plvr_land_house_sale ORDER BY serial_number, rowid`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rows.Close()
+	type row struct {
+		serial string
+		date   *time.Time
+		price  int64
+	}
+	got := []row{}
+	for rows.Next() {
+		var r row
+		if err := rows.Scan(&r.serial, &r.date, &r.price); err != nil {
+			t.Fatal(err)
+		}
+		got = append(got, r)
+	}
+	if err := rows.Err(); err != nil {
+		t.Fatal(err)
+	}
+	want := []row{{"A1", &date, 5100000}, {"A2", &date, 5000000}, {"A3", nil, 5200000}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected the rows %v, got %v", want, got)
+	}
+}
+
+func TestSQLiteHistory(t *testing.T) {
+	s := newTestSQLite(t)
+	ctx := context.Background()
+	addr := "https://plvr.land.moi.gov.tw/DownloadSeason?season=104S1&type=zip&fileName=lvr_landcsv.zip"
+
+	if imported, errData := s.Imported(ctx, addr); errData != nil || imported {
+		t.Fatalf("expected %s not to be imported, got %v %v", addr, imported, errData)
+	}
+	// marking twice refreshes the time of the download
+	for i := 0; i < 2; i++ {
+		if errData := s.MarkImported(ctx, addr); errData != nil {
+			t.Fatal(errData)
+		}
+	}
+	if imported, errData := s.Imported(ctx, addr); errData != nil || !imported {
+		t.Errorf("expected %s to be imported, got %v %v", addr, imported, errData)
+	}
+}
+
+func TestSQLiteRuns(t *testing.T) {
+	s := newTestSQLite(t)
+	ctx := context.Background()
+
+	rec := report.NewRecorder("run-1")
+	if errData := s.StartRun(ctx, rec); errData != nil {
+		t.Fatal(errData)
+	}
+	runs, errData := s.Runs(ctx, 0)
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	if len(runs) != 1 || runs[0].Status != report.StatusRunning || runs[0].FinishedTime != nil {
+		t.Fatalf("expected a running run, got %+v", runs)
+	}
+
+	rec.File("104S1", "h_lvr_land_a.csv", "h", report.Rows{Read: 8, Inserted: 6, Updated: 1, Skipped: 1})
+	rec.File("104S1", "h_lvr_land_c.csv", "h", report.Rows{Read: 2, Inserted: 1, Rejected: 1})
+	rec.Error("104S1", "h_lvr_land_c.csv", 3, e.NewErrorData(InsertTransactionError, "rejected", "test", nil, nil))
+	run := rec.Run(report.StatusFinished)
+	if errData := s.SaveRun(ctx, run); errData != nil {
+		t.Fatal(errData)
+	}
+
+	got, errData := s.Run(ctx, "run-1")
+	if errData != nil {
+		t.Fatal(errData)
+	}
+	if got.Status != report.StatusFinished || got.FinishedTime == nil || !got.FinishedTime.Equal(run.FinishedTime.Truncate(time.Millisecond)) {
+		t.Errorf("expected the run to be finished at %v, got %s %v", run.FinishedTime, got.Status, got.FinishedTime)
+	}
+	wantRows := report.Rows{Read: 10, Inserted: 7, Updated: 1, Skipped: 1, Rejected: 1}
+	if got.Rows != wantRows || got.ErrorCount != 1 {
+		t.Errorf("expected the rows %+v and an error, got %+v %d", wantRows, got.Rows, got.ErrorCount)
+	}
+	if !reflect.DeepEqual(got.Files, run.Files) || !reflect.DeepEqual(got.Errors, run.Errors) {
+		t.Errorf("expected the files %+v and the errors %+v, got %+v %+v", run.Files, run.Errors, got.Files, got.Errors)
+	}
+
+	if _, errData := s.Run(ctx, "run-2"); errData == nil || errData.Code != report.RunNotFoundError {
+		t.Errorf("expected a %s, got %v", report.RunNotFoundError, errData)
+	}
+}
@@ -0,0 +1,78 @@
+package store
+
+import (
+	"context"
+
+	e "github.com/Walker088/gorealestate/error"
+	"github.com/Walker088/gorealestate/report"
+)
+
+const (
+	currentPackage = "github.com/Walker088/gorealestate/store"
+
+	InsertTransactionError = "ST00001"
+	PrepareTableError      = "ST00002"
+	QueryHistoryError      = "ST00003"
+	UnsupportedError       = "ST00004"
+	SaveHistoryError       = "ST00005"
+
+	HouseSaleTable = "plvr_land_house_sale"
+	NewHouseTable  = "plvr_land_new_house"
+	RentalTable    = "plvr_land_rental"
+)
+
+// Transaction is a parsed row of a plvr file, the columns are named after the ones of the table
+type Transaction struct {
+	Table   string
+	Columns []string
+	Values  []any
+}
+
+// SaveResult tells whether a saved transaction is new, replaced a stored one or was already stored
+type SaveResult int
+
+const (
+	Unchanged SaveResult = iota
+	Inserted
+	Updated
+)
+
+// dedupColumns are the columns of the unique index of the transaction tables, a transaction is
+// identified by its serial number, the normalized address only matches the sales of a unit, the
+// undated transactions are keyed on the city and serial number by a partial index
+var dedupColumns = []string{"city", "serial_number", "transaction_date"}
+
+// TransactionStore saves the parsed transactions, a transaction stored with other values, e.g.,
+// corrected by a later publication, is updated
+type TransactionStore interface {
+	// Prepare readies table for the rows of the years, e.g., creates their partitions
+	Prepare(ctx context.Context, table string, years []int) *e.ErrorData
+	// Save inserts t, or updates the stored transaction of its dedup columns when the values differ
+	Save(ctx context.Context, t Transaction) (SaveResult, *e.ErrorData)
+}
+
+// HistoryStore tells which downloads are already imported
+type HistoryStore interface {
+	Imported(ctx context.Context, remoteAddr string) (bool, *e.ErrorData)
+	// MarkImported records remoteAddr as imported, i.e., the next runs skip it
+	MarkImported(ctx context.Context, remoteAddr string) *e.ErrorData
+}
+
+// RunStore persists the reports of the crawl runs and reads them back
+type RunStore interface {
+	// StartRun records a running run, a run never saved stays running, e.g., when the process is killed
+	StartRun(ctx context.Context, rec *report.Recorder) *e.ErrorData
+	// SaveRun stores the status, the files and the errors of run
+	SaveRun(ctx context.Context, run *report.Run) *e.ErrorData
+	// Runs lists the latest runs along with their totals, without their files and errors
+	Runs(ctx context.Context, limit int) ([]report.Run, *e.ErrorData)
+	// Run returns a run along with its files and errors
+	Run(ctx context.Context, runID string) (*report.Run, *e.ErrorData)
+}
+
+// Store is implemented by every backend, the databases implement Querier as well
+type Store interface {
+	TransactionStore
+	HistoryStore
+	RunStore
+}